@@ -0,0 +1,84 @@
+// Package butlerdtest helps testing butlerd handlers without
+// running a daemon: they're called directly, with a request context
+// backed by a throwaway database.
+package butlerdtest
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/cmd/operate/loopbackconn"
+	"github.com/itchio/butler/cmd/operate/memorylogger"
+	"github.com/itchio/butler/database"
+	itchio "github.com/itchio/go-itchio"
+)
+
+type Harness struct {
+	RC     *butlerd.RequestContext
+	Router *butlerd.Router
+	// Calls and notifications sent by handlers end up here
+	Conn loopbackconn.LoopbackConn
+
+	// API clients talk to this server. It's unreachable by default,
+	// as if we were offline.
+	Server string
+
+	// A temporary folder, removed by Close
+	Dir string
+
+	pool *sqlite.Pool
+}
+
+// New returns a harness with a fresh, migrated database.
+// Call Close once done.
+func New(t *testing.T) *Harness {
+	dir, err := ioutil.TempDir("", "butlerdtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool, err := sqlite.Open(filepath.Join(dir, "butler.db"), 0, 10)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	conn := pool.Get(context.Background().Done())
+	err = database.Prepare(conn)
+	pool.Put(conn)
+	if err != nil {
+		pool.Close()
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	h := &Harness{
+		Server: "http://127.0.0.1:1",
+		Dir:    dir,
+		pool:   pool,
+	}
+
+	getClient := func(key string) *itchio.Client {
+		client := itchio.ClientWithKey(key)
+		client.SetServer(h.Server)
+		client.RetryPatterns = nil
+		return client
+	}
+
+	consumer := memorylogger.New().Consumer()
+	h.Conn = loopbackconn.New(consumer)
+	h.Router = butlerd.NewRouter(pool, getClient, http.DefaultClient, nil)
+	h.RC = h.Router.NewRequestContext(context.Background(), h.Conn, consumer)
+	return h
+}
+
+func (h *Harness) Close() {
+	h.pool.Close()
+	os.RemoveAll(h.Dir)
+}
@@ -11,11 +11,14 @@ var codeMessages = map[Code]string{
 	CodeInstallFolderDisappeared: "Launch was unsuccessful because install folder disappeared",
 
 	CodeNoCompatibleUploads: "No compatible uploads were found.",
+	CodeNoPreviousVersion:   "There is no previous version to roll back to.",
 
 	CodeUnsupportedPackaging: "This title is packaged in a way that is not supported.",
 	CodeUnsupportedHost:      "This title is hosted on an incompatible third-party website",
 
-	CodeNoLaunchCandidates: "Nothing that can be launched was found.",
+	CodeNoLaunchCandidates:  "Nothing that can be launched was found.",
+	CodePreLaunchHookFailed: "A pre-launch hook failed.",
+	CodeAlreadyRunning:      "This game is already running.",
 
 	CodeNetworkDisconnected: "There is no Internet connection",
 
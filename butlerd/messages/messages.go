@@ -288,6 +288,246 @@ func (r *NetworkSetBandwidthThrottleType) TestCall(rc *butlerd.RequestContext, p
 
 var NetworkSetBandwidthThrottle *NetworkSetBandwidthThrottleType
 
+// Network.SetBandwidthPolicy (Request)
+
+type NetworkSetBandwidthPolicyType struct {}
+
+var _ RequestMessage = (*NetworkSetBandwidthPolicyType)(nil)
+
+func (r *NetworkSetBandwidthPolicyType) Method() string {
+  return "Network.SetBandwidthPolicy"
+}
+
+func (r *NetworkSetBandwidthPolicyType) Register(router router, f func(*butlerd.RequestContext, butlerd.NetworkSetBandwidthPolicyParams) (*butlerd.NetworkSetBandwidthPolicyResult, error)) {
+  router.Register("Network.SetBandwidthPolicy", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.NetworkSetBandwidthPolicyParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Network.SetBandwidthPolicy")
+    }
+    return res, nil
+  })
+}
+
+func (r *NetworkSetBandwidthPolicyType) TestCall(rc *butlerd.RequestContext, params butlerd.NetworkSetBandwidthPolicyParams) (*butlerd.NetworkSetBandwidthPolicyResult, error) {
+  var result butlerd.NetworkSetBandwidthPolicyResult
+  err := rc.Call("Network.SetBandwidthPolicy", params, &result)
+  return &result, err
+}
+
+var NetworkSetBandwidthPolicy *NetworkSetBandwidthPolicyType
+
+// Network.GetBandwidthPolicy (Request)
+
+type NetworkGetBandwidthPolicyType struct {}
+
+var _ RequestMessage = (*NetworkGetBandwidthPolicyType)(nil)
+
+func (r *NetworkGetBandwidthPolicyType) Method() string {
+  return "Network.GetBandwidthPolicy"
+}
+
+func (r *NetworkGetBandwidthPolicyType) Register(router router, f func(*butlerd.RequestContext, butlerd.NetworkGetBandwidthPolicyParams) (*butlerd.NetworkGetBandwidthPolicyResult, error)) {
+  router.Register("Network.GetBandwidthPolicy", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.NetworkGetBandwidthPolicyParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Network.GetBandwidthPolicy")
+    }
+    return res, nil
+  })
+}
+
+func (r *NetworkGetBandwidthPolicyType) TestCall(rc *butlerd.RequestContext, params butlerd.NetworkGetBandwidthPolicyParams) (*butlerd.NetworkGetBandwidthPolicyResult, error) {
+  var result butlerd.NetworkGetBandwidthPolicyResult
+  err := rc.Call("Network.GetBandwidthPolicy", params, &result)
+  return &result, err
+}
+
+var NetworkGetBandwidthPolicy *NetworkGetBandwidthPolicyType
+
+// Network.SetPeerCache (Request)
+
+type NetworkSetPeerCacheType struct {}
+
+var _ RequestMessage = (*NetworkSetPeerCacheType)(nil)
+
+func (r *NetworkSetPeerCacheType) Method() string {
+  return "Network.SetPeerCache"
+}
+
+func (r *NetworkSetPeerCacheType) Register(router router, f func(*butlerd.RequestContext, butlerd.NetworkSetPeerCacheParams) (*butlerd.NetworkSetPeerCacheResult, error)) {
+  router.Register("Network.SetPeerCache", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.NetworkSetPeerCacheParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Network.SetPeerCache")
+    }
+    return res, nil
+  })
+}
+
+func (r *NetworkSetPeerCacheType) TestCall(rc *butlerd.RequestContext, params butlerd.NetworkSetPeerCacheParams) (*butlerd.NetworkSetPeerCacheResult, error) {
+  var result butlerd.NetworkSetPeerCacheResult
+  err := rc.Call("Network.SetPeerCache", params, &result)
+  return &result, err
+}
+
+var NetworkSetPeerCache *NetworkSetPeerCacheType
+
+// Network.GetPeerCache (Request)
+
+type NetworkGetPeerCacheType struct {}
+
+var _ RequestMessage = (*NetworkGetPeerCacheType)(nil)
+
+func (r *NetworkGetPeerCacheType) Method() string {
+  return "Network.GetPeerCache"
+}
+
+func (r *NetworkGetPeerCacheType) Register(router router, f func(*butlerd.RequestContext, butlerd.NetworkGetPeerCacheParams) (*butlerd.NetworkGetPeerCacheResult, error)) {
+  router.Register("Network.GetPeerCache", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.NetworkGetPeerCacheParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Network.GetPeerCache")
+    }
+    return res, nil
+  })
+}
+
+func (r *NetworkGetPeerCacheType) TestCall(rc *butlerd.RequestContext, params butlerd.NetworkGetPeerCacheParams) (*butlerd.NetworkGetPeerCacheResult, error) {
+  var result butlerd.NetworkGetPeerCacheResult
+  err := rc.Call("Network.GetPeerCache", params, &result)
+  return &result, err
+}
+
+var NetworkGetPeerCache *NetworkGetPeerCacheType
+
+// Network.SetConnectivityProbe (Request)
+
+type NetworkSetConnectivityProbeType struct {}
+
+var _ RequestMessage = (*NetworkSetConnectivityProbeType)(nil)
+
+func (r *NetworkSetConnectivityProbeType) Method() string {
+  return "Network.SetConnectivityProbe"
+}
+
+func (r *NetworkSetConnectivityProbeType) Register(router router, f func(*butlerd.RequestContext, butlerd.NetworkSetConnectivityProbeParams) (*butlerd.NetworkSetConnectivityProbeResult, error)) {
+  router.Register("Network.SetConnectivityProbe", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.NetworkSetConnectivityProbeParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Network.SetConnectivityProbe")
+    }
+    return res, nil
+  })
+}
+
+func (r *NetworkSetConnectivityProbeType) TestCall(rc *butlerd.RequestContext, params butlerd.NetworkSetConnectivityProbeParams) (*butlerd.NetworkSetConnectivityProbeResult, error) {
+  var result butlerd.NetworkSetConnectivityProbeResult
+  err := rc.Call("Network.SetConnectivityProbe", params, &result)
+  return &result, err
+}
+
+var NetworkSetConnectivityProbe *NetworkSetConnectivityProbeType
+
+// Network.GetConnectivityProbe (Request)
+
+type NetworkGetConnectivityProbeType struct {}
+
+var _ RequestMessage = (*NetworkGetConnectivityProbeType)(nil)
+
+func (r *NetworkGetConnectivityProbeType) Method() string {
+  return "Network.GetConnectivityProbe"
+}
+
+func (r *NetworkGetConnectivityProbeType) Register(router router, f func(*butlerd.RequestContext, butlerd.NetworkGetConnectivityProbeParams) (*butlerd.NetworkGetConnectivityProbeResult, error)) {
+  router.Register("Network.GetConnectivityProbe", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.NetworkGetConnectivityProbeParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Network.GetConnectivityProbe")
+    }
+    return res, nil
+  })
+}
+
+func (r *NetworkGetConnectivityProbeType) TestCall(rc *butlerd.RequestContext, params butlerd.NetworkGetConnectivityProbeParams) (*butlerd.NetworkGetConnectivityProbeResult, error) {
+  var result butlerd.NetworkGetConnectivityProbeResult
+  err := rc.Call("Network.GetConnectivityProbe", params, &result)
+  return &result, err
+}
+
+var NetworkGetConnectivityProbe *NetworkGetConnectivityProbeType
+
 
 //==============================
 // Miscellaneous
@@ -405,6 +645,62 @@ func (r *DownloadsDriveFinishedType) Register(router router, f func(*butlerd.Req
 
 var DownloadsDriveFinished *DownloadsDriveFinishedType
 
+// Downloads.Drive.Paused (Notification)
+
+type DownloadsDrivePausedType struct {}
+
+var _ NotificationMessage = (*DownloadsDrivePausedType)(nil)
+
+func (r *DownloadsDrivePausedType) Method() string {
+  return "Downloads.Drive.Paused"
+}
+
+func (r *DownloadsDrivePausedType) Notify(rc *butlerd.RequestContext, params butlerd.DownloadsDrivePausedNotification) (error) {
+  return rc.Notify("Downloads.Drive.Paused", params)
+}
+
+func (r *DownloadsDrivePausedType) Register(router router, f func(*butlerd.RequestContext, butlerd.DownloadsDrivePausedNotification)) {
+  router.RegisterNotification("Downloads.Drive.Paused", func (rc *butlerd.RequestContext) {
+    var params butlerd.DownloadsDrivePausedNotification
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	// can't even propagate, just return
+    	return
+    }
+    f(rc, params)
+  })
+}
+
+var DownloadsDrivePaused *DownloadsDrivePausedType
+
+// Downloads.Drive.DiskSpaceLow (Notification)
+
+type DownloadsDriveDiskSpaceLowType struct {}
+
+var _ NotificationMessage = (*DownloadsDriveDiskSpaceLowType)(nil)
+
+func (r *DownloadsDriveDiskSpaceLowType) Method() string {
+  return "Downloads.Drive.DiskSpaceLow"
+}
+
+func (r *DownloadsDriveDiskSpaceLowType) Notify(rc *butlerd.RequestContext, params butlerd.DownloadsDriveDiskSpaceLowNotification) (error) {
+  return rc.Notify("Downloads.Drive.DiskSpaceLow", params)
+}
+
+func (r *DownloadsDriveDiskSpaceLowType) Register(router router, f func(*butlerd.RequestContext, butlerd.DownloadsDriveDiskSpaceLowNotification)) {
+  router.RegisterNotification("Downloads.Drive.DiskSpaceLow", func (rc *butlerd.RequestContext) {
+    var params butlerd.DownloadsDriveDiskSpaceLowNotification
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	// can't even propagate, just return
+    	return
+    }
+    f(rc, params)
+  })
+}
+
+var DownloadsDriveDiskSpaceLow *DownloadsDriveDiskSpaceLowType
+
 // Downloads.Drive.Discarded (Notification)
 
 type DownloadsDriveDiscardedType struct {}
@@ -1464,6 +1760,86 @@ func (r *FetchCaveType) TestCall(rc *butlerd.RequestContext, params butlerd.Fetc
 
 var FetchCave *FetchCaveType
 
+// Fetch.Cave.PlaySessions (Request)
+
+type FetchCavePlaySessionsType struct {}
+
+var _ RequestMessage = (*FetchCavePlaySessionsType)(nil)
+
+func (r *FetchCavePlaySessionsType) Method() string {
+  return "Fetch.Cave.PlaySessions"
+}
+
+func (r *FetchCavePlaySessionsType) Register(router router, f func(*butlerd.RequestContext, butlerd.FetchCavePlaySessionsParams) (*butlerd.FetchCavePlaySessionsResult, error)) {
+  router.Register("Fetch.Cave.PlaySessions", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.FetchCavePlaySessionsParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Fetch.Cave.PlaySessions")
+    }
+    return res, nil
+  })
+}
+
+func (r *FetchCavePlaySessionsType) TestCall(rc *butlerd.RequestContext, params butlerd.FetchCavePlaySessionsParams) (*butlerd.FetchCavePlaySessionsResult, error) {
+  var result butlerd.FetchCavePlaySessionsResult
+  err := rc.Call("Fetch.Cave.PlaySessions", params, &result)
+  return &result, err
+}
+
+var FetchCavePlaySessions *FetchCavePlaySessionsType
+
+// Fetch.PlayStats (Request)
+
+type FetchPlayStatsType struct {}
+
+var _ RequestMessage = (*FetchPlayStatsType)(nil)
+
+func (r *FetchPlayStatsType) Method() string {
+  return "Fetch.PlayStats"
+}
+
+func (r *FetchPlayStatsType) Register(router router, f func(*butlerd.RequestContext, butlerd.FetchPlayStatsParams) (*butlerd.FetchPlayStatsResult, error)) {
+  router.Register("Fetch.PlayStats", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.FetchPlayStatsParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Fetch.PlayStats")
+    }
+    return res, nil
+  })
+}
+
+func (r *FetchPlayStatsType) TestCall(rc *butlerd.RequestContext, params butlerd.FetchPlayStatsParams) (*butlerd.FetchPlayStatsResult, error) {
+  var result butlerd.FetchPlayStatsResult
+  err := rc.Call("Fetch.PlayStats", params, &result)
+  return &result, err
+}
+
+var FetchPlayStats *FetchPlayStatsType
+
 // Fetch.ExpireAll (Request)
 
 type FetchExpireAllType struct {}
@@ -1709,17 +2085,137 @@ func (r *CavesSetPinnedType) TestCall(rc *butlerd.RequestContext, params butlerd
 
 var CavesSetPinned *CavesSetPinnedType
 
-// Install.Perform (Request)
+// Caves.SetUpdatePolicy (Request)
 
-type InstallPerformType struct {}
+type CavesSetUpdatePolicyType struct {}
 
-var _ RequestMessage = (*InstallPerformType)(nil)
+var _ RequestMessage = (*CavesSetUpdatePolicyType)(nil)
 
-func (r *InstallPerformType) Method() string {
-  return "Install.Perform"
+func (r *CavesSetUpdatePolicyType) Method() string {
+  return "Caves.SetUpdatePolicy"
 }
 
-func (r *InstallPerformType) Register(router router, f func(*butlerd.RequestContext, butlerd.InstallPerformParams) (*butlerd.InstallPerformResult, error)) {
+func (r *CavesSetUpdatePolicyType) Register(router router, f func(*butlerd.RequestContext, butlerd.CavesSetUpdatePolicyParams) (*butlerd.CavesSetUpdatePolicyResult, error)) {
+  router.Register("Caves.SetUpdatePolicy", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.CavesSetUpdatePolicyParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Caves.SetUpdatePolicy")
+    }
+    return res, nil
+  })
+}
+
+func (r *CavesSetUpdatePolicyType) TestCall(rc *butlerd.RequestContext, params butlerd.CavesSetUpdatePolicyParams) (*butlerd.CavesSetUpdatePolicyResult, error) {
+  var result butlerd.CavesSetUpdatePolicyResult
+  err := rc.Call("Caves.SetUpdatePolicy", params, &result)
+  return &result, err
+}
+
+var CavesSetUpdatePolicy *CavesSetUpdatePolicyType
+
+// Install.SetPreviousVersionsKept (Request)
+
+type InstallSetPreviousVersionsKeptType struct {}
+
+var _ RequestMessage = (*InstallSetPreviousVersionsKeptType)(nil)
+
+func (r *InstallSetPreviousVersionsKeptType) Method() string {
+  return "Install.SetPreviousVersionsKept"
+}
+
+func (r *InstallSetPreviousVersionsKeptType) Register(router router, f func(*butlerd.RequestContext, butlerd.InstallSetPreviousVersionsKeptParams) (*butlerd.InstallSetPreviousVersionsKeptResult, error)) {
+  router.Register("Install.SetPreviousVersionsKept", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.InstallSetPreviousVersionsKeptParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Install.SetPreviousVersionsKept")
+    }
+    return res, nil
+  })
+}
+
+func (r *InstallSetPreviousVersionsKeptType) TestCall(rc *butlerd.RequestContext, params butlerd.InstallSetPreviousVersionsKeptParams) (*butlerd.InstallSetPreviousVersionsKeptResult, error) {
+  var result butlerd.InstallSetPreviousVersionsKeptResult
+  err := rc.Call("Install.SetPreviousVersionsKept", params, &result)
+  return &result, err
+}
+
+var InstallSetPreviousVersionsKept *InstallSetPreviousVersionsKeptType
+
+// Caves.Rollback (Request)
+
+type CavesRollbackType struct {}
+
+var _ RequestMessage = (*CavesRollbackType)(nil)
+
+func (r *CavesRollbackType) Method() string {
+  return "Caves.Rollback"
+}
+
+func (r *CavesRollbackType) Register(router router, f func(*butlerd.RequestContext, butlerd.CavesRollbackParams) (*butlerd.CavesRollbackResult, error)) {
+  router.Register("Caves.Rollback", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.CavesRollbackParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Caves.Rollback")
+    }
+    return res, nil
+  })
+}
+
+func (r *CavesRollbackType) TestCall(rc *butlerd.RequestContext, params butlerd.CavesRollbackParams) (*butlerd.CavesRollbackResult, error) {
+  var result butlerd.CavesRollbackResult
+  err := rc.Call("Caves.Rollback", params, &result)
+  return &result, err
+}
+
+var CavesRollback *CavesRollbackType
+
+// Install.Perform (Request)
+
+type InstallPerformType struct {}
+
+var _ RequestMessage = (*InstallPerformType)(nil)
+
+func (r *InstallPerformType) Method() string {
+  return "Install.Perform"
+}
+
+func (r *InstallPerformType) Register(router router, f func(*butlerd.RequestContext, butlerd.InstallPerformParams) (*butlerd.InstallPerformResult, error)) {
   router.Register("Install.Perform", func (rc *butlerd.RequestContext) (interface{}, error) {
     var params butlerd.InstallPerformParams
     err := json.Unmarshal(*rc.Params, &params)
@@ -1749,6 +2245,46 @@ func (r *InstallPerformType) TestCall(rc *butlerd.RequestContext, params butlerd
 
 var InstallPerform *InstallPerformType
 
+// Install.FromLocalFile (Request)
+
+type InstallFromLocalFileType struct {}
+
+var _ RequestMessage = (*InstallFromLocalFileType)(nil)
+
+func (r *InstallFromLocalFileType) Method() string {
+  return "Install.FromLocalFile"
+}
+
+func (r *InstallFromLocalFileType) Register(router router, f func(*butlerd.RequestContext, butlerd.InstallFromLocalFileParams) (*butlerd.InstallFromLocalFileResult, error)) {
+  router.Register("Install.FromLocalFile", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.InstallFromLocalFileParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Install.FromLocalFile")
+    }
+    return res, nil
+  })
+}
+
+func (r *InstallFromLocalFileType) TestCall(rc *butlerd.RequestContext, params butlerd.InstallFromLocalFileParams) (*butlerd.InstallFromLocalFileResult, error) {
+  var result butlerd.InstallFromLocalFileResult
+  err := rc.Call("Install.FromLocalFile", params, &result)
+  return &result, err
+}
+
+var InstallFromLocalFile *InstallFromLocalFileType
+
 // Install.Cancel (Request)
 
 type InstallCancelType struct {}
@@ -2466,6 +3002,46 @@ func (r *DownloadsClearFinishedType) TestCall(rc *butlerd.RequestContext, params
 
 var DownloadsClearFinished *DownloadsClearFinishedType
 
+// Downloads.History (Request)
+
+type DownloadsHistoryType struct {}
+
+var _ RequestMessage = (*DownloadsHistoryType)(nil)
+
+func (r *DownloadsHistoryType) Method() string {
+  return "Downloads.History"
+}
+
+func (r *DownloadsHistoryType) Register(router router, f func(*butlerd.RequestContext, butlerd.DownloadsHistoryParams) (*butlerd.DownloadsHistoryResult, error)) {
+  router.Register("Downloads.History", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.DownloadsHistoryParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Downloads.History")
+    }
+    return res, nil
+  })
+}
+
+func (r *DownloadsHistoryType) TestCall(rc *butlerd.RequestContext, params butlerd.DownloadsHistoryParams) (*butlerd.DownloadsHistoryResult, error) {
+  var result butlerd.DownloadsHistoryResult
+  err := rc.Call("Downloads.History", params, &result)
+  return &result, err
+}
+
+var DownloadsHistory *DownloadsHistoryType
+
 // Downloads.Drive (Request)
 
 type DownloadsDriveType struct {}
@@ -2626,6 +3202,166 @@ func (r *DownloadsDiscardType) TestCall(rc *butlerd.RequestContext, params butle
 
 var DownloadsDiscard *DownloadsDiscardType
 
+// Downloads.SetConcurrency (Request)
+
+type DownloadsSetConcurrencyType struct {}
+
+var _ RequestMessage = (*DownloadsSetConcurrencyType)(nil)
+
+func (r *DownloadsSetConcurrencyType) Method() string {
+  return "Downloads.SetConcurrency"
+}
+
+func (r *DownloadsSetConcurrencyType) Register(router router, f func(*butlerd.RequestContext, butlerd.DownloadsSetConcurrencyParams) (*butlerd.DownloadsSetConcurrencyResult, error)) {
+  router.Register("Downloads.SetConcurrency", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.DownloadsSetConcurrencyParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Downloads.SetConcurrency")
+    }
+    return res, nil
+  })
+}
+
+func (r *DownloadsSetConcurrencyType) TestCall(rc *butlerd.RequestContext, params butlerd.DownloadsSetConcurrencyParams) (*butlerd.DownloadsSetConcurrencyResult, error) {
+  var result butlerd.DownloadsSetConcurrencyResult
+  err := rc.Call("Downloads.SetConcurrency", params, &result)
+  return &result, err
+}
+
+var DownloadsSetConcurrency *DownloadsSetConcurrencyType
+
+// Downloads.Pause (Request)
+
+type DownloadsPauseType struct {}
+
+var _ RequestMessage = (*DownloadsPauseType)(nil)
+
+func (r *DownloadsPauseType) Method() string {
+  return "Downloads.Pause"
+}
+
+func (r *DownloadsPauseType) Register(router router, f func(*butlerd.RequestContext, butlerd.DownloadsPauseParams) (*butlerd.DownloadsPauseResult, error)) {
+  router.Register("Downloads.Pause", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.DownloadsPauseParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Downloads.Pause")
+    }
+    return res, nil
+  })
+}
+
+func (r *DownloadsPauseType) TestCall(rc *butlerd.RequestContext, params butlerd.DownloadsPauseParams) (*butlerd.DownloadsPauseResult, error) {
+  var result butlerd.DownloadsPauseResult
+  err := rc.Call("Downloads.Pause", params, &result)
+  return &result, err
+}
+
+var DownloadsPause *DownloadsPauseType
+
+// Downloads.Resume (Request)
+
+type DownloadsResumeType struct {}
+
+var _ RequestMessage = (*DownloadsResumeType)(nil)
+
+func (r *DownloadsResumeType) Method() string {
+  return "Downloads.Resume"
+}
+
+func (r *DownloadsResumeType) Register(router router, f func(*butlerd.RequestContext, butlerd.DownloadsResumeParams) (*butlerd.DownloadsResumeResult, error)) {
+  router.Register("Downloads.Resume", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.DownloadsResumeParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Downloads.Resume")
+    }
+    return res, nil
+  })
+}
+
+func (r *DownloadsResumeType) TestCall(rc *butlerd.RequestContext, params butlerd.DownloadsResumeParams) (*butlerd.DownloadsResumeResult, error) {
+  var result butlerd.DownloadsResumeResult
+  err := rc.Call("Downloads.Resume", params, &result)
+  return &result, err
+}
+
+var DownloadsResume *DownloadsResumeType
+
+// Downloads.SetBandwidthCap (Request)
+
+type DownloadsSetBandwidthCapType struct {}
+
+var _ RequestMessage = (*DownloadsSetBandwidthCapType)(nil)
+
+func (r *DownloadsSetBandwidthCapType) Method() string {
+  return "Downloads.SetBandwidthCap"
+}
+
+func (r *DownloadsSetBandwidthCapType) Register(router router, f func(*butlerd.RequestContext, butlerd.DownloadsSetBandwidthCapParams) (*butlerd.DownloadsSetBandwidthCapResult, error)) {
+  router.Register("Downloads.SetBandwidthCap", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.DownloadsSetBandwidthCapParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Downloads.SetBandwidthCap")
+    }
+    return res, nil
+  })
+}
+
+func (r *DownloadsSetBandwidthCapType) TestCall(rc *butlerd.RequestContext, params butlerd.DownloadsSetBandwidthCapParams) (*butlerd.DownloadsSetBandwidthCapResult, error) {
+  var result butlerd.DownloadsSetBandwidthCapResult
+  err := rc.Call("Downloads.SetBandwidthCap", params, &result)
+  return &result, err
+}
+
+var DownloadsSetBandwidthCap *DownloadsSetBandwidthCapType
+
 
 //==============================
 // Update
@@ -2739,15 +3475,175 @@ func (r *SnoozeCaveType) TestCall(rc *butlerd.RequestContext, params butlerd.Sno
 
 var SnoozeCave *SnoozeCaveType
 
+// Updates.SetSchedule (Request)
 
-//==============================
-// update
-//==============================
+type UpdatesSetScheduleType struct {}
 
+var _ RequestMessage = (*UpdatesSetScheduleType)(nil)
 
-//==============================
-// Launch
-//==============================
+func (r *UpdatesSetScheduleType) Method() string {
+  return "Updates.SetSchedule"
+}
+
+func (r *UpdatesSetScheduleType) Register(router router, f func(*butlerd.RequestContext, butlerd.UpdatesSetScheduleParams) (*butlerd.UpdatesSetScheduleResult, error)) {
+  router.Register("Updates.SetSchedule", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.UpdatesSetScheduleParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Updates.SetSchedule")
+    }
+    return res, nil
+  })
+}
+
+func (r *UpdatesSetScheduleType) TestCall(rc *butlerd.RequestContext, params butlerd.UpdatesSetScheduleParams) (*butlerd.UpdatesSetScheduleResult, error) {
+  var result butlerd.UpdatesSetScheduleResult
+  err := rc.Call("Updates.SetSchedule", params, &result)
+  return &result, err
+}
+
+var UpdatesSetSchedule *UpdatesSetScheduleType
+
+// Updates.GetSchedule (Request)
+
+type UpdatesGetScheduleType struct {}
+
+var _ RequestMessage = (*UpdatesGetScheduleType)(nil)
+
+func (r *UpdatesGetScheduleType) Method() string {
+  return "Updates.GetSchedule"
+}
+
+func (r *UpdatesGetScheduleType) Register(router router, f func(*butlerd.RequestContext, butlerd.UpdatesGetScheduleParams) (*butlerd.UpdatesGetScheduleResult, error)) {
+  router.Register("Updates.GetSchedule", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.UpdatesGetScheduleParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Updates.GetSchedule")
+    }
+    return res, nil
+  })
+}
+
+func (r *UpdatesGetScheduleType) TestCall(rc *butlerd.RequestContext, params butlerd.UpdatesGetScheduleParams) (*butlerd.UpdatesGetScheduleResult, error) {
+  var result butlerd.UpdatesGetScheduleResult
+  err := rc.Call("Updates.GetSchedule", params, &result)
+  return &result, err
+}
+
+var UpdatesGetSchedule *UpdatesGetScheduleType
+
+// Updates.Drive (Request)
+
+type UpdatesDriveType struct {}
+
+var _ RequestMessage = (*UpdatesDriveType)(nil)
+
+func (r *UpdatesDriveType) Method() string {
+  return "Updates.Drive"
+}
+
+func (r *UpdatesDriveType) Register(router router, f func(*butlerd.RequestContext, butlerd.UpdatesDriveParams) (*butlerd.UpdatesDriveResult, error)) {
+  router.Register("Updates.Drive", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.UpdatesDriveParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Updates.Drive")
+    }
+    return res, nil
+  })
+}
+
+func (r *UpdatesDriveType) TestCall(rc *butlerd.RequestContext, params butlerd.UpdatesDriveParams) (*butlerd.UpdatesDriveResult, error) {
+  var result butlerd.UpdatesDriveResult
+  err := rc.Call("Updates.Drive", params, &result)
+  return &result, err
+}
+
+var UpdatesDrive *UpdatesDriveType
+
+// Updates.Drive.Cancel (Request)
+
+type UpdatesDriveCancelType struct {}
+
+var _ RequestMessage = (*UpdatesDriveCancelType)(nil)
+
+func (r *UpdatesDriveCancelType) Method() string {
+  return "Updates.Drive.Cancel"
+}
+
+func (r *UpdatesDriveCancelType) Register(router router, f func(*butlerd.RequestContext, butlerd.UpdatesDriveCancelParams) (*butlerd.UpdatesDriveCancelResult, error)) {
+  router.Register("Updates.Drive.Cancel", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.UpdatesDriveCancelParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Updates.Drive.Cancel")
+    }
+    return res, nil
+  })
+}
+
+func (r *UpdatesDriveCancelType) TestCall(rc *butlerd.RequestContext, params butlerd.UpdatesDriveCancelParams) (*butlerd.UpdatesDriveCancelResult, error) {
+  var result butlerd.UpdatesDriveCancelResult
+  err := rc.Call("Updates.Drive.Cancel", params, &result)
+  return &result, err
+}
+
+var UpdatesDriveCancel *UpdatesDriveCancelType
+
+
+//==============================
+// update
+//==============================
+
+
+//==============================
+// Launch
+//==============================
 
 // Launch (Request)
 
@@ -2789,6 +3685,86 @@ func (r *LaunchType) TestCall(rc *butlerd.RequestContext, params butlerd.LaunchP
 
 var Launch *LaunchType
 
+// Launch.SetSandboxBackend (Request)
+
+type LaunchSetSandboxBackendType struct {}
+
+var _ RequestMessage = (*LaunchSetSandboxBackendType)(nil)
+
+func (r *LaunchSetSandboxBackendType) Method() string {
+  return "Launch.SetSandboxBackend"
+}
+
+func (r *LaunchSetSandboxBackendType) Register(router router, f func(*butlerd.RequestContext, butlerd.LaunchSetSandboxBackendParams) (*butlerd.LaunchSetSandboxBackendResult, error)) {
+  router.Register("Launch.SetSandboxBackend", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.LaunchSetSandboxBackendParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Launch.SetSandboxBackend")
+    }
+    return res, nil
+  })
+}
+
+func (r *LaunchSetSandboxBackendType) TestCall(rc *butlerd.RequestContext, params butlerd.LaunchSetSandboxBackendParams) (*butlerd.LaunchSetSandboxBackendResult, error) {
+  var result butlerd.LaunchSetSandboxBackendResult
+  err := rc.Call("Launch.SetSandboxBackend", params, &result)
+  return &result, err
+}
+
+var LaunchSetSandboxBackend *LaunchSetSandboxBackendType
+
+// Launch.GetSandboxBackend (Request)
+
+type LaunchGetSandboxBackendType struct {}
+
+var _ RequestMessage = (*LaunchGetSandboxBackendType)(nil)
+
+func (r *LaunchGetSandboxBackendType) Method() string {
+  return "Launch.GetSandboxBackend"
+}
+
+func (r *LaunchGetSandboxBackendType) Register(router router, f func(*butlerd.RequestContext, butlerd.LaunchGetSandboxBackendParams) (*butlerd.LaunchGetSandboxBackendResult, error)) {
+  router.Register("Launch.GetSandboxBackend", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.LaunchGetSandboxBackendParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Launch.GetSandboxBackend")
+    }
+    return res, nil
+  })
+}
+
+func (r *LaunchGetSandboxBackendType) TestCall(rc *butlerd.RequestContext, params butlerd.LaunchGetSandboxBackendParams) (*butlerd.LaunchGetSandboxBackendResult, error) {
+  var result butlerd.LaunchGetSandboxBackendResult
+  err := rc.Call("Launch.GetSandboxBackend", params, &result)
+  return &result, err
+}
+
+var LaunchGetSandboxBackend *LaunchGetSandboxBackendType
+
 // LaunchRunning (Notification)
 
 type LaunchRunningType struct {}
@@ -2845,6 +3821,34 @@ func (r *LaunchExitedType) Register(router router, f func(*butlerd.RequestContex
 
 var LaunchExited *LaunchExitedType
 
+// LaunchResourceUsage (Notification)
+
+type LaunchResourceUsageType struct {}
+
+var _ NotificationMessage = (*LaunchResourceUsageType)(nil)
+
+func (r *LaunchResourceUsageType) Method() string {
+  return "LaunchResourceUsage"
+}
+
+func (r *LaunchResourceUsageType) Notify(rc *butlerd.RequestContext, params butlerd.LaunchResourceUsageNotification) (error) {
+  return rc.Notify("LaunchResourceUsage", params)
+}
+
+func (r *LaunchResourceUsageType) Register(router router, f func(*butlerd.RequestContext, butlerd.LaunchResourceUsageNotification)) {
+  router.RegisterNotification("LaunchResourceUsage", func (rc *butlerd.RequestContext) {
+    var params butlerd.LaunchResourceUsageNotification
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	// can't even propagate, just return
+    	return
+    }
+    f(rc, params)
+  })
+}
+
+var LaunchResourceUsage *LaunchResourceUsageType
+
 // PickManifestAction (Request)
 
 type PickManifestActionType struct {}
@@ -3169,6 +4173,571 @@ func (r *PrereqsFailedType) Call(rc *butlerd.RequestContext, params butlerd.Prer
 
 var PrereqsFailed *PrereqsFailedType
 
+// Launch.List (Request)
+
+type LaunchListType struct {}
+
+var _ RequestMessage = (*LaunchListType)(nil)
+
+func (r *LaunchListType) Method() string {
+  return "Launch.List"
+}
+
+func (r *LaunchListType) Register(router router, f func(*butlerd.RequestContext, butlerd.LaunchListParams) (*butlerd.LaunchListResult, error)) {
+  router.Register("Launch.List", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.LaunchListParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Launch.List")
+    }
+    return res, nil
+  })
+}
+
+func (r *LaunchListType) TestCall(rc *butlerd.RequestContext, params butlerd.LaunchListParams) (*butlerd.LaunchListResult, error) {
+  var result butlerd.LaunchListResult
+  err := rc.Call("Launch.List", params, &result)
+  return &result, err
+}
+
+var LaunchList *LaunchListType
+
+// Launch.Stop (Request)
+
+type LaunchStopType struct {}
+
+var _ RequestMessage = (*LaunchStopType)(nil)
+
+func (r *LaunchStopType) Method() string {
+  return "Launch.Stop"
+}
+
+func (r *LaunchStopType) Register(router router, f func(*butlerd.RequestContext, butlerd.LaunchStopParams) (*butlerd.LaunchStopResult, error)) {
+  router.Register("Launch.Stop", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.LaunchStopParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Launch.Stop")
+    }
+    return res, nil
+  })
+}
+
+func (r *LaunchStopType) TestCall(rc *butlerd.RequestContext, params butlerd.LaunchStopParams) (*butlerd.LaunchStopResult, error) {
+  var result butlerd.LaunchStopResult
+  err := rc.Call("Launch.Stop", params, &result)
+  return &result, err
+}
+
+var LaunchStop *LaunchStopType
+
+// Launch.CrashReports (Request)
+
+type LaunchCrashReportsType struct {}
+
+var _ RequestMessage = (*LaunchCrashReportsType)(nil)
+
+func (r *LaunchCrashReportsType) Method() string {
+  return "Launch.CrashReports"
+}
+
+func (r *LaunchCrashReportsType) Register(router router, f func(*butlerd.RequestContext, butlerd.LaunchCrashReportsParams) (*butlerd.LaunchCrashReportsResult, error)) {
+  router.Register("Launch.CrashReports", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.LaunchCrashReportsParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Launch.CrashReports")
+    }
+    return res, nil
+  })
+}
+
+func (r *LaunchCrashReportsType) TestCall(rc *butlerd.RequestContext, params butlerd.LaunchCrashReportsParams) (*butlerd.LaunchCrashReportsResult, error) {
+  var result butlerd.LaunchCrashReportsResult
+  err := rc.Call("Launch.CrashReports", params, &result)
+  return &result, err
+}
+
+var LaunchCrashReports *LaunchCrashReportsType
+
+// CompatRunners.List (Request)
+
+type CompatRunnersListType struct {}
+
+var _ RequestMessage = (*CompatRunnersListType)(nil)
+
+func (r *CompatRunnersListType) Method() string {
+  return "CompatRunners.List"
+}
+
+func (r *CompatRunnersListType) Register(router router, f func(*butlerd.RequestContext, butlerd.CompatRunnersListParams) (*butlerd.CompatRunnersListResult, error)) {
+  router.Register("CompatRunners.List", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.CompatRunnersListParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for CompatRunners.List")
+    }
+    return res, nil
+  })
+}
+
+func (r *CompatRunnersListType) TestCall(rc *butlerd.RequestContext, params butlerd.CompatRunnersListParams) (*butlerd.CompatRunnersListResult, error) {
+  var result butlerd.CompatRunnersListResult
+  err := rc.Call("CompatRunners.List", params, &result)
+  return &result, err
+}
+
+var CompatRunnersList *CompatRunnersListType
+
+// CompatRunners.Save (Request)
+
+type CompatRunnersSaveType struct {}
+
+var _ RequestMessage = (*CompatRunnersSaveType)(nil)
+
+func (r *CompatRunnersSaveType) Method() string {
+  return "CompatRunners.Save"
+}
+
+func (r *CompatRunnersSaveType) Register(router router, f func(*butlerd.RequestContext, butlerd.CompatRunnersSaveParams) (*butlerd.CompatRunnersSaveResult, error)) {
+  router.Register("CompatRunners.Save", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.CompatRunnersSaveParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for CompatRunners.Save")
+    }
+    return res, nil
+  })
+}
+
+func (r *CompatRunnersSaveType) TestCall(rc *butlerd.RequestContext, params butlerd.CompatRunnersSaveParams) (*butlerd.CompatRunnersSaveResult, error) {
+  var result butlerd.CompatRunnersSaveResult
+  err := rc.Call("CompatRunners.Save", params, &result)
+  return &result, err
+}
+
+var CompatRunnersSave *CompatRunnersSaveType
+
+// CompatRunners.Remove (Request)
+
+type CompatRunnersRemoveType struct {}
+
+var _ RequestMessage = (*CompatRunnersRemoveType)(nil)
+
+func (r *CompatRunnersRemoveType) Method() string {
+  return "CompatRunners.Remove"
+}
+
+func (r *CompatRunnersRemoveType) Register(router router, f func(*butlerd.RequestContext, butlerd.CompatRunnersRemoveParams) (*butlerd.CompatRunnersRemoveResult, error)) {
+  router.Register("CompatRunners.Remove", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.CompatRunnersRemoveParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for CompatRunners.Remove")
+    }
+    return res, nil
+  })
+}
+
+func (r *CompatRunnersRemoveType) TestCall(rc *butlerd.RequestContext, params butlerd.CompatRunnersRemoveParams) (*butlerd.CompatRunnersRemoveResult, error) {
+  var result butlerd.CompatRunnersRemoveResult
+  err := rc.Call("CompatRunners.Remove", params, &result)
+  return &result, err
+}
+
+var CompatRunnersRemove *CompatRunnersRemoveType
+
+// Caves.SetCompatRunner (Request)
+
+type CavesSetCompatRunnerType struct {}
+
+var _ RequestMessage = (*CavesSetCompatRunnerType)(nil)
+
+func (r *CavesSetCompatRunnerType) Method() string {
+  return "Caves.SetCompatRunner"
+}
+
+func (r *CavesSetCompatRunnerType) Register(router router, f func(*butlerd.RequestContext, butlerd.CavesSetCompatRunnerParams) (*butlerd.CavesSetCompatRunnerResult, error)) {
+  router.Register("Caves.SetCompatRunner", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.CavesSetCompatRunnerParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Caves.SetCompatRunner")
+    }
+    return res, nil
+  })
+}
+
+func (r *CavesSetCompatRunnerType) TestCall(rc *butlerd.RequestContext, params butlerd.CavesSetCompatRunnerParams) (*butlerd.CavesSetCompatRunnerResult, error) {
+  var result butlerd.CavesSetCompatRunnerResult
+  err := rc.Call("Caves.SetCompatRunner", params, &result)
+  return &result, err
+}
+
+var CavesSetCompatRunner *CavesSetCompatRunnerType
+
+// Caves.SetLaunchOptions (Request)
+
+type CavesSetLaunchOptionsType struct {}
+
+var _ RequestMessage = (*CavesSetLaunchOptionsType)(nil)
+
+func (r *CavesSetLaunchOptionsType) Method() string {
+  return "Caves.SetLaunchOptions"
+}
+
+func (r *CavesSetLaunchOptionsType) Register(router router, f func(*butlerd.RequestContext, butlerd.CavesSetLaunchOptionsParams) (*butlerd.CavesSetLaunchOptionsResult, error)) {
+  router.Register("Caves.SetLaunchOptions", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.CavesSetLaunchOptionsParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Caves.SetLaunchOptions")
+    }
+    return res, nil
+  })
+}
+
+func (r *CavesSetLaunchOptionsType) TestCall(rc *butlerd.RequestContext, params butlerd.CavesSetLaunchOptionsParams) (*butlerd.CavesSetLaunchOptionsResult, error) {
+  var result butlerd.CavesSetLaunchOptionsResult
+  err := rc.Call("Caves.SetLaunchOptions", params, &result)
+  return &result, err
+}
+
+var CavesSetLaunchOptions *CavesSetLaunchOptionsType
+
+// Caves.GetLaunchOptions (Request)
+
+type CavesGetLaunchOptionsType struct {}
+
+var _ RequestMessage = (*CavesGetLaunchOptionsType)(nil)
+
+func (r *CavesGetLaunchOptionsType) Method() string {
+  return "Caves.GetLaunchOptions"
+}
+
+func (r *CavesGetLaunchOptionsType) Register(router router, f func(*butlerd.RequestContext, butlerd.CavesGetLaunchOptionsParams) (*butlerd.CavesGetLaunchOptionsResult, error)) {
+  router.Register("Caves.GetLaunchOptions", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.CavesGetLaunchOptionsParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Caves.GetLaunchOptions")
+    }
+    return res, nil
+  })
+}
+
+func (r *CavesGetLaunchOptionsType) TestCall(rc *butlerd.RequestContext, params butlerd.CavesGetLaunchOptionsParams) (*butlerd.CavesGetLaunchOptionsResult, error) {
+  var result butlerd.CavesGetLaunchOptionsResult
+  err := rc.Call("Caves.GetLaunchOptions", params, &result)
+  return &result, err
+}
+
+var CavesGetLaunchOptions *CavesGetLaunchOptionsType
+
+// Caves.CreateShortcut (Request)
+
+type CavesCreateShortcutType struct {}
+
+var _ RequestMessage = (*CavesCreateShortcutType)(nil)
+
+func (r *CavesCreateShortcutType) Method() string {
+  return "Caves.CreateShortcut"
+}
+
+func (r *CavesCreateShortcutType) Register(router router, f func(*butlerd.RequestContext, butlerd.CavesCreateShortcutParams) (*butlerd.CavesCreateShortcutResult, error)) {
+  router.Register("Caves.CreateShortcut", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.CavesCreateShortcutParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Caves.CreateShortcut")
+    }
+    return res, nil
+  })
+}
+
+func (r *CavesCreateShortcutType) TestCall(rc *butlerd.RequestContext, params butlerd.CavesCreateShortcutParams) (*butlerd.CavesCreateShortcutResult, error) {
+  var result butlerd.CavesCreateShortcutResult
+  err := rc.Call("Caves.CreateShortcut", params, &result)
+  return &result, err
+}
+
+var CavesCreateShortcut *CavesCreateShortcutType
+
+// Caves.RemoveShortcut (Request)
+
+type CavesRemoveShortcutType struct {}
+
+var _ RequestMessage = (*CavesRemoveShortcutType)(nil)
+
+func (r *CavesRemoveShortcutType) Method() string {
+  return "Caves.RemoveShortcut"
+}
+
+func (r *CavesRemoveShortcutType) Register(router router, f func(*butlerd.RequestContext, butlerd.CavesRemoveShortcutParams) (*butlerd.CavesRemoveShortcutResult, error)) {
+  router.Register("Caves.RemoveShortcut", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.CavesRemoveShortcutParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Caves.RemoveShortcut")
+    }
+    return res, nil
+  })
+}
+
+func (r *CavesRemoveShortcutType) TestCall(rc *butlerd.RequestContext, params butlerd.CavesRemoveShortcutParams) (*butlerd.CavesRemoveShortcutResult, error) {
+  var result butlerd.CavesRemoveShortcutResult
+  err := rc.Call("Caves.RemoveShortcut", params, &result)
+  return &result, err
+}
+
+var CavesRemoveShortcut *CavesRemoveShortcutType
+
+
+//==============================
+// Saves
+//==============================
+
+// Saves.Snapshot (Request)
+
+type SavesSnapshotType struct {}
+
+var _ RequestMessage = (*SavesSnapshotType)(nil)
+
+func (r *SavesSnapshotType) Method() string {
+  return "Saves.Snapshot"
+}
+
+func (r *SavesSnapshotType) Register(router router, f func(*butlerd.RequestContext, butlerd.SavesSnapshotParams) (*butlerd.SavesSnapshotResult, error)) {
+  router.Register("Saves.Snapshot", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.SavesSnapshotParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Saves.Snapshot")
+    }
+    return res, nil
+  })
+}
+
+func (r *SavesSnapshotType) TestCall(rc *butlerd.RequestContext, params butlerd.SavesSnapshotParams) (*butlerd.SavesSnapshotResult, error) {
+  var result butlerd.SavesSnapshotResult
+  err := rc.Call("Saves.Snapshot", params, &result)
+  return &result, err
+}
+
+var SavesSnapshot *SavesSnapshotType
+
+// Saves.List (Request)
+
+type SavesListType struct {}
+
+var _ RequestMessage = (*SavesListType)(nil)
+
+func (r *SavesListType) Method() string {
+  return "Saves.List"
+}
+
+func (r *SavesListType) Register(router router, f func(*butlerd.RequestContext, butlerd.SavesListParams) (*butlerd.SavesListResult, error)) {
+  router.Register("Saves.List", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.SavesListParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Saves.List")
+    }
+    return res, nil
+  })
+}
+
+func (r *SavesListType) TestCall(rc *butlerd.RequestContext, params butlerd.SavesListParams) (*butlerd.SavesListResult, error) {
+  var result butlerd.SavesListResult
+  err := rc.Call("Saves.List", params, &result)
+  return &result, err
+}
+
+var SavesList *SavesListType
+
+// Saves.Restore (Request)
+
+type SavesRestoreType struct {}
+
+var _ RequestMessage = (*SavesRestoreType)(nil)
+
+func (r *SavesRestoreType) Method() string {
+  return "Saves.Restore"
+}
+
+func (r *SavesRestoreType) Register(router router, f func(*butlerd.RequestContext, butlerd.SavesRestoreParams) (*butlerd.SavesRestoreResult, error)) {
+  router.Register("Saves.Restore", func (rc *butlerd.RequestContext) (interface{}, error) {
+    var params butlerd.SavesRestoreParams
+    err := json.Unmarshal(*rc.Params, &params)
+    if err != nil {
+    	return nil, &butlerd.RpcError{Code: jsonrpc2.CodeParseError, Message: err.Error()}
+    }
+    err = params.Validate()
+    if err != nil {
+    	return nil, err
+    }
+    res, err := f(rc, params)
+    if err != nil {
+    	return nil, err
+    }
+    if res == nil {
+    	return nil, errors.New("internal error: nil result for Saves.Restore")
+    }
+    return res, nil
+  })
+}
+
+func (r *SavesRestoreType) TestCall(rc *butlerd.RequestContext, params butlerd.SavesRestoreParams) (*butlerd.SavesRestoreResult, error) {
+  var result butlerd.SavesRestoreResult
+  err := rc.Call("Saves.Restore", params, &result)
+  return &result, err
+}
+
+var SavesRestore *SavesRestoreType
+
 
 //==============================
 // Clean Downloads
@@ -3392,6 +4961,12 @@ func EnsureAllRequests(router *butlerd.Router) {
   if _, ok := router.Handlers["Version.Get"]; !ok { panic("missing request handler for (Version.Get)") }
   if _, ok := router.Handlers["Network.SetSimulateOffline"]; !ok { panic("missing request handler for (Network.SetSimulateOffline)") }
   if _, ok := router.Handlers["Network.SetBandwidthThrottle"]; !ok { panic("missing request handler for (Network.SetBandwidthThrottle)") }
+  if _, ok := router.Handlers["Network.SetBandwidthPolicy"]; !ok { panic("missing request handler for (Network.SetBandwidthPolicy)") }
+  if _, ok := router.Handlers["Network.GetBandwidthPolicy"]; !ok { panic("missing request handler for (Network.GetBandwidthPolicy)") }
+  if _, ok := router.Handlers["Network.SetPeerCache"]; !ok { panic("missing request handler for (Network.SetPeerCache)") }
+  if _, ok := router.Handlers["Network.GetPeerCache"]; !ok { panic("missing request handler for (Network.GetPeerCache)") }
+  if _, ok := router.Handlers["Network.SetConnectivityProbe"]; !ok { panic("missing request handler for (Network.SetConnectivityProbe)") }
+  if _, ok := router.Handlers["Network.GetConnectivityProbe"]; !ok { panic("missing request handler for (Network.GetConnectivityProbe)") }
   if _, ok := router.Handlers["Profile.List"]; !ok { panic("missing request handler for (Profile.List)") }
   if _, ok := router.Handlers["Profile.LoginWithPassword"]; !ok { panic("missing request handler for (Profile.LoginWithPassword)") }
   if _, ok := router.Handlers["Profile.LoginWithAPIKey"]; !ok { panic("missing request handler for (Profile.LoginWithAPIKey)") }
@@ -3414,12 +4989,18 @@ func EnsureAllRequests(router *butlerd.Router) {
   if _, ok := router.Handlers["Fetch.Commons"]; !ok { panic("missing request handler for (Fetch.Commons)") }
   if _, ok := router.Handlers["Fetch.Caves"]; !ok { panic("missing request handler for (Fetch.Caves)") }
   if _, ok := router.Handlers["Fetch.Cave"]; !ok { panic("missing request handler for (Fetch.Cave)") }
+  if _, ok := router.Handlers["Fetch.Cave.PlaySessions"]; !ok { panic("missing request handler for (Fetch.Cave.PlaySessions)") }
+  if _, ok := router.Handlers["Fetch.PlayStats"]; !ok { panic("missing request handler for (Fetch.PlayStats)") }
   if _, ok := router.Handlers["Fetch.ExpireAll"]; !ok { panic("missing request handler for (Fetch.ExpireAll)") }
   if _, ok := router.Handlers["Game.FindUploads"]; !ok { panic("missing request handler for (Game.FindUploads)") }
   if _, ok := router.Handlers["Install.Queue"]; !ok { panic("missing request handler for (Install.Queue)") }
   if _, ok := router.Handlers["Install.Plan"]; !ok { panic("missing request handler for (Install.Plan)") }
   if _, ok := router.Handlers["Caves.SetPinned"]; !ok { panic("missing request handler for (Caves.SetPinned)") }
+  if _, ok := router.Handlers["Caves.SetUpdatePolicy"]; !ok { panic("missing request handler for (Caves.SetUpdatePolicy)") }
+  if _, ok := router.Handlers["Install.SetPreviousVersionsKept"]; !ok { panic("missing request handler for (Install.SetPreviousVersionsKept)") }
+  if _, ok := router.Handlers["Caves.Rollback"]; !ok { panic("missing request handler for (Caves.Rollback)") }
   if _, ok := router.Handlers["Install.Perform"]; !ok { panic("missing request handler for (Install.Perform)") }
+  if _, ok := router.Handlers["Install.FromLocalFile"]; !ok { panic("missing request handler for (Install.FromLocalFile)") }
   if _, ok := router.Handlers["Install.Cancel"]; !ok { panic("missing request handler for (Install.Cancel)") }
   if _, ok := router.Handlers["Uninstall.Perform"]; !ok { panic("missing request handler for (Uninstall.Perform)") }
   if _, ok := router.Handlers["Install.VersionSwitch.Queue"]; !ok { panic("missing request handler for (Install.VersionSwitch.Queue)") }
@@ -3432,13 +5013,38 @@ func EnsureAllRequests(router *butlerd.Router) {
   if _, ok := router.Handlers["Downloads.Prioritize"]; !ok { panic("missing request handler for (Downloads.Prioritize)") }
   if _, ok := router.Handlers["Downloads.List"]; !ok { panic("missing request handler for (Downloads.List)") }
   if _, ok := router.Handlers["Downloads.ClearFinished"]; !ok { panic("missing request handler for (Downloads.ClearFinished)") }
+  if _, ok := router.Handlers["Downloads.History"]; !ok { panic("missing request handler for (Downloads.History)") }
   if _, ok := router.Handlers["Downloads.Drive"]; !ok { panic("missing request handler for (Downloads.Drive)") }
   if _, ok := router.Handlers["Downloads.Drive.Cancel"]; !ok { panic("missing request handler for (Downloads.Drive.Cancel)") }
   if _, ok := router.Handlers["Downloads.Retry"]; !ok { panic("missing request handler for (Downloads.Retry)") }
   if _, ok := router.Handlers["Downloads.Discard"]; !ok { panic("missing request handler for (Downloads.Discard)") }
+  if _, ok := router.Handlers["Downloads.SetConcurrency"]; !ok { panic("missing request handler for (Downloads.SetConcurrency)") }
+  if _, ok := router.Handlers["Downloads.Pause"]; !ok { panic("missing request handler for (Downloads.Pause)") }
+  if _, ok := router.Handlers["Downloads.Resume"]; !ok { panic("missing request handler for (Downloads.Resume)") }
+  if _, ok := router.Handlers["Downloads.SetBandwidthCap"]; !ok { panic("missing request handler for (Downloads.SetBandwidthCap)") }
   if _, ok := router.Handlers["CheckUpdate"]; !ok { panic("missing request handler for (CheckUpdate)") }
   if _, ok := router.Handlers["SnoozeCave"]; !ok { panic("missing request handler for (SnoozeCave)") }
+  if _, ok := router.Handlers["Updates.SetSchedule"]; !ok { panic("missing request handler for (Updates.SetSchedule)") }
+  if _, ok := router.Handlers["Updates.GetSchedule"]; !ok { panic("missing request handler for (Updates.GetSchedule)") }
+  if _, ok := router.Handlers["Updates.Drive"]; !ok { panic("missing request handler for (Updates.Drive)") }
+  if _, ok := router.Handlers["Updates.Drive.Cancel"]; !ok { panic("missing request handler for (Updates.Drive.Cancel)") }
   if _, ok := router.Handlers["Launch"]; !ok { panic("missing request handler for (Launch)") }
+  if _, ok := router.Handlers["Launch.SetSandboxBackend"]; !ok { panic("missing request handler for (Launch.SetSandboxBackend)") }
+  if _, ok := router.Handlers["Launch.GetSandboxBackend"]; !ok { panic("missing request handler for (Launch.GetSandboxBackend)") }
+  if _, ok := router.Handlers["Launch.List"]; !ok { panic("missing request handler for (Launch.List)") }
+  if _, ok := router.Handlers["Launch.Stop"]; !ok { panic("missing request handler for (Launch.Stop)") }
+  if _, ok := router.Handlers["Launch.CrashReports"]; !ok { panic("missing request handler for (Launch.CrashReports)") }
+  if _, ok := router.Handlers["CompatRunners.List"]; !ok { panic("missing request handler for (CompatRunners.List)") }
+  if _, ok := router.Handlers["CompatRunners.Save"]; !ok { panic("missing request handler for (CompatRunners.Save)") }
+  if _, ok := router.Handlers["CompatRunners.Remove"]; !ok { panic("missing request handler for (CompatRunners.Remove)") }
+  if _, ok := router.Handlers["Caves.SetCompatRunner"]; !ok { panic("missing request handler for (Caves.SetCompatRunner)") }
+  if _, ok := router.Handlers["Caves.SetLaunchOptions"]; !ok { panic("missing request handler for (Caves.SetLaunchOptions)") }
+  if _, ok := router.Handlers["Caves.GetLaunchOptions"]; !ok { panic("missing request handler for (Caves.GetLaunchOptions)") }
+  if _, ok := router.Handlers["Caves.CreateShortcut"]; !ok { panic("missing request handler for (Caves.CreateShortcut)") }
+  if _, ok := router.Handlers["Caves.RemoveShortcut"]; !ok { panic("missing request handler for (Caves.RemoveShortcut)") }
+  if _, ok := router.Handlers["Saves.Snapshot"]; !ok { panic("missing request handler for (Saves.Snapshot)") }
+  if _, ok := router.Handlers["Saves.List"]; !ok { panic("missing request handler for (Saves.List)") }
+  if _, ok := router.Handlers["Saves.Restore"]; !ok { panic("missing request handler for (Saves.Restore)") }
   if _, ok := router.Handlers["CleanDownloads.Search"]; !ok { panic("missing request handler for (CleanDownloads.Search)") }
   if _, ok := router.Handlers["CleanDownloads.Apply"]; !ok { panic("missing request handler for (CleanDownloads.Apply)") }
   if _, ok := router.Handlers["System.StatFS"]; !ok { panic("missing request handler for (System.StatFS)") }
@@ -37,6 +37,7 @@ type Router struct {
 	Handlers             map[string]RequestHandler
 	NotificationHandlers map[string]NotificationHandler
 	CancelFuncs          *CancelFuncs
+	RunningCaves         *RunningCaves
 	dbPool               *sqlite.Pool
 	getClient            GetClientFunc
 	httpClient           *http.Client
@@ -62,6 +63,7 @@ func NewRouter(dbPool *sqlite.Pool, getClient GetClientFunc, httpClient *http.Cl
 		CancelFuncs: &CancelFuncs{
 			Funcs: make(map[string]context.CancelFunc),
 		},
+		RunningCaves:  NewRunningCaves(),
 		dbPool:        dbPool,
 		getClient:     getClient,
 		httpClient:    httpClient,
@@ -107,6 +109,30 @@ func (r *Router) RegisterNotification(method string, nh NotificationHandler) {
 	r.NotificationHandlers[method] = nh
 }
 
+// NewRequestContext returns a context in which handlers can be called
+// directly, without going through JSON-RPC. Requests and notifications
+// from handlers are sent to conn.
+func (r *Router) NewRequestContext(ctx context.Context, conn Conn, consumer *state.Consumer) *RequestContext {
+	return &RequestContext{
+		Ctx:          ctx,
+		Consumer:     consumer,
+		Conn:         conn,
+		CancelFuncs:  r.CancelFuncs,
+		RunningCaves: r.RunningCaves,
+		dbPool:       r.dbPool,
+		Client:       r.getClient,
+
+		HTTPClient:    r.httpClient,
+		HTTPTransport: r.httpTransport,
+
+		ButlerVersion:       r.ButlerVersion,
+		ButlerVersionString: r.ButlerVersionString,
+
+		Group:    r.Group,
+		Shutdown: r.initiateShutdown,
+	}
+}
+
 func (r *Router) Dispatch(ctx context.Context, origConn *jsonrpc2.Conn, req *jsonrpc2.Request) {
 	r.inflightLock.Lock()
 	r.inflightRequests[req.ID] = InFlightRequest{
@@ -156,27 +182,10 @@ func (r *Router) Dispatch(ctx context.Context, origConn *jsonrpc2.Conn, req *jso
 			}
 		}()
 
-		rc := &RequestContext{
-			Ctx:         ctx,
-			Consumer:    consumer,
-			Params:      req.Params,
-			Conn:        conn,
-			CancelFuncs: r.CancelFuncs,
-			dbPool:      r.dbPool,
-			Client:      r.getClient,
-
-			HTTPClient:    r.httpClient,
-			HTTPTransport: r.httpTransport,
-
-			ButlerVersion:       r.ButlerVersion,
-			ButlerVersionString: r.ButlerVersionString,
-
-			Group:    r.Group,
-			Shutdown: r.initiateShutdown,
-
-			origConn: origConn,
-			method:   method,
-		}
+		rc := r.NewRequestContext(ctx, conn, consumer)
+		rc.Params = req.Params
+		rc.origConn = origConn
+		rc.method = method
 
 		if req.Notif {
 			if nh, ok := r.NotificationHandlers[req.Method]; ok {
@@ -184,35 +193,7 @@ func (r *Router) Dispatch(ctx context.Context, origConn *jsonrpc2.Conn, req *jso
 			}
 		} else {
 			if h, ok := r.Handlers[method]; ok {
-				rc.Consumer.OnProgress = func(alpha float64) {
-					if rc.tracker == nil {
-						// skip
-						return
-					}
-
-					rc.tracker.SetProgress(alpha)
-					notif := ProgressNotification{
-						Progress: alpha,
-						ETA:      rc.tracker.ETA().Seconds(),
-						BPS:      rc.tracker.BPS(),
-					}
-					// cannot use autogenerated wrappers to avoid import cycles
-					rc.Notify("Progress", notif)
-				}
-				rc.Consumer.OnProgressLabel = func(label string) {
-					// muffin
-				}
-				rc.Consumer.OnPauseProgress = func() {
-					if rc.tracker != nil {
-						rc.tracker.Pause()
-					}
-				}
-				rc.Consumer.OnResumeProgress = func() {
-					if rc.tracker != nil {
-						rc.tracker.Resume()
-					}
-				}
-
+				rc.bindProgress()
 				res, err = h(rc)
 			} else {
 				err = &RpcError{
@@ -283,13 +264,14 @@ func (r *Router) Dispatch(ctx context.Context, origConn *jsonrpc2.Conn, req *jso
 }
 
 type RequestContext struct {
-	Ctx         context.Context
-	Consumer    *state.Consumer
-	Params      *json.RawMessage
-	Conn        Conn
-	CancelFuncs *CancelFuncs
-	dbPool      *sqlite.Pool
-	Client      GetClientFunc
+	Ctx          context.Context
+	Consumer     *state.Consumer
+	Params       *json.RawMessage
+	Conn         Conn
+	CancelFuncs  *CancelFuncs
+	RunningCaves *RunningCaves
+	dbPool       *sqlite.Pool
+	Client       GetClientFunc
 
 	HTTPClient    *http.Client
 	HTTPTransport *http.Transport
@@ -309,6 +291,54 @@ type RequestContext struct {
 
 type WithParamsFunc func() (interface{}, error)
 
+// bindProgress makes progress reported on rc's consumer
+// tracked by rc and sent as notifications.
+func (rc *RequestContext) bindProgress() {
+	rc.Consumer.OnProgress = func(alpha float64) {
+		if rc.tracker == nil {
+			// skip
+			return
+		}
+
+		rc.tracker.SetProgress(alpha)
+		notif := ProgressNotification{
+			Progress: alpha,
+			ETA:      rc.tracker.ETA().Seconds(),
+			BPS:      rc.tracker.BPS(),
+		}
+		// cannot use autogenerated wrappers to avoid import cycles
+		rc.Notify("Progress", notif)
+	}
+	rc.Consumer.OnProgressLabel = func(label string) {
+		// muffin
+	}
+	rc.Consumer.OnPauseProgress = func() {
+		if rc.tracker != nil {
+			rc.tracker.Pause()
+		}
+	}
+	rc.Consumer.OnResumeProgress = func() {
+		if rc.tracker != nil {
+			rc.tracker.Resume()
+		}
+	}
+}
+
+// Fork returns a request context that shares rc's connection and
+// state, but has its own context, progress tracking and notification
+// interceptors, so several operations can run concurrently for one request.
+func (rc *RequestContext) Fork(ctx context.Context) *RequestContext {
+	fork := *rc
+	fork.Ctx = ctx
+	fork.Consumer = &state.Consumer{
+		OnMessage: rc.Consumer.OnMessage,
+	}
+	fork.notificationInterceptors = nil
+	fork.tracker = nil
+	fork.bindProgress()
+	return &fork
+}
+
 type NotificationInterceptor func(method string, params interface{}) error
 
 func (rc *RequestContext) Call(method string, params interface{}, res interface{}) error {
@@ -0,0 +1,92 @@
+package butlerd
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// A RunningCave is a game that was launched through @@LaunchParams
+// and hasn't exited yet.
+type RunningCave struct {
+	// Unique identifier for this launch
+	LaunchID string
+	CaveID   string
+
+	StartedAt time.Time
+
+	// Processes returns the PIDs of all the processes that belong
+	// to the game. It is best-effort and may return nothing.
+	Processes func() []int64
+
+	// Stop asks the game to exit, waits up to gracePeriod for it to
+	// do so, then kills its processes. Returns true if it had to kill them.
+	Stop func(gracePeriod time.Duration) (bool, error)
+
+	// Done is closed once the game has exited
+	Done chan struct{}
+}
+
+// RunningCaves keeps track of all games launched by this daemon.
+type RunningCaves struct {
+	lock  sync.Mutex
+	caves map[string]*RunningCave
+}
+
+func NewRunningCaves() *RunningCaves {
+	return &RunningCaves{
+		caves: make(map[string]*RunningCave),
+	}
+}
+
+func (rcs *RunningCaves) Add(rc *RunningCave) {
+	rcs.lock.Lock()
+	defer rcs.lock.Unlock()
+	rcs.caves[rc.LaunchID] = rc
+}
+
+// AddUnlessRunning adds rc, unless the same cave is already running,
+// in which case the running instance is returned and rc isn't added.
+func (rcs *RunningCaves) AddUnlessRunning(rc *RunningCave) *RunningCave {
+	rcs.lock.Lock()
+	defer rcs.lock.Unlock()
+	for _, other := range rcs.caves {
+		if other.CaveID == rc.CaveID {
+			return other
+		}
+	}
+	rcs.caves[rc.LaunchID] = rc
+	return nil
+}
+
+func (rcs *RunningCaves) Remove(launchID string) {
+	rcs.lock.Lock()
+	defer rcs.lock.Unlock()
+	delete(rcs.caves, launchID)
+}
+
+// List returns all running caves, oldest launch first
+func (rcs *RunningCaves) List() []*RunningCave {
+	rcs.lock.Lock()
+	defer rcs.lock.Unlock()
+
+	var res []*RunningCave
+	for _, rc := range rcs.caves {
+		res = append(res, rc)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].StartedAt.Before(res[j].StartedAt)
+	})
+	return res
+}
+
+// ByCaveID returns all running instances of a given cave
+func (rcs *RunningCaves) ByCaveID(caveID string) []*RunningCave {
+	var res []*RunningCave
+	for _, rc := range rcs.List() {
+		if rc.CaveID == caveID {
+			res = append(res, rc)
+		}
+	}
+	return res
+}
@@ -1,11 +1,14 @@
 package butlerd
 
 import (
+	"net/url"
+	"path/filepath"
 	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation"
 	itchio "github.com/itchio/go-itchio"
 	"github.com/itchio/ox"
+	"github.com/pkg/errors"
 )
 
 // When using TCP transport, must be the first message sent
@@ -132,6 +135,271 @@ func (p NetworkSetBandwidthThrottleParams) Validate() error {
 
 type NetworkSetBandwidthThrottleResult struct{}
 
+// Sets a bandwidth policy, which throttles downloads depending
+// on the time of day, e.g. "unlimited at night, 2 MB/s during work hours".
+//
+// The policy is applied whenever the rate it gives changes, so a throttle
+// set with @@NetworkSetBandwidthThrottleParams lasts until then.
+// Clearing the policy goes back to that throttle.
+//
+// @name Network.SetBandwidthPolicy
+// @category Utilities
+// @caller client
+type NetworkSetBandwidthPolicyParams struct {
+	// The policy to follow, or nil to stop following one
+	//
+	// @optional
+	Policy *BandwidthPolicy `json:"policy"`
+}
+
+func (p NetworkSetBandwidthPolicyParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Policy),
+	)
+}
+
+type NetworkSetBandwidthPolicyResult struct{}
+
+// Returns the policy set with @@NetworkSetBandwidthPolicyParams
+//
+// @name Network.GetBandwidthPolicy
+// @category Utilities
+// @caller client
+type NetworkGetBandwidthPolicyParams struct{}
+
+func (p NetworkGetBandwidthPolicyParams) Validate() error {
+	return nil
+}
+
+type NetworkGetBandwidthPolicyResult struct {
+	// The current policy, if any
+	//
+	// @optional
+	Policy *BandwidthPolicy `json:"policy"`
+}
+
+// Enables or disables sharing downloads with other butlerd
+// instances on the local network, which is off by default.
+//
+// When enabled, completed install sources and patches are kept and
+// served over HTTP, and the instance announces itself to peers. They
+// are then looked for on peers before the CDN, and verified against
+// the hashes the CDN gives. Since streamed files can't be shared,
+// install sources and patches are downloaded in full before being
+// used, which needs more disk space.
+//
+// @name Network.SetPeerCache
+// @category Utilities
+// @caller client
+type NetworkSetPeerCacheParams struct {
+	Settings *PeerCacheSettings `json:"settings"`
+}
+
+func (p NetworkSetPeerCacheParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Settings, validation.Required),
+	)
+}
+
+type NetworkSetPeerCacheResult struct{}
+
+// Returns the settings set with @@NetworkSetPeerCacheParams,
+// along with the peers known at the moment.
+//
+// @name Network.GetPeerCache
+// @category Utilities
+// @caller client
+type NetworkGetPeerCacheParams struct{}
+
+func (p NetworkGetPeerCacheParams) Validate() error {
+	return nil
+}
+
+type NetworkGetPeerCacheResult struct {
+	Settings *PeerCacheSettings `json:"settings"`
+
+	// Base URLs of peers downloads can be fetched from
+	Peers []string `json:"peers"`
+}
+
+type PeerCacheSettings struct {
+	// Whether downloads are shared with peers
+	Enabled bool `json:"enabled"`
+
+	// Folder where shared downloads are kept
+	//
+	// @optional
+	Folder string `json:"folder"`
+
+	// How many downloads are kept, defaults to 5
+	//
+	// @optional
+	Keep int64 `json:"keep"`
+
+	// Address to serve downloads on, defaults to ":42771"
+	//
+	// @optional
+	Address string `json:"address"`
+
+	// UDP address to receive announcements from peers
+	// on, defaults to ":42770"
+	//
+	// @optional
+	DiscoveryAddress string `json:"discoveryAddress"`
+
+	// UDP addresses to send announcements to, defaults
+	// to broadcasting on the local network
+	//
+	// @optional
+	AnnounceAddresses []string `json:"announceAddresses"`
+
+	// Base URLs of peers that don't need to announce themselves,
+	// like "http://192.168.1.12:42771"
+	//
+	// @optional
+	Peers []string `json:"peers"`
+}
+
+func (s PeerCacheSettings) Validate() error {
+	if s.Enabled && s.Folder == "" {
+		return errors.New("folder: must be set to share downloads")
+	}
+	return validation.ValidateStruct(&s,
+		validation.Field(&s.Keep, validation.Min(0)),
+	)
+}
+
+// Changes how @@DownloadsDriveParams checks whether we're online
+// after a download fails because of the network.
+//
+// @name Network.SetConnectivityProbe
+// @category Utilities
+// @caller client
+type NetworkSetConnectivityProbeParams struct {
+	// The probe to use, or nil to go back to the default one
+	//
+	// @optional
+	Probe *ConnectivityProbe `json:"probe"`
+}
+
+func (p NetworkSetConnectivityProbeParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Probe),
+	)
+}
+
+type NetworkSetConnectivityProbeResult struct{}
+
+// Returns the probe set with @@NetworkSetConnectivityProbeParams,
+// or the default one.
+//
+// @name Network.GetConnectivityProbe
+// @category Utilities
+// @caller client
+type NetworkGetConnectivityProbeParams struct{}
+
+func (p NetworkGetConnectivityProbeParams) Validate() error {
+	return nil
+}
+
+type NetworkGetConnectivityProbeResult struct {
+	Probe *ConnectivityProbe `json:"probe"`
+}
+
+// ConnectivityProbe decides how we find out whether we're online
+type ConnectivityProbe struct {
+	// URLs to fetch, in order. We're online as soon
+	// as one of them answers as expected.
+	URLs []string `json:"urls"`
+
+	// Body the URLs are expected to answer with, leading and
+	// trailing whitespace excluded. Anything else means we're
+	// behind a captive portal, unless it's an error from the URL's
+	// own host, which means it's down. If empty, any successful
+	// answer will do, unless it comes from another host.
+	//
+	// @optional
+	ExpectedBody string `json:"expectedBody"`
+
+	// How long to wait for each URL, in seconds, at least 0.1
+	Timeout float64 `json:"timeout"`
+
+	// How long to wait between probes while offline, in seconds,
+	// at least 0.1
+	Interval float64 `json:"interval"`
+}
+
+func (p ConnectivityProbe) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.URLs, validation.Required, validation.By(validateURLs)),
+		validation.Field(&p.Timeout, validation.Min(0.1)),
+		validation.Field(&p.Interval, validation.Min(0.1)),
+	)
+}
+
+func validateURLs(value interface{}) error {
+	for _, u := range value.([]string) {
+		parsed, err := url.Parse(u)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return errors.Errorf("%q is not an http(s) URL", u)
+		}
+	}
+	return nil
+}
+
+// BandwidthPolicy decides how fast downloads can go at any time
+type BandwidthPolicy struct {
+	// Rules are checked in order, the first one
+	// that applies gives the rate.
+	Rules []*BandwidthRule `json:"rules"`
+
+	// Rate used when no rule applies, in kbps. 0 means unlimited.
+	DefaultRate int64 `json:"defaultRate"`
+}
+
+func (p BandwidthPolicy) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Rules),
+		validation.Field(&p.DefaultRate, validation.Min(0)),
+	)
+}
+
+// BandwidthRule limits bandwidth during part of the day,
+// in local time.
+type BandwidthRule struct {
+	// Days of the week the rule applies to, from 0 (Sunday)
+	// to 6 (Saturday). If empty, the rule applies every day.
+	//
+	// @optional
+	Days []int64 `json:"days"`
+
+	// When the rule starts applying, in minutes since midnight
+	Start int64 `json:"start"`
+
+	// When the rule stops applying, in minutes since midnight.
+	// If it's before Start, the rule applies over midnight.
+	End int64 `json:"end"`
+
+	// Rate while the rule applies, in kbps. 0 means unlimited.
+	Rate int64 `json:"rate"`
+}
+
+func (r BandwidthRule) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Days, validation.By(func(value interface{}) error {
+			for _, day := range value.([]int64) {
+				if day < 0 || day > 6 {
+					return errors.Errorf("invalid day of the week: %d", day)
+				}
+			}
+			return nil
+		})),
+		validation.Field(&r.Start, validation.Min(0), validation.Max(24*60)),
+		validation.Field(&r.End, validation.Min(0), validation.Max(24*60)),
+		validation.Field(&r.Rate, validation.Min(0)),
+	)
+}
+
 //----------------------------------------------------------------------
 // Profile
 //----------------------------------------------------------------------
@@ -476,6 +744,11 @@ type FetchGameUploadsParams struct {
 	// Only returns compatible uploads
 	OnlyCompatible bool `json:"compatible"`
 
+	// When OnlyCompatible is set, also consider uploads that can be
+	// launched through a @@CompatRunner compatible
+	// @optional
+	AllowCompatRunners bool `json:"allowCompatRunners,omitempty"`
+
 	// Force an API request
 	// @optional
 	Fresh bool `json:"fresh"`
@@ -1008,6 +1281,12 @@ type CaveInstallInfo struct {
 	InstallLocation string `json:"installLocation"`
 	InstallFolder   string `json:"installFolder"`
 	Pinned          bool   `json:"pinned,omitempty"`
+
+	// ID of the @@CompatRunner Windows executables are launched with
+	// @optional
+	CompatRunnerID string `json:"compatRunnerId,omitempty"`
+
+	UpdatePolicy UpdatePolicy `json:"updatePolicy"`
 }
 
 type InstallLocationSummary struct {
@@ -1115,6 +1394,117 @@ type FetchCaveResult struct {
 	Cave *Cave `json:"cave"`
 }
 
+// Retrieve the play sessions of a cave, most recent first.
+//
+// @name Fetch.Cave.PlaySessions
+// @category Fetch
+// @caller client
+type FetchCavePlaySessionsParams struct {
+	CaveID string `json:"caveId"`
+
+	// Maximum number of sessions to return at a time.
+	// @optional
+	Limit int64 `json:"limit"`
+
+	// Used for pagination, if specified
+	// @optional
+	Cursor Cursor `json:"cursor"`
+}
+
+func (p FetchCavePlaySessionsParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.CaveID, validation.Required),
+	)
+}
+
+func (p FetchCavePlaySessionsParams) GetLimit() int64 {
+	return p.Limit
+}
+
+func (p FetchCavePlaySessionsParams) GetCursor() Cursor {
+	return p.Cursor
+}
+
+type FetchCavePlaySessionsResult struct {
+	Items []*PlaySession `json:"items"`
+
+	// Use to fetch the next 'page' of results
+	// @optional
+	NextCursor Cursor `json:"nextCursor,omitempty"`
+}
+
+// A PlaySession is recorded every time a cave is launched
+type PlaySession struct {
+	// Unique identifier of the launch
+	ID     string `json:"id"`
+	CaveID string `json:"caveId"`
+	GameID int64  `json:"gameId"`
+
+	StartedAt *time.Time `json:"startedAt"`
+	// Not set if the game is still running
+	// @optional
+	EndedAt    *time.Time `json:"endedAt"`
+	SecondsRun int64      `json:"secondsRun"`
+
+	// Only set for strategies that wait for a process to exit
+	// @optional
+	ExitCode *int64 `json:"exitCode,omitempty"`
+
+	LaunchStrategy string `json:"launchStrategy"`
+
+	// Name of the manifest action that was launched
+	// @optional
+	ManifestAction string `json:"manifestAction,omitempty"`
+
+	// True if the game exited with a non-zero exit code
+	Crashed bool `json:"crashed"`
+}
+
+// Retrieve daily and weekly play time aggregates, for
+// all caves or a single one.
+//
+// @name Fetch.PlayStats
+// @category Fetch
+// @caller client
+type FetchPlayStatsParams struct {
+	// If set, only sessions of this cave are counted
+	// @optional
+	CaveID string `json:"caveId"`
+
+	// Number of days to look back, defaults to 28
+	// @optional
+	Days int64 `json:"days"`
+
+	// Offset from UTC in minutes, used to decide where days
+	// start. Defaults to 0 (UTC).
+	// @optional
+	UTCOffset int64 `json:"utcOffset"`
+}
+
+func (p FetchPlayStatsParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Days, validation.Min(0), validation.Max(3660)),
+		validation.Field(&p.UTCOffset, validation.Min(-14*60), validation.Max(14*60)),
+	)
+}
+
+type FetchPlayStatsResult struct {
+	// One entry per day, oldest first, including days without sessions
+	Daily []*PlayStatsBucket `json:"daily"`
+
+	// One entry per week (starting on Monday), oldest first
+	Weekly []*PlayStatsBucket `json:"weekly"`
+}
+
+type PlayStatsBucket struct {
+	// Start of the day or week
+	Start time.Time `json:"start"`
+
+	SecondsRun int64 `json:"secondsRun"`
+	Sessions   int64 `json:"sessions"`
+	Crashes    int64 `json:"crashes"`
+}
+
 // Mark all local data as stale.
 //
 // @name Fetch.ExpireAll
@@ -1140,6 +1530,10 @@ type FetchExpireAllResult struct{}
 type GameFindUploadsParams struct {
 	// Which game to find uploads for
 	Game *itchio.Game `json:"game"`
+
+	// Also return uploads that can be launched through a @@CompatRunner
+	// @optional
+	AllowCompatRunners bool `json:"allowCompatRunners,omitempty"`
 }
 
 func (p GameFindUploadsParams) Validate() error {
@@ -1210,6 +1604,12 @@ type InstallQueueParams struct {
 	// @optional
 	IgnoreInstallers bool `json:"ignoreInstallers,omitempty"`
 
+	// When no upload is specified, also consider uploads that can be
+	// launched through a @@CompatRunner. Always true for caves that
+	// have one set.
+	// @optional
+	AllowCompatRunners bool `json:"allowCompatRunners,omitempty"`
+
 	// A folder that butler can use to store temporary files, like
 	// partial downloads, checkpoint files, etc.
 	// @optional
@@ -1236,6 +1636,11 @@ type InstallQueueResult struct {
 	InstallFolder     string         `json:"installFolder"`
 	StagingFolder     string         `json:"stagingFolder"`
 	InstallLocationID string         `json:"installLocationId"`
+	// Estimated free space needed to perform the install, in bytes.
+	// 0 if unknown.
+	//
+	// @optional
+	NeededFreeSpace int64 `json:"neededFreeSpace,omitempty"`
 }
 
 // Sent during @@InstallQueueParams.
@@ -1271,6 +1676,10 @@ type InstallPlanParams struct {
 
 	// @optional
 	UploadID int64 `json:"uploadId"`
+
+	// Also offer uploads that can be launched through a @@CompatRunner
+	// @optional
+	AllowCompatRunners bool `json:"allowCompatRunners,omitempty"`
 }
 
 func (p InstallPlanParams) Validate() error {
@@ -1322,80 +1731,230 @@ func (p CavesSetPinnedParams) Validate() error {
 
 type CavesSetPinnedResult struct{}
 
-// Perform an install that was previously queued via
-// @@InstallQueueParams.
-//
-// Can be cancelled by passing the same `ID` to @@InstallCancelParams.
+// Decide what butler does when it finds an update for a cave.
 //
-// @name Install.Perform
+// @name Caves.SetUpdatePolicy
 // @category Install
-// @tags Cancellable
 // @caller client
-type InstallPerformParams struct {
-	// ID that can be later used in @@InstallCancelParams
-	ID string `json:"id"`
+type CavesSetUpdatePolicyParams struct {
+	// ID of the cave to change the policy of
+	CaveID string `json:"caveId"`
 
-	// The folder turned by @@InstallQueueParams
-	StagingFolder string `json:"stagingFolder"`
+	Policy UpdatePolicy `json:"policy"`
 }
 
-func (p InstallPerformParams) Validate() error {
+func (p CavesSetUpdatePolicyParams) Validate() error {
 	return validation.ValidateStruct(&p,
-		validation.Field(&p.ID, validation.Required),
-		validation.Field(&p.StagingFolder, validation.Required),
+		validation.Field(&p.CaveID, validation.Required),
+		validation.Field(&p.Policy, validation.Required, validation.In(
+			UpdatePolicyManual,
+			UpdatePolicyNotify,
+			UpdatePolicyAutoWhenIdle,
+			UpdatePolicyAutoImmediate,
+		)),
 	)
 }
 
-type InstallPerformResult struct{}
+type CavesSetUpdatePolicyResult struct{}
 
-// Attempt to gracefully cancel an ongoing operation.
+// Set how many builds replaced by updates are kept for each cave,
+// so they can be restored with @@CavesRollbackParams. Zero (the
+// default) disables it.
 //
-// @name Install.Cancel
+// Files that the update leaves untouched are hardlinked when
+// possible, so they don't take additional space.
+//
+// @name Install.SetPreviousVersionsKept
 // @category Install
 // @caller client
-type InstallCancelParams struct {
-	// The UUID of the task to cancel, as passed to @@OperationStartParams
-	ID string `json:"id"`
+type InstallSetPreviousVersionsKeptParams struct {
+	Count int64 `json:"count"`
 }
 
-func (p InstallCancelParams) Validate() error {
+func (p InstallSetPreviousVersionsKeptParams) Validate() error {
 	return validation.ValidateStruct(&p,
-		validation.Field(&p.ID, validation.Required),
+		validation.Field(&p.Count, validation.Min(0), validation.Max(10)),
 	)
 }
 
-type InstallCancelResult struct {
-	DidCancel bool `json:"didCancel"`
-}
+type InstallSetPreviousVersionsKeptResult struct{}
 
-// UninstallParams contains all the parameters needed to perform
-// an uninstallation for a game via @@OperationStartParams.
+// Restore the build a cave had before its last update, as kept
+// because of @@InstallSetPreviousVersionsKeptParams. Doesn't require
+// network access. The cave is snoozed afterwards, so that
+// automatic updates don't immediately undo the rollback.
 //
-// @name Uninstall.Perform
+// @name Caves.Rollback
 // @category Install
 // @caller client
-type UninstallPerformParams struct {
-	// The cave to uninstall
+type CavesRollbackParams struct {
 	CaveID string `json:"caveId"`
-
-	// If true, don't attempt to run any uninstallers, just
-	// remove the DB record and burn the install folder to the ground.
-	// @optional
-	Hard bool `json:"hard"`
 }
 
-func (p UninstallPerformParams) Validate() error {
+func (p CavesRollbackParams) Validate() error {
 	return validation.ValidateStruct(&p,
 		validation.Field(&p.CaveID, validation.Required),
 	)
 }
 
-type UninstallPerformResult struct{}
+type CavesRollbackResult struct {
+	// Upload now installed
+	Upload *itchio.Upload `json:"upload"`
+	// Build now installed
+	Build *itchio.Build `json:"build"`
+}
 
-// Prepare to queue a version switch. The client will
-// receive an @@InstallVersionSwitchPickParams.
+// @category Install
+type UpdatePolicy string
+
+const (
+	// Only look for updates when asked to with @@CheckUpdateParams,
+	// never during @@UpdatesDriveParams
+	UpdatePolicyManual UpdatePolicy = "manual"
+	// Send @@GameUpdateAvailableNotification, the client decides
+	// what to do. This is the default.
+	UpdatePolicyNotify UpdatePolicy = "notify"
+	// Queue direct updates, as long as no game is running
+	UpdatePolicyAutoWhenIdle UpdatePolicy = "auto-when-idle"
+	// Queue direct updates, as long as the cave isn't running
+	UpdatePolicyAutoImmediate UpdatePolicy = "auto-immediate"
+)
+
+// Perform an install that was previously queued via
+// @@InstallQueueParams.
 //
-// @name Install.VersionSwitch.Queue
+// Can be cancelled by passing the same `ID` to @@InstallCancelParams.
+//
+// @name Install.Perform
+// @category Install
+// @tags Cancellable
+// @caller client
+type InstallPerformParams struct {
+	// ID that can be later used in @@InstallCancelParams
+	ID string `json:"id"`
+
+	// The folder turned by @@InstallQueueParams
+	StagingFolder string `json:"stagingFolder"`
+}
+
+func (p InstallPerformParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.ID, validation.Required),
+		validation.Field(&p.StagingFolder, validation.Required),
+	)
+}
+
+type InstallPerformResult struct{}
+
+// Installs a game archive or installer that's already on disk, like one
+// from a bundle or a USB stick, the same way @@InstallPerformParams would
+// after downloading it, and creates a cave for it.
+//
+// If a game (and optionally an upload) is specified, the cave can be
+// checked for updates later on. Otherwise, a local stand-in game named
+// after the file is used.
+//
+// Can be cancelled by passing the same `ID` to @@InstallCancelParams.
+//
+// @name Install.FromLocalFile
+// @category Install
+// @tags Cancellable
+// @caller client
+type InstallFromLocalFileParams struct {
+	// ID that can be later used in @@InstallCancelParams
+	ID string `json:"id"`
+
+	// Absolute path of the file to install
+	Path string `json:"path"`
+
+	// ID of the install location to install to
+	InstallLocationID string `json:"installLocationId"`
+
+	// The game the file is for
+	// @optional
+	GameID int64 `json:"gameId"`
+
+	// The upload the file is for. Requires GameID.
+	// @optional
+	UploadID int64 `json:"uploadId"`
+
+	// If true, do not run windows installers, just extract
+	// whatever to the install folder.
+	// @optional
+	IgnoreInstallers bool `json:"ignoreInstallers,omitempty"`
+}
+
+func (p InstallFromLocalFileParams) Validate() error {
+	if p.UploadID != 0 && p.GameID == 0 {
+		return errors.New("gameId: must be set along with uploadId")
+	}
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.ID, validation.Required),
+		validation.Field(&p.Path, validation.Required, validation.By(validateAbsolutePath)),
+		validation.Field(&p.InstallLocationID, validation.Required),
+	)
+}
+
+func validateAbsolutePath(value interface{}) error {
+	if !filepath.IsAbs(value.(string)) {
+		return errors.New("must be an absolute path")
+	}
+	return nil
+}
+
+type InstallFromLocalFileResult struct {
+	// The cave that was created
+	Cave *Cave `json:"cave"`
+}
+
+// Attempt to gracefully cancel an ongoing operation.
+//
+// @name Install.Cancel
+// @category Install
+// @caller client
+type InstallCancelParams struct {
+	// The UUID of the task to cancel, as passed to @@OperationStartParams
+	ID string `json:"id"`
+}
+
+func (p InstallCancelParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.ID, validation.Required),
+	)
+}
+
+type InstallCancelResult struct {
+	DidCancel bool `json:"didCancel"`
+}
+
+// UninstallParams contains all the parameters needed to perform
+// an uninstallation for a game via @@OperationStartParams.
+//
+// @name Uninstall.Perform
+// @category Install
+// @caller client
+type UninstallPerformParams struct {
+	// The cave to uninstall
+	CaveID string `json:"caveId"`
+
+	// If true, don't attempt to run any uninstallers, just
+	// remove the DB record and burn the install folder to the ground.
+	// @optional
+	Hard bool `json:"hard"`
+}
+
+func (p UninstallPerformParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.CaveID, validation.Required),
+	)
+}
+
+type UninstallPerformResult struct{}
+
+// Prepare to queue a version switch. The client will
+// receive an @@InstallVersionSwitchPickParams.
+//
+// @name Install.VersionSwitch.Queue
 // @category Install
 // @caller client
 type InstallVersionSwitchQueueParams struct {
@@ -1732,7 +2291,8 @@ type DownloadsListResult struct {
 	Downloads []*Download `json:"downloads"`
 }
 
-// Removes all finished downloads from the queue.
+// Removes all finished downloads from the queue. They're
+// still part of @@DownloadsHistoryParams.
 //
 // @name Downloads.ClearFinished
 // @category Downloads
@@ -1747,9 +2307,126 @@ func (p DownloadsClearFinishedParams) Validate() error {
 type DownloadsClearFinishedResult struct {
 }
 
-// Drive downloads, which is: perform them one at a time,
+// Returns the transfer log, which records every attempt at performing
+// a download, even for downloads that were cleared since, along with
+// daily totals.
+//
+// @name Downloads.History
+// @category Downloads
+// @caller client
+type DownloadsHistoryParams struct {
+	// If set, only transfers for this game are returned
+	// @optional
+	GameID int64 `json:"gameId"`
+
+	// If set, only transfers that ended this way are returned
+	// @optional
+	Status TransferStatus `json:"status"`
+
+	// Number of days to look back, defaults to 28
+	// @optional
+	Days int64 `json:"days"`
+
+	// Offset from UTC in minutes, used to decide where days
+	// start. Defaults to 0 (UTC).
+	// @optional
+	UTCOffset int64 `json:"utcOffset"`
+
+	// Maximum number of transfers to return at a time.
+	// @optional
+	Limit int64 `json:"limit"`
+
+	// Used for pagination, if specified
+	// @optional
+	Cursor Cursor `json:"cursor"`
+}
+
+func (p DownloadsHistoryParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Status, validation.In(TransferStatusFinished, TransferStatusErrored, TransferStatusStopped)),
+		validation.Field(&p.Days, validation.Min(0), validation.Max(3660)),
+		validation.Field(&p.UTCOffset, validation.Min(-14*60), validation.Max(14*60)),
+	)
+}
+
+func (p DownloadsHistoryParams) GetLimit() int64 {
+	return p.Limit
+}
+
+func (p DownloadsHistoryParams) GetCursor() Cursor {
+	return p.Cursor
+}
+
+type DownloadsHistoryResult struct {
+	// Transfers, most recent first
+	Items []*TransferRecord `json:"items"`
+
+	// Use to fetch the next 'page' of results
+	// @optional
+	NextCursor Cursor `json:"nextCursor,omitempty"`
+
+	// One entry per day, oldest first, including days without
+	// transfers. Not affected by pagination.
+	Daily []*BandwidthBucket `json:"daily"`
+}
+
+// A TransferRecord is kept every time performing
+// a download stops, whether it finished or not.
+type TransferRecord struct {
+	ID         string         `json:"id"`
+	DownloadID string         `json:"downloadId"`
+	CaveID     string         `json:"caveId"`
+	GameID     int64          `json:"gameId"`
+	UploadID   int64          `json:"uploadId"`
+	BuildID    int64          `json:"buildId"`
+	Reason     DownloadReason `json:"reason"`
+	Status     TransferStatus `json:"status"`
+
+	StartedAt  *time.Time `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt"`
+	// How long the attempt took, in seconds
+	Duration float64 `json:"duration"`
+
+	// Bytes received over the network
+	Bytes int64 `json:"bytes"`
+	// Bytes that didn't need to be received thanks to patching
+	BytesSaved int64 `json:"bytesSaved"`
+	// Average speed while receiving, in bytes per second
+	AverageBPS float64 `json:"averageBps"`
+
+	// Standard butlerd error code, if errored
+	// @optional
+	ErrorCode *int64 `json:"errorCode,omitempty"`
+}
+
+type TransferStatus string
+
+const (
+	TransferStatusFinished TransferStatus = "finished"
+	TransferStatusErrored  TransferStatus = "errored"
+	// Cancelled, paused, deprioritized, or waiting
+	// for the network or disk space.
+	TransferStatusStopped TransferStatus = "stopped"
+)
+
+type BandwidthBucket struct {
+	// Start of the day
+	Start time.Time `json:"start"`
+
+	Bytes      int64 `json:"bytes"`
+	BytesSaved int64 `json:"bytesSaved"`
+	Transfers  int64 `json:"transfers"`
+}
+
+// Drive downloads, which is: perform them, up to
+// @@DownloadsSetConcurrencyParams at a time (one by default),
 // until they're all finished.
 //
+// The downloads with the lowest positions are performed first,
+// see @@DownloadsPrioritizeParams. Downloads that can't proceed for
+// now, like updates for games that are running, or downloads waiting
+// for disk space, don't keep the next ones from being performed.
+//
 // @name Downloads.Drive
 // @category Downloads
 // @caller client
@@ -1802,6 +2479,27 @@ type DownloadsDriveFinishedNotification struct {
 	Download *Download `json:"download"`
 }
 
+// Sent during @@DownloadsDriveParams when a download
+// was paused, see @@DownloadsPauseParams.
+//
+// @name Downloads.Drive.Paused
+type DownloadsDrivePausedNotification struct {
+	Download *Download `json:"download"`
+}
+
+// Sent during @@DownloadsDriveParams when there isn't enough
+// free space left at a download's install location. The download
+// stops, and is started again once enough space is freed.
+//
+// @name Downloads.Drive.DiskSpaceLow
+type DownloadsDriveDiskSpaceLowNotification struct {
+	Download *Download `json:"download"`
+	// Free space at the install location, in bytes
+	FreeSize int64 `json:"freeSize"`
+	// Free space needed for the download to continue, in bytes
+	NeededSize int64 `json:"neededSize"`
+}
+
 // @name Downloads.Drive.Discarded
 type DownloadsDriveDiscardedNotification struct {
 	Download *Download `json:"download"`
@@ -1821,6 +2519,9 @@ type NetworkStatus string
 const (
 	NetworkStatusOnline  NetworkStatus = "online"
 	NetworkStatusOffline NetworkStatus = "offline"
+	// Something answers, but it's not what we expect,
+	// see @@ConnectivityProbe
+	NetworkStatusCaptive NetworkStatus = "captive"
 )
 
 type DownloadReason string
@@ -1848,6 +2549,12 @@ type Download struct {
 	StartedAt     *time.Time     `json:"startedAt"`
 	FinishedAt    *time.Time     `json:"finishedAt"`
 	StagingFolder string         `json:"stagingFolder"`
+	// Paused downloads are skipped by @@DownloadsDriveParams
+	// until they're resumed.
+	Paused bool `json:"paused"`
+	// Maximum bandwidth for this download, in kbps. 0 means
+	// only the global throttle and policy apply.
+	BandwidthCap int64 `json:"bandwidthCap"`
 }
 
 type DownloadProgress struct {
@@ -1891,6 +2598,88 @@ func (p DownloadsDiscardParams) Validate() error {
 
 type DownloadsDiscardResult struct{}
 
+// Sets how many downloads @@DownloadsDriveParams performs
+// at the same time. Takes effect while driving: if it's lowered,
+// the downloads with the highest positions are stopped, to
+// be resumed later.
+//
+// @name Downloads.SetConcurrency
+// @category Downloads
+// @caller client
+type DownloadsSetConcurrencyParams struct {
+	// Maximum number of concurrent downloads
+	Concurrency int64 `json:"concurrency"`
+}
+
+func (p DownloadsSetConcurrencyParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Concurrency, validation.Required, validation.Min(1), validation.Max(8)),
+	)
+}
+
+type DownloadsSetConcurrencyResult struct{}
+
+// Pauses a download, so @@DownloadsDriveParams skips it (and
+// stops it, if it was being performed) while other downloads
+// continue. Its staging folder is kept, so it can be resumed
+// with @@DownloadsResumeParams later.
+//
+// @name Downloads.Pause
+// @category Downloads
+// @caller client
+type DownloadsPauseParams struct {
+	DownloadID string `json:"downloadId"`
+}
+
+func (p DownloadsPauseParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.DownloadID, validation.Required),
+	)
+}
+
+type DownloadsPauseResult struct{}
+
+// Resumes a download paused with @@DownloadsPauseParams
+//
+// @name Downloads.Resume
+// @category Downloads
+// @caller client
+type DownloadsResumeParams struct {
+	DownloadID string `json:"downloadId"`
+}
+
+func (p DownloadsResumeParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.DownloadID, validation.Required),
+	)
+}
+
+type DownloadsResumeResult struct{}
+
+// Caps the bandwidth of a single download, on top of the global
+// throttle and policy, for example so a background update doesn't
+// slow down a foreground install. Takes effect while the download
+// is being performed.
+//
+// @name Downloads.SetBandwidthCap
+// @category Downloads
+// @caller client
+type DownloadsSetBandwidthCapParams struct {
+	DownloadID string `json:"downloadId"`
+
+	// Maximum bandwidth, in kbps. 0 removes the cap.
+	Rate int64 `json:"rate"`
+}
+
+func (p DownloadsSetBandwidthCapParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.DownloadID, validation.Required),
+		validation.Field(&p.Rate, validation.Min(0)),
+	)
+}
+
+type DownloadsSetBandwidthCapResult struct{}
+
 //----------------------------------------------------------------------
 // CheckUpdate
 //----------------------------------------------------------------------
@@ -1926,8 +2715,8 @@ type CheckUpdateResult struct {
 	Warnings []string `json:"warnings"`
 }
 
-// Sent during @@CheckUpdateParams, every time butler
-// finds an update for a game. Can be safely ignored if displaying
+// Sent during @@CheckUpdateParams and @@UpdatesDriveParams, every time
+// butler finds an update for a game. Can be safely ignored if displaying
 // updates as they are found is not a requirement for the client.
 //
 // @category Update
@@ -1955,6 +2744,11 @@ type GameUpdate struct {
 
 	// Available choice of updates
 	Choices []*GameUpdateChoice `json:"choices"`
+
+	// True if the cave's @@UpdatePolicy allowed butler to queue
+	// the first choice as a download by itself
+	// @optional
+	Queued bool `json:"queued,omitempty"`
 }
 
 // One possible upload/build choice to upgrade a cave
@@ -1967,6 +2761,18 @@ type GameUpdateChoice struct {
 	Build *itchio.Build `json:"build"`
 	// How confident we are that this is the right upgrade
 	Confidence float64 `json:"confidence"`
+
+	// Number of bytes we expect to download to install this choice
+	TransferSize int64 `json:"transferSize"`
+
+	// True if this choice can be installed by applying
+	// patches, in which case TransferSize is the sum of their sizes.
+	// Otherwise, it's the size of the upload's archive.
+	Patched bool `json:"patched"`
+
+	// Estimated change in disk usage once installed,
+	// negative if the game would take less space
+	DiskDelta int64 `json:"diskDelta"`
 }
 
 // Snoozing a cave means we ignore all new uploads (that would
@@ -1991,43 +2797,252 @@ func (p SnoozeCaveParams) Validate() error {
 type SnoozeCaveResult struct {
 }
 
-//----------------------------------------------------------------------
-// Launch
-//----------------------------------------------------------------------
+// Configure automatic update checks, performed while
+// @@UpdatesDriveParams is running. The schedule is stored
+// in the database and takes effect immediately.
+//
+// @name Updates.SetSchedule
+// @category Update
+// @caller client
+type UpdatesSetScheduleParams struct {
+	Schedule *UpdateSchedule `json:"schedule"`
+}
 
-// Attempt to launch an installed game.
+func (p UpdatesSetScheduleParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Schedule, validation.Required),
+	)
+}
+
+type UpdatesSetScheduleResult struct{}
+
+// Retrieve the current update check schedule.
 //
-// @name Launch
-// @category Launch
+// @name Updates.GetSchedule
+// @category Update
 // @caller client
-type LaunchParams struct {
-	// The ID of the cave to launch
-	CaveID string `json:"caveId"`
+type UpdatesGetScheduleParams struct{}
 
-	// The directory to use to store installer files for prerequisites
-	PrereqsDir string `json:"prereqsDir"`
+func (p UpdatesGetScheduleParams) Validate() error {
+	return nil
+}
 
-	// Force installing all prerequisites, even if they're already marked as installed
+type UpdatesGetScheduleResult struct {
+	Schedule *UpdateSchedule `json:"schedule"`
+}
+
+// When and how often butler looks for updates on its own.
+//
+// @category Update
+type UpdateSchedule struct {
+	// Seconds between two rounds of update checks. Caves that were
+	// checked more recently than that (for example by @@CheckUpdateParams)
+	// are skipped. Zero disables automatic checks.
+	IntervalSeconds int64 `json:"intervalSeconds"`
+
+	// Up to this many seconds are randomly added to each interval,
+	// so that clients started at the same time don't all check at once.
 	// @optional
-	ForcePrereqs bool `json:"forcePrereqs,omitempty"`
+	JitterSeconds int64 `json:"jitterSeconds"`
 
-	// Enable sandbox (regardless of manifest opt-in)
+	// If set, no automatic checks are performed during these hours
 	// @optional
-	Sandbox bool `json:"sandbox,omitempty"`
+	QuietHours *QuietHours `json:"quietHours"`
+
+	// Number of caves checked in parallel, also used by
+	// @@CheckUpdateParams. Defaults to 4.
+	// @optional
+	Concurrency int64 `json:"concurrency"`
 }
 
-func (p LaunchParams) Validate() error {
-	return validation.ValidateStruct(&p,
-		validation.Field(&p.CaveID, validation.Required),
-		validation.Field(&p.PrereqsDir, validation.Required),
+func (s UpdateSchedule) Validate() error {
+	return validation.ValidateStruct(&s,
+		validation.Field(&s.IntervalSeconds, validation.Min(0)),
+		validation.Field(&s.JitterSeconds, validation.Min(0)),
+		validation.Field(&s.QuietHours),
+		validation.Field(&s.Concurrency, validation.Min(0), validation.Max(32)),
 	)
 }
 
-type LaunchResult struct {
+// A daily period, in the daemon's local time. If End
+// is before Start, the period spans midnight.
+//
+// @category Update
+type QuietHours struct {
+	// Minutes since midnight (0-1439)
+	Start int64 `json:"start"`
+	// Minutes since midnight (0-1439)
+	End int64 `json:"end"`
 }
 
-// Sent during @@LaunchParams, when the game is configured, prerequisites are installed
-// sandbox is set up (if enabled), and the game is actually running.
+func (q QuietHours) Validate() error {
+	return validation.ValidateStruct(&q,
+		validation.Field(&q.Start, validation.Min(0), validation.Max(24*60-1)),
+		validation.Field(&q.End, validation.Min(0), validation.Max(24*60-1)),
+	)
+}
+
+// Look for updates following the schedule set with
+// @@UpdatesSetScheduleParams, until cancelled. Updates found
+// are sent via @@GameUpdateAvailableNotification.
+//
+// Snoozed and pinned caves are treated as in @@CheckUpdateParams.
+//
+// @name Updates.Drive
+// @category Update
+// @caller client
+type UpdatesDriveParams struct{}
+
+func (p UpdatesDriveParams) Validate() error {
+	return nil
+}
+
+type UpdatesDriveResult struct{}
+
+// Stop driving update checks gracefully.
+//
+// @name Updates.Drive.Cancel
+// @category Update
+// @caller client
+type UpdatesDriveCancelParams struct{}
+
+func (p UpdatesDriveCancelParams) Validate() error {
+	return nil
+}
+
+type UpdatesDriveCancelResult struct {
+	DidCancel bool `json:"didCancel"`
+}
+
+//----------------------------------------------------------------------
+// Launch
+//----------------------------------------------------------------------
+
+// Attempt to launch an installed game.
+//
+// @name Launch
+// @category Launch
+// @caller client
+type LaunchParams struct {
+	// The ID of the cave to launch
+	CaveID string `json:"caveId"`
+
+	// The directory to use to store installer files for prerequisites
+	PrereqsDir string `json:"prereqsDir"`
+
+	// Force installing all prerequisites, even if they're already marked as installed
+	// @optional
+	ForcePrereqs bool `json:"forcePrereqs,omitempty"`
+
+	// Enable sandbox (regardless of manifest opt-in)
+	// @optional
+	Sandbox bool `json:"sandbox,omitempty"`
+
+	// Locale the client is using, e.g. `en-US`. Substituted for
+	// `{{locale}}` in manifest actions.
+	// @optional
+	Locale string `json:"locale,omitempty"`
+
+	// Serve HTML5 games from butlerd on a loopback address, see
+	// @@HTMLLaunchParams
+	// @optional
+	ServeHTML bool `json:"serveHTML,omitempty"`
+
+	// Sandbox implementation to use on Linux. If unset, the one set
+	// with @@LaunchSetSandboxBackendParams is used, or bubblewrap if it
+	// works on this system, or firejail.
+	// @optional
+	SandboxBackend SandboxBackend `json:"sandboxBackend,omitempty"`
+
+	// Deny network access to sandboxed games. Only supported
+	// by the bubblewrap backend.
+	// @optional
+	SandboxNoNetwork bool `json:"sandboxNoNetwork,omitempty"`
+
+	// What to do if the cave is already running. Defaults to `reject`.
+	// On Windows, only games launched by this butlerd instance are
+	// detected, not those launched by other butler processes.
+	// @optional
+	IfRunning IfRunningPolicy `json:"ifRunning,omitempty"`
+}
+
+func (p LaunchParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.CaveID, validation.Required),
+		validation.Field(&p.PrereqsDir, validation.Required),
+		validation.Field(&p.SandboxBackend, validation.In(SandboxBackendFirejail, SandboxBackendBubblewrap)),
+		validation.Field(&p.IfRunning, validation.In(IfRunningPolicyReject, IfRunningPolicyFocus, IfRunningPolicyLaunch)),
+	)
+}
+
+type IfRunningPolicy string
+
+const (
+	// Fail with error code 5002
+	IfRunningPolicyReject IfRunningPolicy = "reject"
+	// Bring the running game's window to the front (Linux only, requires
+	// xdotool), and fail with error code 5002 if that's not possible
+	IfRunningPolicyFocus IfRunningPolicy = "focus"
+	// Launch another instance anyway
+	IfRunningPolicyLaunch IfRunningPolicy = "launch"
+)
+
+type SandboxBackend string
+
+const (
+	// firejail, installed as a prerequisite
+	SandboxBackendFirejail SandboxBackend = "firejail"
+	// bubblewrap, as shipped by the distribution
+	SandboxBackendBubblewrap SandboxBackend = "bubblewrap"
+)
+
+// Sets the sandbox implementation launches use on Linux, when
+// @@LaunchParams doesn't specify one.
+//
+// @name Launch.SetSandboxBackend
+// @category Launch
+// @caller client
+type LaunchSetSandboxBackendParams struct {
+	// The backend to use, or empty to use bubblewrap if it
+	// works on this system, and firejail otherwise
+	// @optional
+	Backend SandboxBackend `json:"backend,omitempty"`
+}
+
+func (p LaunchSetSandboxBackendParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Backend, validation.In(SandboxBackendFirejail, SandboxBackendBubblewrap)),
+	)
+}
+
+type LaunchSetSandboxBackendResult struct{}
+
+// Returns the backend set with @@LaunchSetSandboxBackendParams
+//
+// @name Launch.GetSandboxBackend
+// @category Launch
+// @caller client
+type LaunchGetSandboxBackendParams struct{}
+
+func (p LaunchGetSandboxBackendParams) Validate() error {
+	return nil
+}
+
+type LaunchGetSandboxBackendResult struct {
+	// Empty if none was set
+	// @optional
+	Backend SandboxBackend `json:"backend,omitempty"`
+}
+
+type LaunchResult struct {
+	// Set if the game was already running, and its window was
+	// brought to the front instead of launching it again
+	// @optional
+	Focused bool `json:"focused,omitempty"`
+}
+
+// Sent during @@LaunchParams, when the game is configured, prerequisites are installed
+// sandbox is set up (if enabled), and the game is actually running.
 //
 // @category Launch
 type LaunchRunningNotification struct{}
@@ -2037,6 +3052,28 @@ type LaunchRunningNotification struct{}
 // @category Launch
 type LaunchExitedNotification struct{}
 
+// Sent periodically during @@LaunchParams while the game is running.
+// Only sent on Linux.
+//
+// @category Launch
+type LaunchResourceUsageNotification struct {
+	// Unique identifier for this launch
+	LaunchID string `json:"launchId"`
+
+	// CPU time used by the game's processes since they started, in seconds
+	CPUSeconds float64 `json:"cpuSeconds"`
+
+	// CPU usage since the previous notification, where 100 means one
+	// core was busy all the time
+	CPUPercent float64 `json:"cpuPercent"`
+
+	// Resident memory of all the game's processes, in bytes
+	RSS int64 `json:"rss"`
+
+	// Number of processes the game spawned, besides the main one
+	Children int64 `json:"children"`
+}
+
 // Sent during @@LaunchParams, ask the user to pick a manifest action to launch.
 //
 // See [itch app manifests](https://itch.io/docs/itch/integrating/manifest.html).
@@ -2058,6 +3095,11 @@ func (p PickManifestActionParams) Validate() error {
 type PickManifestActionResult struct {
 	// Index of action picked by user, or negative if aborting
 	Index int `json:"index"`
+
+	// Remember the choice in the cave's @@LaunchOptions, so the user
+	// isn't asked again
+	// @optional
+	Remember bool `json:"remember,omitempty"`
 }
 
 // Ask the client to perform a shell launch, ie. open an item
@@ -2098,6 +3140,16 @@ type HTMLLaunchParams struct {
 	Args []string `json:"args"`
 	// Environment variables, to pass as `global.Itch.env`
 	Env map[string]string `json:"env"`
+
+	// Set if @@LaunchParams was called with `serveHTML`: address at which
+	// butlerd serves the root folder, with correct MIME types, precompressed
+	// assets and cross-origin isolation headers. Clients should load this
+	// instead of serving the files themselves. It contains a secret that
+	// changes with every launch, and only accepts requests made to that
+	// exact host. It stops being served as soon as the client replies
+	// to this request.
+	// @optional
+	URL string `json:"url,omitempty"`
 }
 
 func (p HTMLLaunchParams) Validate() error {
@@ -2239,6 +3291,486 @@ type PrereqsFailedResult struct {
 	Continue bool `json:"continue"`
 }
 
+// List games that are currently running, ie. that were launched via
+// @@LaunchParams and haven't exited yet.
+//
+// @name Launch.List
+// @category Launch
+// @caller client
+type LaunchListParams struct{}
+
+func (p LaunchListParams) Validate() error {
+	return nil
+}
+
+type LaunchListResult struct {
+	// Running games, oldest launch first
+	Games []*RunningGame `json:"games"`
+}
+
+// A game that is currently running
+//
+// @category Launch
+type RunningGame struct {
+	// The ID of the cave that was launched
+	CaveID string `json:"caveId"`
+	// Unique identifier for this launch
+	LaunchID string `json:"launchId"`
+	// When the game was launched
+	StartedAt time.Time `json:"startedAt"`
+	// Process identifiers of the game and its children, if known.
+	// Always empty on Windows.
+	PIDs []int64 `json:"pids"`
+}
+
+// Stop a running game. Asks it to exit first, and if it's still
+// running after the grace period, kills it and all of its child processes.
+//
+// On Windows, the game and its child processes are killed right away,
+// and `forced` is always set.
+//
+// The corresponding @@LaunchParams call returns once the game is stopped.
+//
+// @name Launch.Stop
+// @category Launch
+// @caller client
+type LaunchStopParams struct {
+	// The ID of the cave to stop
+	CaveID string `json:"caveId"`
+
+	// How long to wait for the game to exit before killing it,
+	// in seconds. Defaults to 10.
+	// @optional
+	GracePeriod float64 `json:"gracePeriod,omitempty"`
+}
+
+func (p LaunchStopParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.CaveID, validation.Required),
+	)
+}
+
+type LaunchStopResult struct {
+	// True if the game was running
+	DidStop bool `json:"didStop"`
+	// True if the game had to be killed
+	Forced bool `json:"forced"`
+}
+
+// Retrieve the crash reports recorded when natively-launched
+// games exited abnormally, most recent first.
+//
+// @name Launch.CrashReports
+// @category Launch
+// @caller client
+type LaunchCrashReportsParams struct {
+	// If set, only reports for this cave are returned
+	// @optional
+	CaveID string `json:"caveId"`
+
+	// Maximum number of reports to return
+	// @optional
+	Limit int64 `json:"limit"`
+}
+
+func (p LaunchCrashReportsParams) Validate() error {
+	return nil
+}
+
+type LaunchCrashReportsResult struct {
+	Reports []*CrashReport `json:"reports"`
+}
+
+// A CompatRunner is a compatibility tool, like Wine or Proton,
+// used to launch Windows executables on Linux.
+type CompatRunner struct {
+	// Chosen by the client, like `wine-staging`
+	ID string `json:"id"`
+
+	// Human-friendly name
+	Name string `json:"name"`
+
+	// Decides how Command is invoked
+	Kind CompatRunnerKind `json:"kind"`
+
+	// Absolute path to the `wine` binary or `proton` script
+	Command string `json:"command"`
+}
+
+func (r CompatRunner) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.ID, validation.Required),
+		validation.Field(&r.Kind, validation.Required, validation.In(CompatRunnerKindWine, CompatRunnerKindProton)),
+		validation.Field(&r.Command, validation.Required),
+	)
+}
+
+type CompatRunnerKind string
+
+const (
+	// Invoked as `command game.exe args...` with `WINEPREFIX` set
+	CompatRunnerKindWine CompatRunnerKind = "wine"
+	// Invoked as `command run game.exe args...` with `STEAM_COMPAT_DATA_PATH` set.
+	// `STEAM_COMPAT_CLIENT_INSTALL_PATH` is passed through if set in butler's
+	// environment, and points to the prefix otherwise.
+	CompatRunnerKindProton CompatRunnerKind = "proton"
+)
+
+// List configured compatibility runners.
+//
+// @name CompatRunners.List
+// @category Launch
+// @caller client
+type CompatRunnersListParams struct{}
+
+func (p CompatRunnersListParams) Validate() error {
+	return nil
+}
+
+type CompatRunnersListResult struct {
+	Runners []*CompatRunner `json:"runners"`
+}
+
+// Add or update a compatibility runner.
+//
+// @name CompatRunners.Save
+// @category Launch
+// @caller client
+type CompatRunnersSaveParams struct {
+	Runner *CompatRunner `json:"runner"`
+}
+
+func (p CompatRunnersSaveParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Runner, validation.Required),
+	)
+}
+
+type CompatRunnersSaveResult struct{}
+
+// Remove a compatibility runner. Caves that used it go
+// back to launching Windows executables directly.
+//
+// @name CompatRunners.Remove
+// @category Launch
+// @caller client
+type CompatRunnersRemoveParams struct {
+	ID string `json:"id"`
+}
+
+func (p CompatRunnersRemoveParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.ID, validation.Required),
+	)
+}
+
+type CompatRunnersRemoveResult struct{}
+
+// Select the compatibility runner used to launch a cave's
+// Windows executables on Linux.
+//
+// @name Caves.SetCompatRunner
+// @category Launch
+// @caller client
+type CavesSetCompatRunnerParams struct {
+	CaveID string `json:"caveId"`
+
+	// ID of a @@CompatRunner, empty to stop using one
+	// @optional
+	RunnerID string `json:"runnerId"`
+
+	// Wine prefix (or Proton compat data path) to use. Defaults
+	// to a folder in the cave's install folder.
+	// @optional
+	PrefixDir string `json:"prefixDir"`
+}
+
+func (p CavesSetCompatRunnerParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.CaveID, validation.Required),
+	)
+}
+
+type CavesSetCompatRunnerResult struct {
+	// The prefix folder that will be used
+	// @optional
+	PrefixDir string `json:"prefixDir,omitempty"`
+}
+
+// Player-set overrides for launching a cave, see @@CavesSetLaunchOptionsParams
+type LaunchOptions struct {
+	// Appended to the arguments of the manifest action, may contain
+	// template variables like `{{installFolder}}`
+	// @optional
+	Args []string `json:"args,omitempty"`
+
+	// Environment variables, take precedence over those of the manifest action
+	// @optional
+	Env map[string]string `json:"env,omitempty"`
+
+	// Index of the manifest action to launch, in the list passed to
+	// @@PickManifestActionParams. If set, the player isn't asked.
+	// @optional
+	ManifestActionIndex *int64 `json:"manifestActionIndex,omitempty"`
+
+	// Path of the file to launch when there's no manifest, relative to the
+	// install folder. If set, the player isn't asked.
+	// @optional
+	CandidatePath string `json:"candidatePath,omitempty"`
+
+	// Forces the sandbox on or off, regardless of @@LaunchParams and
+	// the manifest
+	// @optional
+	Sandbox *bool `json:"sandbox,omitempty"`
+}
+
+func (o LaunchOptions) Validate() error {
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.ManifestActionIndex, validation.Min(0)),
+	)
+}
+
+// Replace the launch options of a cave.
+//
+// @name Caves.SetLaunchOptions
+// @category Launch
+// @caller client
+type CavesSetLaunchOptionsParams struct {
+	CaveID string `json:"caveId"`
+
+	Options *LaunchOptions `json:"options"`
+}
+
+func (p CavesSetLaunchOptionsParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.CaveID, validation.Required),
+		validation.Field(&p.Options, validation.Required),
+	)
+}
+
+type CavesSetLaunchOptionsResult struct{}
+
+// Retrieve the launch options of a cave.
+//
+// @name Caves.GetLaunchOptions
+// @category Launch
+// @caller client
+type CavesGetLaunchOptionsParams struct {
+	CaveID string `json:"caveId"`
+}
+
+func (p CavesGetLaunchOptionsParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.CaveID, validation.Required),
+	)
+}
+
+type CavesGetLaunchOptionsResult struct {
+	Options *LaunchOptions `json:"options"`
+}
+
+// Add a cave to the application menu, by writing a desktop entry
+// that launches it through butler. Only supported on Linux.
+//
+// The icon is the one listed in the cave's manifest, or the game's cover.
+// Shortcuts are removed when the cave is uninstalled.
+//
+// Games launched from a shortcut run in a separate butler process, so
+// they don't show up in @@LaunchRunningNotification or in the running
+// caves of the daemon. Only the lock file written to the install folder
+// on launch (on Linux) keeps them from being launched twice.
+//
+// @name Caves.CreateShortcut
+// @category Launch
+// @caller client
+type CavesCreateShortcutParams struct {
+	CaveID string `json:"caveId"`
+
+	// The directory to use to store installer files for prerequisites,
+	// see @@LaunchParams
+	// @optional
+	PrereqsDir string `json:"prereqsDir"`
+}
+
+func (p CavesCreateShortcutParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.CaveID, validation.Required),
+	)
+}
+
+type CavesCreateShortcutResult struct {
+	// Path of the desktop entry
+	Path string `json:"path"`
+
+	// Path of the icon, empty if a generic icon is used
+	// @optional
+	IconPath string `json:"iconPath,omitempty"`
+}
+
+// Remove a cave from the application menu.
+//
+// @name Caves.RemoveShortcut
+// @category Launch
+// @caller client
+type CavesRemoveShortcutParams struct {
+	CaveID string `json:"caveId"`
+}
+
+func (p CavesRemoveShortcutParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.CaveID, validation.Required),
+	)
+}
+
+type CavesRemoveShortcutResult struct {
+	// False if the cave had no shortcut
+	Removed bool `json:"removed"`
+}
+
+// A CrashReport is recorded every time a game exits abnormally.
+type CrashReport struct {
+	ID       string `json:"id"`
+	LaunchID string `json:"launchId"`
+
+	CaveID   string `json:"caveId"`
+	GameID   int64  `json:"gameId"`
+	UploadID int64  `json:"uploadId"`
+	// @optional
+	BuildID int64 `json:"buildId,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Exit code of the game's process
+	ExitCode int64 `json:"exitCode"`
+	// Name of the signal that killed the game, if any
+	// @optional
+	Signal string `json:"signal,omitempty"`
+	// How long the game ran for, in seconds
+	Duration float64 `json:"duration"`
+
+	// Something like `linux-amd64`
+	Runtime string `json:"runtime"`
+	// Path of the executable that was launched
+	Target string `json:"target"`
+
+	// Where the report is stored on disk
+	Path string `json:"path"`
+
+	// Last lines of standard output. Empty if the report
+	// could not be read from disk.
+	Stdout []string `json:"stdout"`
+	// Last lines of standard error
+	Stderr []string `json:"stderr"`
+}
+
+//----------------------------------------------------------------------
+// Saves
+//----------------------------------------------------------------------
+
+// Back up the saves of a cave, as listed in the `saves` section of
+// its manifest. Each snapshot is a numbered zip in the cave's backup
+// folder, which is kept when the cave is uninstalled.
+//
+// @name Saves.Snapshot
+// @category Saves
+// @caller client
+type SavesSnapshotParams struct {
+	CaveID string `json:"caveId"`
+}
+
+func (p SavesSnapshotParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.CaveID, validation.Required),
+	)
+}
+
+type SavesSnapshotResult struct {
+	Snapshot *SaveSnapshot `json:"snapshot"`
+}
+
+// List the save snapshots of a cave, most recent first.
+//
+// @name Saves.List
+// @category Saves
+// @caller client
+type SavesListParams struct {
+	CaveID string `json:"caveId"`
+}
+
+func (p SavesListParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.CaveID, validation.Required),
+	)
+}
+
+type SavesListResult struct {
+	Snapshots []*SaveSnapshot `json:"snapshots"`
+}
+
+// Restore a save snapshot, overwriting the files it contains.
+// The current saves are snapshotted first, so a restore can be undone.
+//
+// @name Saves.Restore
+// @category Saves
+// @caller client
+type SavesRestoreParams struct {
+	CaveID string `json:"caveId"`
+
+	// Version of the snapshot to restore, as returned by @@SavesListParams
+	Version int64 `json:"version"`
+}
+
+func (p SavesRestoreParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.CaveID, validation.Required),
+		validation.Field(&p.Version, validation.Required),
+	)
+}
+
+type SavesRestoreResult struct {
+	// Number of files written
+	Files int64 `json:"files"`
+
+	// Snapshot of the saves as they were before restoring, if there were any
+	// @optional
+	Backup *SaveSnapshot `json:"backup,omitempty"`
+}
+
+// A SaveSnapshot is a zip of all the save paths of a cave at a given time.
+type SaveSnapshot struct {
+	// Increases by one with each snapshot of a cave
+	Version int64 `json:"version"`
+
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Why the snapshot was taken
+	Reason SaveSnapshotReason `json:"reason"`
+
+	// Number of files in the snapshot
+	Files int64 `json:"files"`
+
+	// Total uncompressed size of the files, in bytes
+	Size int64 `json:"size"`
+
+	// Where the snapshot is stored on disk
+	Path string `json:"path"`
+}
+
+type SaveSnapshotReason string
+
+const (
+	// Requested with @@SavesSnapshotParams
+	SaveSnapshotReasonManual SaveSnapshotReason = "manual"
+	// Taken before a cave is uninstalled
+	SaveSnapshotReasonUninstall SaveSnapshotReason = "uninstall"
+	// Taken before switching to another version of a game
+	SaveSnapshotReasonVersionSwitch SaveSnapshotReason = "version-switch"
+	// Taken after a game exits
+	SaveSnapshotReasonPlaySession SaveSnapshotReason = "play-session"
+	// Taken before restoring another snapshot
+	SaveSnapshotReasonRestore SaveSnapshotReason = "restore"
+)
+
 //----------------------------------------------------------------------
 // CleanDownloads
 //----------------------------------------------------------------------
@@ -2416,6 +3948,9 @@ const (
 	// We tried to install something, but could not find compatible uploads
 	CodeNoCompatibleUploads Code = 2001
 
+	// There is no previous version to roll back to, see @@CavesRollbackParams
+	CodeNoPreviousVersion Code = 2002
+
 	// This title is packaged in a way that is not supported.
 	CodeUnsupportedPackaging Code = 3000
 
@@ -2425,6 +3960,12 @@ const (
 	// Nothing that can be launched was found
 	CodeNoLaunchCandidates Code = 5000
 
+	// A pre-launch hook from the manifest failed
+	CodePreLaunchHookFailed Code = 5001
+
+	// The game is already running, see @@IfRunningPolicy
+	CodeAlreadyRunning Code = 5002
+
 	// There is no Internet connection
 	CodeNetworkDisconnected Code = 9000
 
@@ -2451,6 +3992,16 @@ type Manifest struct {
 	// Prereqs describe libraries or frameworks that must be installed
 	// prior to launching a game
 	Prereqs []*Prereq `json:"prereqs,omitempty"`
+
+	// Hooks are commands run before a game is launched, or after it exits
+	Hooks []*Hook `json:"hooks,omitempty"`
+
+	// Saves lists where the game stores its saves, so they can be backed up
+	Saves []*SavePath `json:"saves,omitempty"`
+
+	// image used for desktop shortcuts, relative to the install folder.
+	// PNG or SVG, ideally square and at least 256x256
+	Icon string `json:"icon,omitempty"`
 }
 
 // An Action is a choice for the user to pick when launching a game.
@@ -2461,6 +4012,7 @@ type Action struct {
 	Name string `json:"name"`
 
 	// file path (relative to manifest or absolute), URL, etc.
+	// may contain template variables like `{{installFolder}}`, `{{arch}}` or `{{locale}}`
 	Path string `json:"path"`
 
 	// icon name (see static/fonts/icomoon/demo.html, don't include `icon-` prefix)
@@ -2469,6 +4021,13 @@ type Action struct {
 	// command-line arguments
 	Args []string `json:"args,omitempty"`
 
+	// environment variables to set (values may contain template variables)
+	Env map[string]string `json:"env,omitempty"`
+
+	// working directory (relative to manifest or absolute), defaults to the
+	// folder the target is in
+	WorkingDir string `json:"workingDir,omitempty"`
+
 	// sandbox opt-in
 	Sandbox bool `json:"sandbox,omitempty"`
 
@@ -2481,6 +4040,13 @@ type Action struct {
 	// platform to restrict this action too
 	Platform ox.Platform `json:"platform,omitempty"`
 
+	// architecture to restrict this action to (`386` or `amd64`)
+	Arch string `json:"arch,omitempty"`
+
+	// condition that must hold for this action to be offered,
+	// for example `osVersion >= 10.0 && env.VK_ICD_FILENAMES`
+	Condition string `json:"condition,omitempty"`
+
 	// localized action name
 	Locales map[string]*ActionLocale `json:"locales,omitempty"`
 }
@@ -2490,6 +4056,45 @@ type Prereq struct {
 	Name string `json:"name"`
 }
 
+// A Hook is a command run in the install folder around a launch,
+// with the same sandbox and environment as the launched action.
+type Hook struct {
+	// when to run the hook
+	Event HookEvent `json:"event"`
+
+	// file path (relative to manifest or absolute), may contain template variables
+	Path string `json:"path"`
+
+	// command-line arguments
+	Args []string `json:"args,omitempty"`
+
+	// platform to restrict this hook to
+	Platform ox.Platform `json:"platform,omitempty"`
+
+	// maximum running time, in seconds (defaults to 60)
+	Timeout int64 `json:"timeout,omitempty"`
+}
+
+// A SavePath is a file, folder or glob pattern holding a game's saves.
+type SavePath struct {
+	// relative to the install folder, or starting with a user folder
+	// variable: `{{home}}`, `{{userData}}`, `{{userConfig}}` or `{{documents}}`.
+	// may use `*`, `?` and `**` (any number of folders)
+	Path string `json:"path"`
+
+	// platform to restrict this save path to
+	Platform ox.Platform `json:"platform,omitempty"`
+}
+
+type HookEvent string
+
+const (
+	// Run before the game is launched. If it fails, the launch is aborted
+	HookEventPreLaunch HookEvent = "pre-launch"
+	// Run after the game exits, whether it succeeded or not
+	HookEventPostExit HookEvent = "post-exit"
+)
+
 type ActionLocale struct {
 	// A localized action name
 	Name string `json:"name"`
@@ -0,0 +1,78 @@
+package crashes
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/itchio/butler/comm"
+	"github.com/itchio/butler/endpoints/launch/crashes"
+	"github.com/itchio/butler/mansion"
+	"github.com/itchio/httpkit/progress"
+	"github.com/pkg/errors"
+)
+
+var args = struct {
+	dir   *string
+	limit *int64
+}{}
+
+func Register(ctx *mansion.Context) {
+	cmd := ctx.App.Command("crashes", "Show crash reports recorded for a game's install folder")
+	args.dir = cmd.Arg("dir", "Install folder of the game").Required().String()
+	args.limit = cmd.Flag("limit", "Only show the most recent reports").Default("0").Int64()
+	ctx.Register(cmd, do)
+}
+
+func do(ctx *mansion.Context) {
+	ctx.Must(Do(*args.dir, *args.limit))
+}
+
+func Do(dir string, limit int64) error {
+	reports, err := crashes.List(dir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if limit > 0 && int64(len(reports)) > limit {
+		reports = reports[:limit]
+	}
+
+	if len(reports) == 0 {
+		comm.Statf("No crash reports in (%s)", crashes.Dir(dir))
+		return nil
+	}
+
+	for _, r := range reports {
+		comm.ResultOrPrint(r, func() {
+			printReport(r)
+		})
+	}
+	return nil
+}
+
+func printReport(r *crashes.Report) {
+	exit := fmt.Sprintf("exit code %d", r.ExitCode)
+	if r.Signal != "" {
+		exit = fmt.Sprintf("signal %s", r.Signal)
+	}
+
+	comm.Logf("")
+	comm.Statf("%s: %s after %s", r.CreatedAt.Local().Format(time.RFC1123), exit, progress.FormatDuration(time.Duration(r.Duration*float64(time.Second))))
+	comm.Logf("  Target:  %s", r.Target)
+	comm.Logf("  Runtime: %s", r.Runtime)
+	comm.Logf("  Game %d, upload %d, build %d", r.GameID, r.UploadID, r.BuildID)
+	comm.Logf("  Report:  %s", r.ID)
+
+	printLines := func(name string, lines []string) {
+		if len(lines) == 0 {
+			comm.Logf("  %s: empty", name)
+			return
+		}
+		comm.Logf("  %s:", name)
+		for _, l := range lines {
+			comm.Logf("    %s", l)
+		}
+	}
+	printLines("Standard error", r.Stderr)
+	printLines("Standard output", r.Stdout)
+}
@@ -11,6 +11,7 @@ import (
 	"crawshaw.io/sqlite"
 	"github.com/google/uuid"
 	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/cmd/operate"
 	"github.com/itchio/butler/database"
 	"github.com/sourcegraph/jsonrpc2"
 
@@ -75,11 +76,24 @@ func do(ctx *mansion.Context) {
 		conn := dbPool.Get(context.Background().Done())
 		defer dbPool.Put(conn)
 		err = database.Prepare(conn)
+		if err != nil {
+			return
+		}
+
+		err = operate.ConfigurePeerCache(operate.PeerCacheSettings(conn))
+		if err != nil {
+			comm.Warnf("Could not share downloads with LAN peers: %+v", err)
+			err = nil
+		}
 	}()
 	if err != nil {
 		ctx.Must(errors.WithMessage(err, "preparing DB"))
 	}
 
+	policyCtx, cancelPolicy := context.WithCancel(context.Background())
+	defer cancelPolicy()
+	go operate.FollowBandwidthPolicy(policyCtx, dbPool)
+
 	ctx.Must(Do(ctx, context.Background(), dbPool, secret))
 }
 
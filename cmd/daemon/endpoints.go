@@ -11,7 +11,9 @@ import (
 	"github.com/itchio/butler/endpoints/launch"
 	"github.com/itchio/butler/endpoints/meta"
 	"github.com/itchio/butler/endpoints/profile"
+	"github.com/itchio/butler/endpoints/saves"
 	"github.com/itchio/butler/endpoints/search"
+	"github.com/itchio/butler/endpoints/shortcuts"
 	"github.com/itchio/butler/endpoints/system"
 	"github.com/itchio/butler/endpoints/tests"
 	"github.com/itchio/butler/endpoints/update"
@@ -42,6 +44,8 @@ func getRouter(dbPool *sqlite.Pool, mansionContext *mansion.Context) *butlerd.Ro
 	downloads.Register(mainRouter)
 	search.Register(mainRouter)
 	system.Register(mainRouter)
+	saves.Register(mainRouter)
+	shortcuts.Register(mainRouter)
 
 	messages.EnsureAllRequests(mainRouter)
 
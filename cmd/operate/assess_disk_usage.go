@@ -92,14 +92,20 @@ func AssessDiskUsage(sourceFile eos.File, receiptIn *bfs.Receipt, installFolder
 		return nil, errors.WithStack(err)
 	}
 
-	// let's assume the uncompressed game is 1.3x as
-	// large as the install source. this could be completely
-	// inaccurate in either direction.
 	downloadSize := stats.Size()
-	installSize := downloadSize * 130 / 100
+	installSize := GuessInstallSize(downloadSize)
 
 	dui.NeededFreeSpace = downloadSize + installSize
 	dui.FinalDiskUsage = installSize
 
 	return dui, nil
 }
+
+// GuessInstallSize estimates how much space a game takes once
+// installed, from the size of its archive.
+func GuessInstallSize(archiveSize int64) int64 {
+	// let's assume the uncompressed game is 1.3x as
+	// large as the install source. this could be completely
+	// inaccurate in either direction.
+	return archiveSize * 130 / 100
+}
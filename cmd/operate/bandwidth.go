@@ -0,0 +1,91 @@
+package operate
+
+import (
+	"context"
+	"time"
+
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/cmd/operate/throttle"
+	"github.com/itchio/butler/database/models"
+	"github.com/itchio/wharf/eos/option"
+)
+
+const bandwidthPolicySetting = "bandwidth_policy"
+
+// BandwidthPolicy returns the policy set with Network.SetBandwidthPolicy, if any
+func BandwidthPolicy(conn *sqlite.Conn) *butlerd.BandwidthPolicy {
+	var policy *butlerd.BandwidthPolicy
+	models.GetSetting(conn, bandwidthPolicySetting, &policy)
+	return policy
+}
+
+func SetBandwidthPolicy(conn *sqlite.Conn, policy *butlerd.BandwidthPolicy) {
+	models.SetSetting(conn, bandwidthPolicySetting, policy)
+}
+
+// FollowBandwidthPolicy applies the bandwidth policy to all connections
+// every throttle.Interval, whether or not something is being installed,
+// until ctx is done.
+func FollowBandwidthPolicy(ctx context.Context, dbPool *sqlite.Pool) {
+	for {
+		conn := dbPool.Get(ctx.Done())
+		if conn == nil {
+			return
+		}
+		policy := BandwidthPolicy(conn)
+		dbPool.Put(conn)
+		throttle.Global.Apply(policy)
+
+		select {
+		case <-time.After(throttle.Interval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// throttleDownloads makes files opened with downloadOptions follow
+// the cap of download downloadID, until the returned function is called.
+func (oc *OperationContext) throttleDownloads(downloadID string) func() {
+	refresh := func(bc *throttle.Cap) {
+		var rate int64
+		oc.rc.WithConn(func(conn *sqlite.Conn) {
+			if download := models.DownloadByID(conn, downloadID); download != nil {
+				rate = download.BandwidthCap
+			}
+		})
+		bc.SetRate(rate)
+	}
+
+	bc := throttle.NewCap(0)
+	refresh(bc)
+	oc.bandwidthCap = bc
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-time.After(throttle.Interval):
+				refresh(bc)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		oc.bandwidthCap = nil
+		bc.Release()
+	}
+}
+
+// downloadOptions returns the options remote files should be opened with
+func (oc *OperationContext) downloadOptions() []option.Option {
+	opts := []option.Option{option.WithConsumer(oc.Consumer())}
+	if oc.bandwidthCap != nil {
+		opts = append(opts, option.WithHTTPClient(oc.bandwidthCap.HTTPClient()))
+	}
+	return opts
+}
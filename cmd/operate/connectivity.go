@@ -0,0 +1,25 @@
+package operate
+
+import (
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/cmd/operate/connectivity"
+	"github.com/itchio/butler/database/models"
+)
+
+const connectivityProbeSetting = "connectivity_probe"
+
+// ConnectivityProbe returns the probe set with Network.SetConnectivityProbe,
+// or the default one.
+func ConnectivityProbe(conn *sqlite.Conn) *butlerd.ConnectivityProbe {
+	var probe *butlerd.ConnectivityProbe
+	models.GetSetting(conn, connectivityProbeSetting, &probe)
+	if probe == nil {
+		probe = connectivity.Default()
+	}
+	return probe
+}
+
+func SetConnectivityProbe(conn *sqlite.Conn, probe *butlerd.ConnectivityProbe) {
+	models.SetSetting(conn, connectivityProbeSetting, probe)
+}
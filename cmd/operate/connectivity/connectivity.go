@@ -0,0 +1,120 @@
+// Package connectivity finds out whether we're online by fetching
+// known URLs. Captive portals, like hotel wifi login pages, are told
+// apart from the real thing by what they answer.
+package connectivity
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/httpkit/timeout"
+	"github.com/pkg/errors"
+)
+
+// ping answers aren't supposed to be large, login pages might be
+const maxBodySize = 64 * 1024
+
+// Default returns the probe used when none is configured
+func Default() *butlerd.ConnectivityProbe {
+	return &butlerd.ConnectivityProbe{
+		URLs:         []string{"https://itch.io/static/ping.txt"},
+		ExpectedBody: "pong",
+		Timeout:      10,
+		Interval:     1,
+	}
+}
+
+// Seconds converts probe timeouts and intervals to durations
+func Seconds(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// Result is what a probe found out
+type Result struct {
+	Status butlerd.NetworkStatus
+
+	// The URL that answered as expected, if online
+	URL string
+	// What it answered, whitespace excluded
+	Body string
+
+	// The last error encountered, if not online
+	Err error
+}
+
+// Check fetches the probe's URLs in order, until one answers as
+// expected. If none does but one of them answered anyway, we're
+// probably behind a captive portal.
+func Check(ctx context.Context, probe *butlerd.ConnectivityProbe) *Result {
+	client := timeout.NewDefaultClient()
+	client.Timeout = Seconds(probe.Timeout)
+
+	res := &Result{
+		Status: butlerd.NetworkStatusOffline,
+	}
+	for _, u := range probe.URLs {
+		status, body, err := checkOne(ctx, client, probe, u)
+		switch status {
+		case butlerd.NetworkStatusOnline:
+			return &Result{
+				Status: status,
+				URL:    u,
+				Body:   body,
+			}
+		case butlerd.NetworkStatusCaptive:
+			res.Status = status
+		}
+		if err != nil {
+			res.Err = err
+		}
+	}
+	return res
+}
+
+func checkOne(ctx context.Context, client *http.Client, probe *butlerd.ConnectivityProbe, u string) (butlerd.NetworkStatus, string, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return butlerd.NetworkStatusOffline, "", errors.WithStack(err)
+	}
+	req = req.WithContext(ctx)
+
+	res, err := client.Do(req)
+	if err != nil {
+		return butlerd.NetworkStatusOffline, "", errors.WithStack(err)
+	}
+	defer res.Body.Close()
+
+	payload, err := ioutil.ReadAll(io.LimitReader(res.Body, maxBodySize))
+	if err != nil {
+		return butlerd.NetworkStatusOffline, "", errors.WithStack(err)
+	}
+	body := strings.TrimSpace(string(payload))
+
+	if res.StatusCode == http.StatusNetworkAuthenticationRequired {
+		return butlerd.NetworkStatusCaptive, body, errors.Errorf("%s: asks for network authentication", u)
+	}
+
+	redirected := res.Request.URL.Host != req.URL.Host
+	if !redirected && res.StatusCode/100 != 2 {
+		// the URL itself is down or misconfigured, that's not a portal
+		return butlerd.NetworkStatusOffline, body, errors.Errorf("%s: HTTP %d", u, res.StatusCode)
+	}
+
+	if probe.ExpectedBody != "" {
+		if body != strings.TrimSpace(probe.ExpectedBody) {
+			return butlerd.NetworkStatusCaptive, body, errors.Errorf("%s: unexpected answer (HTTP %d)", u, res.StatusCode)
+		}
+		return butlerd.NetworkStatusOnline, body, nil
+	}
+
+	if redirected {
+		// portals usually redirect to their login page
+		return butlerd.NetworkStatusCaptive, body, errors.Errorf("%s: redirected to %s", u, res.Request.URL.Host)
+	}
+	return butlerd.NetworkStatusOnline, body, nil
+}
@@ -0,0 +1,82 @@
+package connectivity_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/cmd/operate/connectivity"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Check(t *testing.T) {
+	portal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "<html>Please log in</html>")
+	}))
+	defer portal.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ping":
+			fmt.Fprintf(w, "pong\n")
+		case "/redirect":
+			http.Redirect(w, r, portal.URL, http.StatusFound)
+		case "/auth":
+			w.WriteHeader(http.StatusNetworkAuthenticationRequired)
+		case "/down":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, "<html>Maintenance</html>")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	check := func(expectedBody string, urls ...string) *connectivity.Result {
+		return connectivity.Check(context.Background(), &butlerd.ConnectivityProbe{
+			URLs:         urls,
+			ExpectedBody: expectedBody,
+			Timeout:      2,
+			Interval:     1,
+		})
+	}
+
+	res := check("pong", server.URL+"/ping")
+	assert.EqualValues(t, butlerd.NetworkStatusOnline, res.Status)
+	assert.EqualValues(t, "pong", res.Body)
+
+	res = check("pong", portal.URL)
+	assert.EqualValues(t, butlerd.NetworkStatusCaptive, res.Status)
+	assert.Error(t, res.Err)
+
+	res = check("", server.URL+"/redirect")
+	assert.EqualValues(t, butlerd.NetworkStatusCaptive, res.Status)
+
+	res = check("", server.URL+"/auth")
+	assert.EqualValues(t, butlerd.NetworkStatusCaptive, res.Status)
+
+	res = check("", closed.URL)
+	assert.EqualValues(t, butlerd.NetworkStatusOffline, res.Status)
+	assert.Error(t, res.Err)
+
+	res = check("", server.URL+"/missing")
+	assert.EqualValues(t, butlerd.NetworkStatusOffline, res.Status)
+
+	// a mirror that's down isn't a portal, even if it answers something else
+	res = check("pong", server.URL+"/down")
+	assert.EqualValues(t, butlerd.NetworkStatusOffline, res.Status)
+	res = check("pong", server.URL+"/missing")
+	assert.EqualValues(t, butlerd.NetworkStatusOffline, res.Status)
+
+	// the first URL that answers as expected wins
+	res = check("pong", closed.URL, portal.URL, server.URL+"/ping")
+	assert.EqualValues(t, butlerd.NetworkStatusOnline, res.Status)
+	assert.EqualValues(t, server.URL+"/ping", res.URL)
+	assert.NoError(t, res.Err)
+}
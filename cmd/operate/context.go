@@ -12,6 +12,7 @@ import (
 
 	"github.com/dchest/safefile"
 	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/cmd/operate/throttle"
 	"github.com/itchio/butler/cmd/wipe"
 	"github.com/itchio/butler/database/models"
 	"github.com/itchio/wharf/state"
@@ -34,6 +35,11 @@ type OperationContext struct {
 	loaded map[string]struct{}
 
 	pidFilePath string
+
+	// set while downloads are throttled, see throttleDownloads
+	bandwidthCap *throttle.Cap
+	// what patching saved us from downloading, see recordTransfer
+	bytesSaved int64
 }
 
 type PidFileContents struct {
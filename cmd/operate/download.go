@@ -12,6 +12,7 @@ import (
 	"github.com/itchio/butler/butlerd"
 	"github.com/itchio/butler/cmd/dl"
 	"github.com/itchio/butler/cmd/operate/downloadextractor"
+	"github.com/itchio/butler/cmd/operate/peercache"
 	"github.com/itchio/butler/comm"
 	"github.com/itchio/butler/installer/archive/intervalsaveconsumer"
 	"github.com/itchio/wharf/eos"
@@ -19,7 +20,7 @@ import (
 	"github.com/pkg/errors"
 )
 
-func DownloadInstallSource(consumer *state.Consumer, stageFolder string, ctx context.Context, file eos.File, destPath string) error {
+func DownloadInstallSource(consumer *state.Consumer, stageFolder string, ctx context.Context, file eos.File, destPath string, peerKey *peercache.Key) error {
 	statePath := filepath.Join(stageFolder, "download-state.dat")
 	sc := intervalsaveconsumer.New(statePath, intervalsaveconsumer.DefaultInterval, consumer, ctx)
 
@@ -28,6 +29,10 @@ func DownloadInstallSource(consumer *state.Consumer, stageFolder string, ctx con
 		consumer.Warnf("Could not load checkpoint: %s", err.Error())
 	}
 
+	if checkpoint == nil && fetchFromPeers(consumer, ctx, file, peerKey, destPath) {
+		return nil
+	}
+
 	destName := filepath.Base(destPath)
 	sink := &savior.FolderSink{
 		Directory: filepath.Dir(destPath),
@@ -76,6 +81,7 @@ func DownloadInstallSource(consumer *state.Consumer, stageFolder string, ctx con
 			return err
 		}
 
+		shareWithPeers(consumer, file, peerKey, destPath)
 		return nil
 	}
 
@@ -26,7 +26,13 @@ func GameToString(game *itchio.Game) string {
 	return fmt.Sprintf("%s - %s", game.Title, game.URL)
 }
 
-func GetFilteredUploads(client *itchio.Client, game *itchio.Game, credentials itchio.GameCredentials, consumer *state.Consumer) (*manager.NarrowDownUploadsResult, error) {
+// IsLocalGame returns true for stand-in games of caves installed from
+// local files without a game, which itch.io knows nothing about.
+func IsLocalGame(gameID int64) bool {
+	return gameID < 0
+}
+
+func GetFilteredUploads(client *itchio.Client, game *itchio.Game, credentials itchio.GameCredentials, consumer *state.Consumer, options manager.NarrowDownUploadsOptions) (*manager.NarrowDownUploadsResult, error) {
 	uploads, err := client.ListGameUploads(itchio.ListGameUploadsParams{
 		GameID:      game.ID,
 		Credentials: credentials,
@@ -39,7 +45,7 @@ func GetFilteredUploads(client *itchio.Client, game *itchio.Game, credentials it
 	if numInputs == 0 {
 		consumer.Infof("No uploads found at all (that we can access)")
 	}
-	uploadsFilterResult := manager.NarrowDownUploads(consumer, game, uploads.Uploads, ox.CurrentRuntime())
+	uploadsFilterResult := manager.NarrowDownUploadsWithOptions(consumer, game, uploads.Uploads, ox.CurrentRuntime(), options)
 
 	numResults := len(uploadsFilterResult.Uploads)
 
@@ -250,6 +256,20 @@ func ValidateCave(rc *butlerd.RequestContext, caveID string) *models.Cave {
 	return cave
 }
 
+// FindPatchFile returns the patch file to upgrade to a build with,
+// preferring optimized patches. Returns nil if the build has no patch.
+func FindPatchFile(build *itchio.Build) *itchio.BuildFile {
+	f := FindBuildFile(build.Files, itchio.BuildFileTypePatch, itchio.BuildFileSubTypeDefault)
+	if f == nil {
+		return nil
+	}
+
+	if of := FindBuildFile(build.Files, itchio.BuildFileTypePatch, itchio.BuildFileSubTypeOptimized); of != nil {
+		return of
+	}
+	return f
+}
+
 func FindBuildFile(files []*itchio.BuildFile, fileType itchio.BuildFileType, subType itchio.BuildFileSubType) *itchio.BuildFile {
 	for _, f := range files {
 		if f.Type == fileType && f.SubType == subType {
@@ -258,3 +278,11 @@ func FindBuildFile(files []*itchio.BuildFile, fileType itchio.BuildFileType, sub
 	}
 	return nil
 }
+
+// CaveUpdatePolicy returns what to do when an update is found for cave
+func CaveUpdatePolicy(cave *models.Cave) butlerd.UpdatePolicy {
+	if cave.UpdatePolicy == "" {
+		return butlerd.UpdatePolicyNotify
+	}
+	return butlerd.UpdatePolicy(cave.UpdatePolicy)
+}
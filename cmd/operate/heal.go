@@ -2,6 +2,7 @@ package operate
 
 import (
 	"fmt"
+	"path/filepath"
 	"time"
 
 	"github.com/itchio/butler/butlerd"
@@ -11,7 +12,6 @@ import (
 	"github.com/itchio/httpkit/progress"
 	"github.com/itchio/savior/seeksource"
 	"github.com/itchio/wharf/eos"
-	"github.com/itchio/wharf/eos/option"
 	"github.com/itchio/wharf/tlc"
 
 	"github.com/itchio/wharf/pwr"
@@ -40,15 +40,7 @@ func heal(oc *OperationContext, meta *MetaSubcontext, isub *InstallSubcontext, r
 	signatureURL := MakeSourceURL(client, consumer, istate.DownloadSessionID, params, "signature")
 	archiveURL := MakeSourceURL(client, consumer, istate.DownloadSessionID, params, "archive")
 
-	healSpec := fmt.Sprintf("archive,%s", archiveURL)
-
-	vc := &pwr.ValidatorContext{
-		Consumer:   consumer,
-		NumWorkers: 1,
-		HealPath:   healSpec,
-	}
-
-	signatureFile, err := eos.Open(signatureURL, option.WithConsumer(consumer))
+	signatureFile, err := eos.Open(signatureURL, oc.downloadOptions()...)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -82,15 +74,46 @@ func heal(oc *OperationContext, meta *MetaSubcontext, isub *InstallSubcontext, r
 		progress.FormatBytes(sigInfo.Container.Size),
 	)
 
-	consumer.Infof("Healing container...")
+	consumer.Infof("Checking container...")
 
 	timeBeforeHeal := time.Now()
 
+	// the healer opens the archive by itself, which would bypass
+	// downloadOptions, so only download it if there's something to heal,
+	// and heal from the local copy.
+	vc := &pwr.ValidatorContext{
+		Consumer:   consumer,
+		NumWorkers: 1,
+		FailFast:   true,
+	}
+
 	oc.rc.StartProgress()
 	err = vc.Validate(oc.ctx, params.InstallFolder, sigInfo)
 	oc.rc.EndProgress()
 	if err != nil {
-		return errors.WithStack(err)
+		if _, ok := errors.Cause(err).(*pwr.ErrHasWound); !ok {
+			return errors.WithStack(err)
+		}
+
+		consumer.Infof("Found corrupted data: %s", err.Error())
+		archivePath, err := downloadHealArchive(oc, params, archiveURL)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		consumer.Infof("Healing container...")
+		vc = &pwr.ValidatorContext{
+			Consumer:   consumer,
+			NumWorkers: 1,
+			HealPath:   fmt.Sprintf("archive,%s", archivePath),
+		}
+
+		oc.rc.StartProgress()
+		err = vc.Validate(oc.ctx, params.InstallFolder, sigInfo)
+		oc.rc.EndProgress()
+		if err != nil {
+			return errors.WithStack(err)
+		}
 	}
 
 	healDuration := time.Since(timeBeforeHeal)
@@ -167,3 +190,33 @@ func resultForContainer(c *tlc.Container) *installer.InstallResult {
 
 	return res
 }
+
+// downloadHealArchive downloads the archive of the build being healed
+// to the stage folder, and returns its path.
+func downloadHealArchive(oc *OperationContext, params *InstallParams, archiveURL string) (string, error) {
+	consumer := oc.Consumer()
+
+	file, err := eos.Open(archiveURL, oc.downloadOptions()...)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer file.Close()
+
+	stats, err := file.Stat()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	folder := filepath.Join(oc.StageFolder(), "heal-source")
+	destPath := filepath.Join(folder, filepath.Base(stats.Name()))
+
+	consumer.Infof("Downloading %s archive to heal from...", progress.FormatBytes(stats.Size()))
+	oc.rc.StartProgress()
+	err = DownloadInstallSource(consumer, folder, oc.ctx, file, destPath, PeerKey(params.Upload, params.Build))
+	oc.rc.EndProgress()
+	consumer.Progress(0)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return destPath, nil
+}
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"crawshaw.io/sqlite"
 	"github.com/itchio/butler/butlerd"
 	"github.com/itchio/butler/butlerd/messages"
 	"github.com/itchio/butler/database/models"
+	"github.com/itchio/butler/endpoints/saves/snapshots"
 	"github.com/itchio/wharf/eos"
 	"github.com/itchio/wharf/eos/option"
 
@@ -28,11 +30,14 @@ func InstallPerform(ctx context.Context, rc *butlerd.RequestContext, performPara
 		return errors.WithStack(err)
 	}
 	defer oc.Release()
+	defer oc.throttleDownloads(performParams.ID)()
 
 	meta := NewMetaSubcontext()
 	oc.Load(meta)
 
+	startedAt := time.Now().UTC()
 	err = doInstallPerform(oc, meta)
+	oc.recordTransfer(performParams.ID, meta.Data, startedAt, err)
 	if err != nil {
 		oc.Consumer().Errorf("%+v", err)
 		return errors.WithStack(err)
@@ -75,7 +80,7 @@ func doForceLocal(file eos.File, oc *OperationContext, meta *MetaSubcontext, isu
 			}
 
 			oc.rc.StartProgress()
-			err = DownloadInstallSource(oc.Consumer(), oc.StageFolder(), oc.ctx, file, destPath)
+			err = DownloadInstallSource(oc.Consumer(), oc.StageFolder(), oc.ctx, file, destPath, PeerKey(params.Upload, params.Build))
 			oc.rc.EndProgress()
 			oc.consumer.Progress(0)
 			if err != nil {
@@ -131,9 +136,19 @@ func doInstallPerform(oc *OperationContext, meta *MetaSubcontext) error {
 	return InstallPrepare(oc, meta, isub, true, func(prepareRes *InstallPrepareResult) error {
 		if !params.NoCave {
 			var cave *models.Cave
+			var installFolder, backupFolder string
 			rc.WithConn(func(conn *sqlite.Conn) {
 				cave = models.CaveByID(conn, params.CaveID)
+				if cave != nil {
+					installFolder = cave.GetInstallFolder(conn)
+					backupFolder = cave.GetSaveBackupFolder(conn)
+				}
 			})
+			if cave != nil && params.Reason == butlerd.DownloadReasonVersionSwitch {
+				// back up saves right before the other version replaces this one
+				consumer.Infof("Backing up saves...")
+				snapshots.Auto(consumer, installFolder, backupFolder, butlerd.SaveSnapshotReasonVersionSwitch)
+			}
 			if cave == nil {
 				cave = &models.Cave{
 					ID:                params.CaveID,
@@ -176,6 +191,7 @@ func doInstallPerform(oc *OperationContext, meta *MetaSubcontext) error {
 			InstallFolderPath: params.InstallFolder,
 
 			ReceiptIn: prepareRes.ReceiptIn,
+			PeerKey:   PeerKey(params.Upload, params.Build),
 
 			Context: oc.ctx,
 		}
@@ -218,6 +234,15 @@ func doInstallPerform(oc *OperationContext, meta *MetaSubcontext) error {
 		if firstInstallResult != nil {
 			consumer.Infof("First install already completed (%d files)", len(firstInstallResult.Files))
 		} else {
+			if params.LocalFile == "" && sharingWithPeers() {
+				// streamed installs can't be shared with LAN peers
+				lf, err := doForceLocal(prepareRes.File, oc, meta, isub)
+				if err != nil {
+					return errors.WithStack(err)
+				}
+				managerInstallParams.File = lf
+			}
+
 			var err error
 			firstInstallResult, err = tryInstall()
 			if err != nil && errors.Cause(err) == installer.ErrNeedLocal {
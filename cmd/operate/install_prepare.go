@@ -53,7 +53,9 @@ func InstallPrepare(oc *OperationContext, meta *MetaSubcontext, isub *InstallSub
 
 	istate := isub.Data
 
-	if istate.DownloadSessionID == "" {
+	if params.LocalFile != "" {
+		consumer.Infof("→ Installing from a local file, no download session needed")
+	} else if istate.DownloadSessionID == "" {
 		res, err := client.NewDownloadSession(itchio.NewDownloadSessionParams{
 			GameID:      params.Game.ID,
 			Credentials: params.Access.Credentials,
@@ -82,7 +84,7 @@ func InstallPrepare(oc *OperationContext, meta *MetaSubcontext, isub *InstallSub
 	consumer.Infof("→ To be installed:")
 	LogUpload(consumer, params.Upload, params.Build)
 
-	if receiptIn != nil && receiptIn.Upload != nil && receiptIn.Upload.ID == params.Upload.ID {
+	if params.LocalFile == "" && receiptIn != nil && receiptIn.Upload != nil && receiptIn.Upload.ID == params.Upload.ID {
 		consumer.Infof("Installing over same upload")
 		if receiptIn.Build != nil && params.Build != nil {
 			oldID := receiptIn.Build.ID
@@ -109,20 +111,13 @@ func InstallPrepare(oc *OperationContext, meta *MetaSubcontext, isub *InstallSub
 				consumer.Infof("Found upgrade path with %d items: ", len(upgradePath.Builds))
 
 				for _, b := range upgradePath.Builds {
-					f := FindBuildFile(b.Files, itchio.BuildFileTypePatch, itchio.BuildFileSubTypeDefault)
+					f := FindPatchFile(b)
 					if f == nil {
 						consumer.Warnf("Whoops, build %d is missing a patch, falling back to heal...", b.ID)
 						res.Strategy = InstallPerformStrategyHeal
 						return task(res)
 					}
 
-					{
-						of := FindBuildFile(b.Files, itchio.BuildFileTypePatch, itchio.BuildFileSubTypeOptimized)
-						if of != nil {
-							f = of
-						}
-					}
-
 					consumer.Infof(" - Build %d (%s)", b.ID, progress.FormatBytes(f.Size))
 					totalUpgradeSize += f.Size
 				}
@@ -146,10 +141,13 @@ func InstallPrepare(oc *OperationContext, meta *MetaSubcontext, isub *InstallSub
 
 				consumer.Infof("Will apply %d patches", len(upgradePath.Builds))
 				res.Strategy = InstallPerformStrategyUpgrade
+				oc.bytesSaved = fullUploadSize - totalUpgradeSize
 
 				if istate.UpgradePath == nil {
 					istate.UpgradePath = upgradePath
 					istate.UpgradePathIndex = 0
+					// patches are downloaded, then rewrite the files they touch
+					istate.NeededFreeSpace = totalUpgradeSize + GuessInstallSize(totalUpgradeSize)
 					err = oc.Save(isub)
 					if err != nil {
 						return err
@@ -171,16 +169,21 @@ func InstallPrepare(oc *OperationContext, meta *MetaSubcontext, isub *InstallSub
 		}
 	}
 
-	installSourceURL := MakeSourceURL(client, consumer, istate.DownloadSessionID, params, "")
-
-	file, err := eos.Open(installSourceURL, option.WithConsumer(consumer))
+	var file eos.File
+	if params.LocalFile != "" {
+		consumer.Infof("Installing from local file (%s)", params.LocalFile)
+		file, err = eos.Open(params.LocalFile, option.WithConsumer(consumer))
+	} else {
+		installSourceURL := MakeSourceURL(client, consumer, istate.DownloadSessionID, params, "")
+		file, err = eos.Open(installSourceURL, oc.downloadOptions()...)
+	}
 	if err != nil {
 		return errors.WithStack(err)
 	}
 	res.File = file
 	defer file.Close()
 
-	if params.Upload.Storage == itchio.UploadStorageExternal {
+	if params.LocalFile == "" && params.Upload.Storage == itchio.UploadStorageExternal {
 		consumer.Warnf("Dealing with an external upload (from %s), all bets are off.", params.Upload.Host)
 
 		if IsBadExternalHost(params.Upload.Host) {
@@ -240,6 +243,7 @@ func InstallPrepare(oc *OperationContext, meta *MetaSubcontext, isub *InstallSub
 		consumer.Infof("  ✓ %s final disk usage", progress.FormatBytes(dui.FinalDiskUsage))
 
 		istate.InstallerInfo = installerInfo
+		istate.NeededFreeSpace = dui.NeededFreeSpace
 		err = oc.Save(isub)
 		if err != nil {
 			return err
@@ -13,6 +13,7 @@ type InstallSubcontextState struct {
 	SecondInstallerInfo *installer.InstallerInfo `json:"secondInstallerInfo,omitempty"`
 	UpgradePath         *itchio.UpgradePath      `json:"upgradePath,omitempty"`
 	UpgradePathIndex    int                      `json:"upgradePathIndex,omitempty"`
+	NeededFreeSpace     int64                    `json:"neededFreeSpace,omitempty"`
 }
 
 type InstallSubcontext struct {
@@ -1,10 +1,15 @@
 package memorylogger
 
-import "github.com/itchio/wharf/state"
+import (
+	"sync"
+
+	"github.com/itchio/wharf/state"
+)
 
 // MemoryLogger
 
 type MemoryLogger struct {
+	lock  sync.Mutex
 	items []*MemoryLogItem
 }
 
@@ -20,12 +25,16 @@ func New() *MemoryLogger {
 func (ml *MemoryLogger) Consumer() *state.Consumer {
 	return &state.Consumer{
 		OnMessage: func(level string, message string) {
+			ml.lock.Lock()
+			defer ml.lock.Unlock()
 			ml.items = append(ml.items, &MemoryLogItem{level, message})
 		},
 	}
 }
 
 func (ml *MemoryLogger) Copy(dst *state.Consumer) {
+	ml.lock.Lock()
+	defer ml.lock.Unlock()
 	for _, item := range ml.items {
 		dst.OnMessage(item.level, item.message)
 	}
@@ -24,6 +24,9 @@ type InstallParams struct {
 
 	IgnoreInstallers bool `json:"ignoreInstallers,omitempty"`
 
+	// If set, installed from this file instead of downloading
+	LocalFile string `json:"localFile,omitempty"`
+
 	Access *GameAccess `json:"credentials"`
 }
 
@@ -0,0 +1,185 @@
+package operate
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/cmd/operate/peercache"
+	"github.com/itchio/butler/comm"
+	"github.com/itchio/butler/database/models"
+	itchio "github.com/itchio/go-itchio"
+	"github.com/itchio/httpkit/htfs"
+	"github.com/itchio/savior"
+	"github.com/itchio/savior/filesource"
+	"github.com/itchio/wharf/eos"
+	"github.com/itchio/wharf/eos/option"
+	"github.com/itchio/wharf/state"
+	"github.com/pkg/errors"
+)
+
+const peerCacheSetting = "peer_cache"
+
+// PeerCacheSettings returns the settings set with Network.SetPeerCache, if any
+func PeerCacheSettings(conn *sqlite.Conn) *butlerd.PeerCacheSettings {
+	var settings *butlerd.PeerCacheSettings
+	models.GetSetting(conn, peerCacheSetting, &settings)
+	return settings
+}
+
+func SetPeerCacheSettings(conn *sqlite.Conn, settings *butlerd.PeerCacheSettings) {
+	models.SetSetting(conn, peerCacheSetting, settings)
+}
+
+// ConfigurePeerCache starts or stops sharing downloads with
+// LAN peers, depending on settings.
+func ConfigurePeerCache(settings *butlerd.PeerCacheSettings) error {
+	if settings == nil || !settings.Enabled {
+		return peercache.Configure(nil, nil)
+	}
+
+	return peercache.Configure(comm.NewStateConsumer(), &peercache.Config{
+		Folder:            settings.Folder,
+		Keep:              int(settings.Keep),
+		Address:           settings.Address,
+		DiscoveryAddress:  settings.DiscoveryAddress,
+		AnnounceAddresses: settings.AnnounceAddresses,
+		Peers:             settings.Peers,
+	})
+}
+
+// PeerKey returns what the install source of upload
+// and build is shared as with LAN peers.
+func PeerKey(upload *itchio.Upload, build *itchio.Build) *peercache.Key {
+	if upload == nil {
+		return nil
+	}
+
+	key := &peercache.Key{
+		UploadID: upload.ID,
+		Kind:     "upload",
+	}
+	if build != nil {
+		key.BuildID = build.ID
+		key.Kind = string(itchio.BuildFileTypeArchive)
+	}
+	return key
+}
+
+// PatchPeerKey returns what the patch of subType that upgrades to build
+// is shared as with LAN peers.
+func PatchPeerKey(upload *itchio.Upload, build *itchio.Build, subType itchio.BuildFileSubType) *peercache.Key {
+	if upload == nil || build == nil {
+		return nil
+	}
+
+	kind := string(itchio.BuildFileTypePatch)
+	if subType != itchio.BuildFileSubTypeDefault {
+		kind += "-" + string(subType)
+	}
+	return &peercache.Key{
+		UploadID: upload.ID,
+		BuildID:  build.ID,
+		Kind:     kind,
+	}
+}
+
+// sharingWithPeers returns true if downloads are shared with LAN peers.
+// Streamed files can't be shared, so sources are downloaded in
+// full before being used in that case.
+func sharingWithPeers() bool {
+	return peercache.Current() != nil
+}
+
+// openPatch opens the patch at patchURL. When sharing with LAN peers,
+// it's downloaded to the staging folder first, from a peer if one
+// has it, so that it can be shared in turn.
+func (oc *OperationContext) openPatch(patchURL string, key *peercache.Key) (savior.FileSource, error) {
+	if key == nil || !sharingWithPeers() {
+		return filesource.Open(patchURL, oc.downloadOptions()...)
+	}
+
+	consumer := oc.Consumer()
+	folder := filepath.Join(oc.StageFolder(), "patches", key.String())
+	donePath := filepath.Join(folder, "done")
+
+	var destPath string
+	if name, err := ioutil.ReadFile(donePath); err == nil {
+		destPath = filepath.Join(folder, string(name))
+		consumer.Infof("Re-using previously-downloaded patch")
+	} else {
+		file, err := eos.Open(patchURL, oc.downloadOptions()...)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		defer file.Close()
+
+		stats, err := file.Stat()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		destPath = filepath.Join(folder, filepath.Base(stats.Name()))
+
+		consumer.Infof("Downloading patch before applying it, to share it with LAN peers")
+		oc.rc.StartProgress()
+		err = DownloadInstallSource(consumer, folder, oc.ctx, file, destPath, key)
+		oc.rc.EndProgress()
+		consumer.Progress(0)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		err = ioutil.WriteFile(donePath, []byte(filepath.Base(destPath)), 0644)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	return filesource.Open(destPath, option.WithConsumer(consumer))
+}
+
+// fetchFromPeers tries to download file from LAN peers, and
+// returns true if destPath now has the verified contents of file.
+func fetchFromPeers(consumer *state.Consumer, ctx context.Context, file eos.File, key *peercache.Key, destPath string) bool {
+	node := peercache.Current()
+	if node == nil || key == nil {
+		return false
+	}
+
+	hf, ok := file.(*htfs.File)
+	if !ok || hf.GetHeader() == nil {
+		return false
+	}
+
+	stats, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	err = node.Fetch(ctx, consumer, *key, hf.GetHeader(), stats.Size(), destPath)
+	if err != nil {
+		consumer.Infof("Downloading from CDN (%v)", err)
+		return false
+	}
+	return true
+}
+
+// shareWithPeers lets LAN peers download file from us
+func shareWithPeers(consumer *state.Consumer, file eos.File, key *peercache.Key, destPath string) {
+	node := peercache.Current()
+	if node == nil || key == nil {
+		return
+	}
+
+	hf, ok := file.(*htfs.File)
+	if !ok || hf.GetHeader() == nil {
+		return
+	}
+
+	err := node.Add(consumer, *key, hf.GetHeader(), destPath)
+	if err != nil {
+		consumer.Warnf("Could not share with LAN peers: %+v", err)
+	}
+}
@@ -0,0 +1,600 @@
+// Package peercache shares downloaded install sources with other
+// butlerd instances on the local network.
+//
+// A node keeps completed downloads in a folder, serves them over HTTP,
+// and announces itself with UDP beacons. Files fetched from peers are
+// verified against the hashes the CDN gave for the same file, so peers
+// don't need to be trusted.
+package peercache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itchio/butler/cmd/dl"
+	"github.com/itchio/httpkit/timeout"
+	"github.com/itchio/wharf/state"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultAddress          = ":42771"
+	DefaultDiscoveryAddress = ":42770"
+	DefaultKeep             = 5
+	DefaultAnnounceInterval = 10 * time.Second
+)
+
+// DefaultAnnounceAddresses broadcasts beacons on the local network
+var DefaultAnnounceAddresses = []string{"255.255.255.255:42770"}
+
+const entryName = "entry.json"
+
+var hashHeader = http.CanonicalHeaderKey("x-goog-hash")
+
+// Key identifies a file that can be shared with peers
+type Key struct {
+	UploadID int64 `json:"uploadId"`
+	BuildID  int64 `json:"buildId"`
+	// What the file is for that upload and build, e.g. "archive"
+	Kind string `json:"kind"`
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("upload-%d-build-%d-%s", k.UploadID, k.BuildID, k.Kind)
+}
+
+// An Entry is a file a node shares
+type Entry struct {
+	Key       Key       `json:"key"`
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	Hashes    []string  `json:"hashes"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type Config struct {
+	// Where shared files are kept
+	Folder string
+	// How many files are kept, oldest are removed first
+	Keep int
+
+	// Address the HTTP server listens on
+	Address string
+	// Address beacons from other nodes are received on
+	DiscoveryAddress string
+	// Addresses beacons are sent to
+	AnnounceAddresses []string
+	AnnounceInterval  time.Duration
+
+	// Base URLs of peers to try even if they don't announce themselves
+	Peers []string
+}
+
+type beacon struct {
+	ID   string `json:"id"`
+	Port int    `json:"port"`
+}
+
+// A Node shares files with peers and fetches files from them
+type Node struct {
+	config   Config
+	consumer *state.Consumer
+	id       string
+	client   *http.Client
+
+	listener net.Listener
+	server   *http.Server
+	udp      *net.UDPConn
+	done     chan struct{}
+
+	lock sync.Mutex
+	seen map[string]time.Time
+	// serializes changes to the folder
+	folderLock sync.Mutex
+}
+
+// Start runs a node until it's closed
+func Start(consumer *state.Consumer, config Config) (*Node, error) {
+	if config.Folder == "" {
+		return nil, errors.New("peer cache folder must be set")
+	}
+	if config.Keep <= 0 {
+		config.Keep = DefaultKeep
+	}
+	if config.Address == "" {
+		config.Address = DefaultAddress
+	}
+	if config.DiscoveryAddress == "" {
+		config.DiscoveryAddress = DefaultDiscoveryAddress
+	}
+	if config.AnnounceAddresses == nil {
+		config.AnnounceAddresses = DefaultAnnounceAddresses
+	}
+	if config.AnnounceInterval <= 0 {
+		config.AnnounceInterval = DefaultAnnounceInterval
+	}
+
+	err := os.MkdirAll(config.Folder, 0755)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	n := &Node{
+		config:   config,
+		consumer: consumer,
+		id:       uuid.New().String(),
+		client:   timeout.NewDefaultClient(),
+		done:     make(chan struct{}),
+		seen:     make(map[string]time.Time),
+	}
+
+	n.listener, err = net.Listen("tcp", config.Address)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	udpAddr, err := net.ResolveUDPAddr("udp4", config.DiscoveryAddress)
+	if err == nil {
+		n.udp, err = net.ListenUDP("udp4", udpAddr)
+	}
+	if err != nil {
+		n.listener.Close()
+		return nil, errors.WithStack(err)
+	}
+
+	n.server = &http.Server{Handler: n}
+	go n.server.Serve(n.listener)
+	go n.receiveBeacons()
+	go n.sendBeacons()
+
+	consumer.Infof("Sharing downloads with LAN peers on %s (discovery on %s)", n.listener.Addr(), n.udp.LocalAddr())
+	return n, nil
+}
+
+// Close stops serving files and announcing the node
+func (n *Node) Close() error {
+	close(n.done)
+	n.udp.Close()
+	return n.server.Close()
+}
+
+// Port returns the port files are served on
+func (n *Node) Port() int {
+	return n.listener.Addr().(*net.TCPAddr).Port
+}
+
+// DiscoveryAddress returns where beacons from other nodes are received
+func (n *Node) DiscoveryAddress() string {
+	return n.udp.LocalAddr().String()
+}
+
+// Peers returns the base URLs of known peers
+func (n *Node) Peers() []string {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	res := append([]string{}, n.config.Peers...)
+	var discovered []string
+	for url, seenAt := range n.seen {
+		if time.Since(seenAt) > 3*n.config.AnnounceInterval {
+			delete(n.seen, url)
+			continue
+		}
+		discovered = append(discovered, url)
+	}
+	sort.Strings(discovered)
+	return append(res, discovered...)
+}
+
+func (n *Node) sendBeacons() {
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		n.consumer.Warnf("Can't announce to LAN peers: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	payload, err := json.Marshal(&beacon{ID: n.id, Port: n.Port()})
+	if err != nil {
+		n.consumer.Warnf("Can't announce to LAN peers: %v", err)
+		return
+	}
+
+	for {
+		for _, address := range n.config.AnnounceAddresses {
+			addr, err := net.ResolveUDPAddr("udp4", address)
+			if err == nil {
+				_, err = conn.WriteTo(payload, addr)
+			}
+			if err != nil {
+				n.consumer.Debugf("Could not announce to (%s): %v", address, err)
+			}
+		}
+
+		select {
+		case <-time.After(n.config.AnnounceInterval):
+		case <-n.done:
+			return
+		}
+	}
+}
+
+func (n *Node) receiveBeacons() {
+	buf := make([]byte, 1024)
+	for {
+		size, addr, err := n.udp.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-n.done:
+				return
+			default:
+				n.consumer.Warnf("Stopped receiving LAN peer beacons: %v", err)
+				return
+			}
+		}
+
+		var b beacon
+		err = json.Unmarshal(buf[:size], &b)
+		if err != nil || b.ID == "" || b.ID == n.id || b.Port <= 0 {
+			continue
+		}
+
+		url := "http://" + net.JoinHostPort(addr.IP.String(), strconv.Itoa(b.Port))
+		n.lock.Lock()
+		if _, ok := n.seen[url]; !ok {
+			n.consumer.Infof("Found LAN peer (%s)", url)
+		}
+		n.seen[url] = time.Now()
+		n.lock.Unlock()
+	}
+}
+
+// Entries returns all shared files, most recent first
+func (n *Node) Entries() ([]*Entry, error) {
+	dirs, err := ioutil.ReadDir(n.config.Folder)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var res []*Entry
+	for _, dir := range dirs {
+		entry, err := n.readEntry(dir.Name())
+		if err != nil {
+			// unfinished or foreign
+			continue
+		}
+		res = append(res, entry)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreatedAt.After(res[j].CreatedAt)
+	})
+	return res, nil
+}
+
+func (n *Node) readEntry(name string) (*Entry, error) {
+	payload, err := ioutil.ReadFile(filepath.Join(n.config.Folder, name, entryName))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	entry := &Entry{}
+	err = json.Unmarshal(payload, entry)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if entry.Key.String() != name {
+		return nil, errors.Errorf("entry (%s) has mismatched key %s", name, entry.Key)
+	}
+	return entry, nil
+}
+
+func (n *Node) entryPath(entry *Entry) string {
+	return filepath.Join(n.config.Folder, entry.Key.String(), entry.Name)
+}
+
+// Add shares the file at path, which was downloaded from a server
+// that sent header. Files without hashes aren't shared, since
+// peers couldn't verify them.
+func (n *Node) Add(consumer *state.Consumer, key Key, header http.Header, path string) error {
+	hashes := header[hashHeader]
+	if len(hashes) == 0 {
+		consumer.Debugf("No hashes for %s, not sharing with LAN peers", key)
+		return nil
+	}
+
+	stats, err := os.Stat(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	n.folderLock.Lock()
+	defer n.folderLock.Unlock()
+
+	entry := &Entry{
+		Key:       key,
+		Name:      filepath.Base(path),
+		Size:      stats.Size(),
+		Hashes:    hashes,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	dir := filepath.Join(n.config.Folder, key.String())
+	tmpDir := dir + ".tmp"
+	for _, d := range []string{dir, tmpDir} {
+		err = os.RemoveAll(d)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	err = os.MkdirAll(tmpDir, 0755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	dst := filepath.Join(tmpDir, entry.Name)
+	err = os.Link(path, dst)
+	if err != nil {
+		err = copyFile(path, dst)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(tmpDir, entryName), payload, 0644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = os.Rename(tmpDir, dir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	consumer.Infof("Sharing %s with LAN peers", key)
+
+	return n.prune()
+}
+
+func (n *Node) prune() error {
+	entries, err := n.Entries()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for i, entry := range entries {
+		if i < n.config.Keep {
+			continue
+		}
+		err = os.RemoveAll(filepath.Join(n.config.Folder, entry.Key.String()))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// ServeHTTP advertises shared files on /peercache/entries
+// and serves them on /peercache/files/<key>
+func (n *Node) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	const filesPrefix = "/peercache/files/"
+	switch {
+	case r.URL.Path == "/peercache/entries":
+		entries, err := n.Entries()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"entries": entries,
+		})
+	case strings.HasPrefix(r.URL.Path, filesPrefix):
+		name := strings.TrimPrefix(r.URL.Path, filesPrefix)
+		if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		entry, err := n.readEntry(name)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		f, err := os.Open(n.entryPath(entry))
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		defer f.Close()
+
+		w.Header()[hashHeader] = entry.Hashes
+		http.ServeContent(w, r, entry.Name, entry.CreatedAt, f)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// Fetch downloads the file for key from the first peer that has it
+// to destPath. header is what the CDN sent for the file, whose hashes
+// the peer's file must match.
+func (n *Node) Fetch(ctx context.Context, consumer *state.Consumer, key Key, header http.Header, size int64, destPath string) error {
+	hashes := header[hashHeader]
+	if len(hashes) == 0 {
+		return errors.New("no hashes to verify peer files with")
+	}
+
+	peers := n.Peers()
+	if len(peers) == 0 {
+		return errors.New("no LAN peers known")
+	}
+
+	for _, peer := range peers {
+		err := n.fetchFrom(ctx, consumer, peer, key, header, size, destPath)
+		if err == nil {
+			consumer.Infof("Got %s from LAN peer (%s)", key, peer)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return errors.WithStack(ctx.Err())
+		}
+		consumer.Infof("LAN peer (%s) can't provide %s: %v", peer, key, err)
+	}
+	return errors.Errorf("none of %d LAN peers could provide %s", len(peers), key)
+}
+
+func (n *Node) fetchFrom(ctx context.Context, consumer *state.Consumer, peer string, key Key, header http.Header, size int64, destPath string) error {
+	req, err := http.NewRequest("GET", peer+"/peercache/files/"+key.String(), nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	req = req.WithContext(ctx)
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return errors.Errorf("HTTP %d", res.StatusCode)
+	}
+	if !sameHashes(res.Header[hashHeader], header[hashHeader]) {
+		return errors.Errorf("advertised hashes don't match")
+	}
+
+	tmpPath := destPath + ".peer"
+	defer os.Remove(tmpPath)
+
+	err = os.MkdirAll(filepath.Dir(tmpPath), 0755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = io.Copy(f, &progressReader{r: res.Body, consumer: consumer, size: size})
+	if err != nil {
+		f.Close()
+		return errors.WithStack(err)
+	}
+	err = f.Close()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = dl.CheckIntegrity(consumer, header, size, tmpPath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(os.Rename(tmpPath, destPath))
+}
+
+// progressReader reports how much of a file fetched from a peer
+// was read so far
+type progressReader struct {
+	r        io.Reader
+	consumer *state.Consumer
+	size     int64
+	read     int64
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	pr.read += int64(n)
+	if pr.size > 0 {
+		pr.consumer.Progress(float64(pr.read) / float64(pr.size))
+	}
+	return n, err
+}
+
+func sameHashes(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string{}, a...)
+	b = append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func copyFile(src string, dst string) error {
+	r, err := os.Open(src)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer r.Close()
+
+	w, err := os.Create(dst)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = io.Copy(w, r)
+	if err != nil {
+		w.Close()
+		return errors.WithStack(err)
+	}
+	return w.Close()
+}
+
+var current *Node
+var currentLock sync.Mutex
+
+// Configure replaces the running node, if any, with a node
+// started with config. A nil config only stops the running node.
+func Configure(consumer *state.Consumer, config *Config) error {
+	currentLock.Lock()
+	defer currentLock.Unlock()
+
+	if current != nil {
+		current.Close()
+		current = nil
+	}
+	if config == nil {
+		return nil
+	}
+
+	node, err := Start(consumer, *config)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	current = node
+	return nil
+}
+
+// Current returns the running node, or nil
+func Current() *Node {
+	currentLock.Lock()
+	defer currentLock.Unlock()
+	return current
+}
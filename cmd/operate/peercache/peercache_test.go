@@ -0,0 +1,119 @@
+package peercache_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/itchio/butler/cmd/dl"
+	"github.com/itchio/butler/cmd/operate/memorylogger"
+	"github.com/itchio/butler/cmd/operate/peercache"
+	"github.com/itchio/wharf/crc32c"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PeerCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "peercache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	consumer := memorylogger.New().Consumer()
+
+	start := func(name string, config peercache.Config) *peercache.Node {
+		config.Folder = filepath.Join(dir, name)
+		config.Address = "127.0.0.1:0"
+		config.DiscoveryAddress = "127.0.0.1:0"
+		if config.AnnounceAddresses == nil {
+			config.AnnounceAddresses = []string{}
+		}
+		config.AnnounceInterval = 50 * time.Millisecond
+		n, err := peercache.Start(consumer, config)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return n
+	}
+
+	write := func(name string, contents []byte) string {
+		p := filepath.Join(dir, name)
+		assert.NoError(t, ioutil.WriteFile(p, contents, 0644))
+		return p
+	}
+
+	contents := []byte("a nightly build, as an archive")
+	hasher := crc32.New(crc32c.Table)
+	hasher.Write(contents)
+	header := http.Header{}
+	header.Set("x-goog-hash", "crc32c="+base64.StdEncoding.EncodeToString(hasher.Sum(nil)))
+	key := peercache.Key{UploadID: 12, BuildID: 34, Kind: "archive"}
+
+	// b learns about a from its beacons
+	b := start("b", peercache.Config{})
+	defer b.Close()
+	a := start("a", peercache.Config{
+		AnnounceAddresses: []string{b.DiscoveryAddress()},
+	})
+	defer a.Close()
+
+	assert.NoError(t, a.Add(consumer, key, header, write("game.zip", contents)))
+
+	for i := 0; i < 100 && len(b.Peers()) == 0; i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+	assert.EqualValues(t, []string{fmt.Sprintf("http://127.0.0.1:%d", a.Port())}, b.Peers())
+
+	dest := filepath.Join(dir, "b-dest", "game.zip")
+	var lastProgress float64
+	progressConsumer := memorylogger.New().Consumer()
+	progressConsumer.OnProgress = func(alpha float64) {
+		lastProgress = alpha
+	}
+	err = b.Fetch(context.Background(), progressConsumer, key, header, int64(len(contents)), dest)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1.0, lastProgress)
+	fetched, err := ioutil.ReadFile(dest)
+	assert.NoError(t, err)
+	assert.EqualValues(t, contents, fetched)
+
+	err = b.Fetch(context.Background(), consumer, peercache.Key{UploadID: 12, Kind: "upload"}, header, int64(len(contents)), dest+".other")
+	assert.Error(t, err)
+
+	// c advertises the right hashes, but serves something else
+	c := start("c", peercache.Config{})
+	defer c.Close()
+	assert.NoError(t, c.Add(consumer, key, header, write("bad.zip", []byte("not the nightly build, at all"))))
+
+	d := start("d", peercache.Config{
+		Peers: []string{fmt.Sprintf("http://127.0.0.1:%d", c.Port())},
+	})
+	defer d.Close()
+
+	dest = filepath.Join(dir, "d-dest", "game.zip")
+	err = d.Fetch(context.Background(), consumer, key, header, int64(len(contents)), dest)
+	assert.Error(t, err)
+	_, err = os.Stat(dest)
+	assert.True(t, os.IsNotExist(err))
+
+	// only the most recent entries are kept
+	e := start("e", peercache.Config{Keep: 2})
+	defer e.Close()
+	for buildID := int64(1); buildID <= 3; buildID++ {
+		assert.NoError(t, e.Add(consumer, peercache.Key{UploadID: 1, BuildID: buildID, Kind: "archive"}, header, write("game.zip", contents)))
+		time.Sleep(10 * time.Millisecond)
+	}
+	entries, err := e.Entries()
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 2) {
+		assert.EqualValues(t, 3, entries[0].Key.BuildID)
+		assert.EqualValues(t, 2, entries[1].Key.BuildID)
+	}
+
+	assert.NoError(t, dl.CheckIntegrity(consumer, header, int64(len(contents)), filepath.Join(dir, "b-dest", "game.zip")))
+}
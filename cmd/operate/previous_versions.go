@@ -0,0 +1,127 @@
+package operate
+
+import (
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/cmd/operate/versions"
+	"github.com/itchio/butler/database/models"
+	"github.com/itchio/butler/installer/bfs"
+	"github.com/itchio/butler/manager"
+	"github.com/itchio/ox"
+	"github.com/pkg/errors"
+)
+
+const previousVersionsKeptSetting = "previous_versions_kept"
+
+// PreviousVersionsKept returns how many builds replaced
+// by upgrades are kept for each cave.
+func PreviousVersionsKept(conn *sqlite.Conn) int64 {
+	var kept int64
+	models.GetSetting(conn, previousVersionsKeptSetting, &kept)
+	return kept
+}
+
+func SetPreviousVersionsKept(conn *sqlite.Conn, kept int64) {
+	models.SetSetting(conn, previousVersionsKeptSetting, kept)
+}
+
+// keepPreviousVersion stores the installed build before an upgrade,
+// if previous versions are kept.
+func keepPreviousVersion(oc *OperationContext, receiptIn *bfs.Receipt, resuming bool) {
+	consumer := oc.Consumer()
+	if oc.cave == nil {
+		return
+	}
+
+	if resuming {
+		// the install folder may already be partially upgraded,
+		// the version was stored when we started.
+		return
+	}
+
+	var kept int64
+	var folder string
+	oc.rc.WithConn(func(conn *sqlite.Conn) {
+		kept = PreviousVersionsKept(conn)
+		folder = oc.cave.GetPreviousVersionsFolder(conn)
+	})
+	if kept <= 0 {
+		return
+	}
+
+	if !receiptIn.HasFiles() {
+		consumer.Infof("No receipt files, not keeping previous version")
+		return
+	}
+
+	_, err := versions.Snapshot(consumer, oc.rc.WithConnString(oc.cave.GetInstallFolder), folder, receiptIn)
+	if err != nil {
+		consumer.Warnf("Could not keep previous version: %+v", err)
+		return
+	}
+
+	err = versions.Prune(folder, int(kept))
+	if err != nil {
+		consumer.Warnf("Could not prune previous versions: %+v", err)
+	}
+}
+
+// RollbackCave restores the most recently kept version of a cave,
+// without any network access.
+func RollbackCave(rc *butlerd.RequestContext, cave *models.Cave) (*versions.Version, error) {
+	consumer := rc.Consumer
+
+	var installFolder, folder string
+	rc.WithConn(func(conn *sqlite.Conn) {
+		installFolder = cave.GetInstallFolder(conn)
+		folder = cave.GetPreviousVersionsFolder(conn)
+	})
+
+	all, err := versions.List(folder)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(all) == 0 {
+		return nil, errors.WithStack(butlerd.CodeNoPreviousVersion)
+	}
+	v := all[0]
+
+	current, err := bfs.ReadReceipt(installFolder)
+	if err != nil {
+		consumer.Warnf("Could not read current receipt, only restoring files: %+v", err)
+	}
+
+	consumer.Infof("Rolling back %s", GameToString(cave.Game))
+	consumer.Infof("→ From:")
+	LogUpload(consumer, cave.Upload, cave.Build)
+	consumer.Infof("→ To:")
+	LogUpload(consumer, v.Receipt.Upload, v.Receipt.Build)
+
+	err = versions.Restore(consumer, installFolder, v, current)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	verdict, err := manager.Configure(consumer, installFolder, ox.CurrentRuntime())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	cave.SetVerdict(verdict)
+	cave.InstalledSize = verdict.TotalSize
+	if v.Receipt.Upload != nil {
+		cave.Upload = v.Receipt.Upload
+		cave.UploadID = v.Receipt.Upload.ID
+	}
+	cave.Build = v.Receipt.Build
+	cave.BuildID = 0
+	if v.Receipt.Build != nil {
+		cave.BuildID = v.Receipt.Build.ID
+	}
+	cave.UpdateInstallTime()
+	// the newer build is still available, don't install it again by ourselves
+	cave.SnoozedAt = cave.InstalledAt
+	rc.WithConn(cave.SaveWithAssocs)
+
+	return v, nil
+}
@@ -0,0 +1,61 @@
+package throttle
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/efarrer/iothrottler"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeCapPool struct {
+	bandwidths []iothrottler.Bandwidth
+	conns      int
+	released   bool
+}
+
+func (p *fakeCapPool) SetBandwidth(bandwidth iothrottler.Bandwidth) {
+	p.bandwidths = append(p.bandwidths, bandwidth)
+}
+
+func (p *fakeCapPool) AddConn(conn net.Conn) (net.Conn, error) {
+	p.conns++
+	return conn, nil
+}
+
+func (p *fakeCapPool) ReleasePool() {
+	p.released = true
+}
+
+func Test_Cap(t *testing.T) {
+	payload := make([]byte, 24*1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	pool := &fakeCapPool{}
+	c := newCap(pool, 128)
+	assert.EqualValues(t, 0, c.TransferDuration())
+
+	res, err := c.HTTPClient().Get(server.URL)
+	assert.NoError(t, err)
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	assert.NoError(t, err)
+	assert.Len(t, body, len(payload))
+	assert.Equal(t, 1, pool.conns, "connections should go through the cap's pool")
+	assert.True(t, c.BytesRead() >= int64(len(payload)), "bytes read should be counted")
+
+	c.SetRate(128)
+	assert.Len(t, pool.bandwidths, 0)
+	c.SetRate(0)
+	c.SetRate(64)
+	assert.EqualValues(t, []iothrottler.Bandwidth{iothrottler.Unlimited, 64 * iothrottler.Kbps}, pool.bandwidths)
+
+	c.Release()
+	assert.True(t, pool.released)
+}
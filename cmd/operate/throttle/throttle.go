@@ -0,0 +1,246 @@
+// Package throttle enforces bandwidth policies, which throttle all
+// downloads depending on the time of day, and per-download caps.
+//
+// Policies are applied to the pool every connection of package timeout
+// goes through. Caps use a separate pool per download, whose connections
+// are also part of the global pool.
+package throttle
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/efarrer/iothrottler"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/httpkit/timeout"
+	"github.com/itchio/wharf/eos/option"
+)
+
+// Interval is how often policies and caps of running downloads are refreshed
+const Interval = 5 * time.Second
+
+// Clock returns the current time
+type Clock func() time.Time
+
+// Pool is what a Scheduler throttles, see iothrottler.IOThrottlerPool
+type Pool interface {
+	SetBandwidth(bandwidth iothrottler.Bandwidth)
+}
+
+// Rate returns the rate policy gives at time t, in kbps.
+// 0 means unlimited.
+func Rate(policy *butlerd.BandwidthPolicy, t time.Time) int64 {
+	minute := int64(t.Hour()*60 + t.Minute())
+	day := int64(t.Weekday())
+
+	for _, rule := range policy.Rules {
+		if ruleApplies(rule, day, minute) {
+			return rule.Rate
+		}
+	}
+	return policy.DefaultRate
+}
+
+func ruleApplies(rule *butlerd.BandwidthRule, day int64, minute int64) bool {
+	if len(rule.Days) > 0 {
+		found := false
+		for _, d := range rule.Days {
+			if d == day {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if rule.Start <= rule.End {
+		return minute >= rule.Start && minute < rule.End
+	}
+	// goes over midnight
+	return minute >= rule.Start || minute < rule.End
+}
+
+// Bandwidth converts a rate in kbps, where 0 means unlimited,
+// to an iothrottler bandwidth.
+func Bandwidth(rate int64) iothrottler.Bandwidth {
+	if rate <= 0 {
+		return iothrottler.Unlimited
+	}
+	return iothrottler.Bandwidth(rate) * iothrottler.Kbps
+}
+
+// A Scheduler applies a policy to a pool as time passes
+type Scheduler struct {
+	Pool Pool
+	Now  Clock
+
+	lock    sync.Mutex
+	manual  int64
+	applied bool
+	rate    int64
+}
+
+// Global is the scheduler for all connections made by package timeout
+var Global = &Scheduler{
+	Pool: timeout.ThrottlerPool,
+	Now:  time.Now,
+}
+
+// SetManual throttles the pool to rate kbps, 0 meaning unlimited,
+// until a policy gives a different rate. The pool goes back to it
+// when the policy is cleared.
+func (s *Scheduler) SetManual(rate int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.Pool.SetBandwidth(Bandwidth(rate))
+	s.manual = rate
+}
+
+// Apply sets the pool's bandwidth to what policy gives right now,
+// if it's different from what was last applied. If policy is nil,
+// the pool goes back to the manual throttle.
+func (s *Scheduler) Apply(policy *butlerd.BandwidthPolicy) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if policy == nil {
+		if s.applied {
+			s.Pool.SetBandwidth(Bandwidth(s.manual))
+			s.applied = false
+		}
+		return
+	}
+
+	rate := Rate(policy, s.Now())
+	if s.applied && rate == s.rate {
+		return
+	}
+
+	s.Pool.SetBandwidth(Bandwidth(rate))
+	s.applied = true
+	s.rate = rate
+}
+
+// A Cap limits the bandwidth of a single download
+type Cap struct {
+	// accessed atomically, first to be 64-bit aligned
+	bytesRead int64
+	// when the first and last reads that received something
+	// happened, in nanoseconds since the epoch, accessed atomically
+	firstRead int64
+	lastRead  int64
+
+	pool   capPool
+	client *http.Client
+
+	lock sync.Mutex
+	rate int64
+}
+
+// capPool is what a Cap throttles, see iothrottler.IOThrottlerPool
+type capPool interface {
+	Pool
+	AddConn(conn net.Conn) (net.Conn, error)
+	ReleasePool()
+}
+
+// NewCap returns a cap of rate kbps, 0 meaning unlimited.
+// It must be released once the download is done.
+func NewCap(rate int64) *Cap {
+	return newCap(iothrottler.NewIOThrottlerPool(Bandwidth(rate)), rate)
+}
+
+func newCap(pool capPool, rate int64) *Cap {
+	c := &Cap{
+		pool: pool,
+		rate: rate,
+	}
+
+	client := timeout.NewDefaultClient()
+	transport := client.Transport.(*http.Transport)
+	dial := transport.Dial
+	transport.Dial = func(network, addr string) (net.Conn, error) {
+		conn, err := dial(network, addr)
+		if err != nil {
+			return nil, err
+		}
+		throttled, err := c.pool.AddConn(conn)
+		if err != nil {
+			return nil, err
+		}
+		return &countingConn{Conn: throttled, c: c}, nil
+	}
+	// follow redirects like eos does
+	client.CheckRedirect = option.DefaultSettings().HTTPClient.CheckRedirect
+	c.client = client
+
+	return c
+}
+
+// SetRate changes the cap, in kbps, 0 meaning unlimited.
+func (c *Cap) SetRate(rate int64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if rate == c.rate {
+		return
+	}
+	c.pool.SetBandwidth(Bandwidth(rate))
+	c.rate = rate
+}
+
+// HTTPClient returns a client whose connections follow the cap
+func (c *Cap) HTTPClient() *http.Client {
+	return c.client
+}
+
+// BytesRead returns how many bytes were received
+// by the cap's client so far
+func (c *Cap) BytesRead() int64 {
+	return atomic.LoadInt64(&c.bytesRead)
+}
+
+// TransferDuration returns how long the cap's client spent receiving,
+// from the first read that got something to the last one.
+func (c *Cap) TransferDuration() time.Duration {
+	first := atomic.LoadInt64(&c.firstRead)
+	if first == 0 {
+		return 0
+	}
+	return time.Duration(atomic.LoadInt64(&c.lastRead) - first)
+}
+
+func (c *Cap) received(n int) {
+	now := time.Now().UnixNano()
+	atomic.AddInt64(&c.bytesRead, int64(n))
+	atomic.CompareAndSwapInt64(&c.firstRead, 0, now)
+	atomic.StoreInt64(&c.lastRead, now)
+}
+
+// Release closes the cap's idle connections and stops its pool
+func (c *Cap) Release() {
+	if transport, ok := c.client.Transport.(*http.Transport); ok {
+		// closing waits for pending reads to time out
+		go transport.CloseIdleConnections()
+	}
+	c.pool.ReleasePool()
+}
+
+type countingConn struct {
+	net.Conn
+	c *Cap
+}
+
+func (cc *countingConn) Read(b []byte) (int, error) {
+	n, err := cc.Conn.Read(b)
+	if n > 0 {
+		cc.c.received(n)
+	}
+	return n, err
+}
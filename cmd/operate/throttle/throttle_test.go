@@ -0,0 +1,83 @@
+package throttle_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/efarrer/iothrottler"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/cmd/operate/throttle"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePool struct {
+	bandwidths []iothrottler.Bandwidth
+}
+
+func (p *fakePool) SetBandwidth(bandwidth iothrottler.Bandwidth) {
+	p.bandwidths = append(p.bandwidths, bandwidth)
+}
+
+func Test_Scheduler(t *testing.T) {
+	// a Monday
+	now := time.Date(2018, time.June, 4, 8, 0, 0, 0, time.UTC)
+	pool := &fakePool{}
+	s := &throttle.Scheduler{
+		Pool: pool,
+		Now: func() time.Time {
+			return now
+		},
+	}
+
+	workHours := &butlerd.BandwidthRule{
+		Days:  []int64{1, 2, 3, 4, 5},
+		Start: 9 * 60,
+		End:   17 * 60,
+		Rate:  16384,
+	}
+	evenings := &butlerd.BandwidthRule{
+		Start: 17 * 60,
+		End:   23 * 60,
+		Rate:  32768,
+	}
+	policy := &butlerd.BandwidthPolicy{
+		Rules: []*butlerd.BandwidthRule{workHours, evenings},
+	}
+
+	s.Apply(policy)
+	assert.EqualValues(t, []iothrottler.Bandwidth{iothrottler.Unlimited}, pool.bandwidths)
+
+	now = now.Add(90 * time.Minute)
+	s.Apply(policy)
+	s.Apply(policy)
+	assert.EqualValues(t, []iothrottler.Bandwidth{iothrottler.Unlimited, 16384 * iothrottler.Kbps}, pool.bandwidths)
+
+	now = now.Add(8 * time.Hour)
+	s.Apply(policy)
+	assert.EqualValues(t, 32768*iothrottler.Kbps, pool.bandwidths[2])
+
+	// a Saturday
+	now = time.Date(2018, time.June, 9, 10, 0, 0, 0, time.UTC)
+	assert.EqualValues(t, 0, throttle.Rate(policy, now))
+
+	pool.bandwidths = nil
+	s.SetManual(1024)
+	s.Apply(nil)
+	assert.EqualValues(t, []iothrottler.Bandwidth{1024 * iothrottler.Kbps, 1024 * iothrottler.Kbps}, pool.bandwidths)
+	s.Apply(nil)
+	assert.Len(t, pool.bandwidths, 2, "clearing twice should leave the pool alone")
+
+	pool.bandwidths = nil
+	s.Apply(policy)
+	assert.EqualValues(t, []iothrottler.Bandwidth{iothrottler.Unlimited}, pool.bandwidths)
+
+	overnight := &butlerd.BandwidthPolicy{
+		Rules: []*butlerd.BandwidthRule{
+			{Start: 22 * 60, End: 6 * 60, Rate: 0},
+		},
+		DefaultRate: 2048,
+	}
+	assert.EqualValues(t, 0, throttle.Rate(overnight, time.Date(2018, time.June, 9, 23, 30, 0, 0, time.UTC)))
+	assert.EqualValues(t, 0, throttle.Rate(overnight, time.Date(2018, time.June, 10, 5, 59, 0, 0, time.UTC)))
+	assert.EqualValues(t, 2048, throttle.Rate(overnight, time.Date(2018, time.June, 10, 6, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,79 @@
+package operate
+
+import (
+	"context"
+	"time"
+
+	"crawshaw.io/sqlite"
+	"github.com/google/uuid"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/database/models"
+	"github.com/itchio/wharf/werrors"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// recordTransfer appends what an attempt at performing download
+// downloadID received to the transfer log, along with how it ended.
+// It must be called while downloads are still throttled, since
+// that's where bytes are counted.
+func (oc *OperationContext) recordTransfer(downloadID string, params *InstallParams, startedAt time.Time, err error) {
+	if params.LocalFile != "" {
+		// nothing to transfer
+		return
+	}
+
+	finishedAt := time.Now().UTC()
+	r := &models.TransferRecord{
+		ID:         uuid.New().String(),
+		DownloadID: downloadID,
+		CaveID:     params.CaveID,
+		Reason:     string(params.Reason),
+		StartedAt:  &startedAt,
+		FinishedAt: &finishedAt,
+		Duration:   finishedAt.Sub(startedAt).Seconds(),
+	}
+	if params.Game != nil {
+		r.GameID = params.Game.ID
+	}
+	if params.Upload != nil {
+		r.UploadID = params.Upload.ID
+	}
+	if params.Build != nil {
+		r.BuildID = params.Build.ID
+	}
+	if oc.bandwidthCap != nil {
+		r.Bytes = oc.bandwidthCap.BytesRead()
+		// only count time spent receiving, not installing
+		if transferSecs := oc.bandwidthCap.TransferDuration().Seconds(); transferSecs > 0 {
+			r.AverageBPS = float64(r.Bytes) / transferSecs
+		}
+	}
+
+	switch {
+	case err == nil:
+		r.Status = string(butlerd.TransferStatusFinished)
+		r.BytesSaved = oc.bytesSaved
+	case isCancelled(err):
+		r.Status = string(butlerd.TransferStatusStopped)
+	default:
+		r.Status = string(butlerd.TransferStatusErrored)
+		code := int64(jsonrpc2.CodeInternalError)
+		if be, ok := butlerd.AsButlerdError(err); ok {
+			code = be.RpcErrorCode()
+		}
+		r.ErrorCode = &code
+	}
+
+	oc.rc.WithConn(func(conn *sqlite.Conn) {
+		models.MustSave(conn, r)
+	})
+}
+
+func isCancelled(err error) bool {
+	if be, ok := butlerd.AsButlerdError(err); ok {
+		return butlerd.Code(be.RpcErrorCode()) == butlerd.CodeOperationCancelled
+	}
+	cause := errors.Cause(err)
+	return cause == werrors.ErrCancelled || cause == context.Canceled
+}
@@ -0,0 +1,73 @@
+package operate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"crawshaw.io/sqlite"
+	"github.com/go-xorm/builder"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/butlerd/butlerdtest"
+	"github.com/itchio/butler/database/models"
+	itchio "github.com/itchio/go-itchio"
+	"github.com/itchio/hades"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RecordTransfer(t *testing.T) {
+	h := butlerdtest.New(t)
+	defer h.Close()
+
+	oc := &OperationContext{rc: h.RC, bytesSaved: 2048}
+	params := &InstallParams{
+		CaveID: "cave",
+		Reason: butlerd.DownloadReasonInstall,
+		Game:   &itchio.Game{ID: 123},
+		Upload: &itchio.Upload{ID: 456},
+	}
+	startedAt := time.Now().UTC().Add(-time.Minute)
+
+	record := func(downloadID string, params *InstallParams, err error) *models.TransferRecord {
+		oc.recordTransfer(downloadID, params, startedAt, err)
+
+		var records []*models.TransferRecord
+		h.RC.WithConn(func(conn *sqlite.Conn) {
+			models.MustSelect(conn, &records, builder.Eq{"download_id": downloadID}, hades.Search{})
+		})
+		if len(records) != 1 {
+			return nil
+		}
+		return records[0]
+	}
+
+	r := record("finished", params, nil)
+	assert.EqualValues(t, butlerd.TransferStatusFinished, r.Status)
+	assert.EqualValues(t, 2048, r.BytesSaved)
+	assert.EqualValues(t, 123, r.GameID)
+	assert.EqualValues(t, 456, r.UploadID)
+	assert.True(t, r.Duration >= 60)
+	assert.Nil(t, r.ErrorCode)
+
+	r = record("cancelled", params, errors.WithStack(context.Canceled))
+	assert.EqualValues(t, butlerd.TransferStatusStopped, r.Status)
+	assert.EqualValues(t, 0, r.BytesSaved, "only finished transfers save bytes")
+	assert.Nil(t, r.ErrorCode)
+
+	r = record("rpc-cancelled", params, butlerd.CodeOperationCancelled)
+	assert.EqualValues(t, butlerd.TransferStatusStopped, r.Status)
+
+	r = record("aborted", params, errors.WithStack(butlerd.CodeOperationAborted))
+	assert.EqualValues(t, butlerd.TransferStatusErrored, r.Status)
+	assert.EqualValues(t, butlerd.CodeOperationAborted, *r.ErrorCode)
+
+	r = record("errored", params, errors.New("disk on fire"))
+	assert.EqualValues(t, butlerd.TransferStatusErrored, r.Status)
+	assert.EqualValues(t, jsonrpc2.CodeInternalError, *r.ErrorCode)
+
+	local := *params
+	local.LocalFile = "/downloads/garden.zip"
+	assert.Nil(t, record("local", &local, nil), "installing local files transfers nothing")
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/itchio/butler/butlerd/messages"
 	"github.com/itchio/butler/cmd/wipe"
 	"github.com/itchio/butler/database/models"
+	"github.com/itchio/butler/endpoints/saves/snapshots"
+	"github.com/itchio/butler/endpoints/shortcuts/xdg"
 	"github.com/itchio/butler/installer"
 	"github.com/itchio/butler/installer/bfs"
 	"github.com/pkg/errors"
@@ -19,6 +21,9 @@ func UninstallPerform(ctx context.Context, rc *butlerd.RequestContext, params bu
 
 	cave := ValidateCave(rc, params.CaveID)
 
+	consumer.Infof("Backing up saves...")
+	snapshots.Auto(consumer, cave.GetInstallFolder(conn), cave.GetSaveBackupFolder(conn), butlerd.SaveSnapshotReasonUninstall)
+
 	if params.Hard {
 		consumer.Opf("Performing hard uninstall for (%s)", cave.ID)
 	} else {
@@ -80,11 +85,26 @@ func UninstallPerform(ctx context.Context, rc *butlerd.RequestContext, params bu
 		}
 	}
 
+	consumer.Infof("Removing previous versions...")
+	err := wipe.Do(consumer, cave.GetPreviousVersionsFolder(conn))
+	if err != nil {
+		consumer.Warnf("Could not remove previous versions: %+v", err)
+	}
+
 	consumer.Infof("Deleting cave...")
 	cave.Delete(conn)
 
 	consumer.Infof("Clearing out downloads...")
 	models.DiscardDownloadsByCaveID(conn, cave.ID)
+	models.DeleteLaunchOptions(conn, cave.ID)
+	models.DeleteUpdateCheck(conn, cave.ID)
+
+	removed, err := xdg.Remove(cave.ID)
+	if err != nil {
+		consumer.Warnf("Could not remove shortcut: %+v", err)
+	} else if removed {
+		consumer.Infof("Removed shortcut")
+	}
 
 	func() {
 		defer func() {
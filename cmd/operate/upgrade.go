@@ -7,8 +7,6 @@ import (
 	"github.com/itchio/butler/installer/bfs"
 	itchio "github.com/itchio/go-itchio"
 	"github.com/itchio/httpkit/progress"
-	"github.com/itchio/savior/filesource"
-	"github.com/itchio/wharf/eos/option"
 	"github.com/itchio/wharf/pools/fspool"
 	"github.com/itchio/wharf/pwr/bowl"
 	"github.com/itchio/wharf/pwr/patcher"
@@ -25,6 +23,8 @@ func upgrade(oc *OperationContext, meta *MetaSubcontext, isub *InstallSubcontext
 
 	consumer.Infof("Applying %d patches (%d already done)", remainingPatches, donePatches)
 
+	keepPreviousVersion(oc, receiptIn, donePatches > 0)
+
 	for i := istate.UpgradePathIndex; i < totalPatches; i++ {
 		build := istate.UpgradePath.Builds[i]
 		err := applyPatch(oc, meta, isub, receiptIn, i)
@@ -60,7 +60,7 @@ func applyPatch(oc *OperationContext, meta *MetaSubcontext, isub *InstallSubcont
 		UUID:        istate.DownloadSessionID,
 	})
 
-	patchSource, err := filesource.Open(patchURL, option.WithConsumer(consumer))
+	patchSource, err := oc.openPatch(patchURL, PatchPeerKey(params.Upload, build, subType))
 	if err != nil {
 		return errors.Wrap(err, "opening remote patch")
 	}
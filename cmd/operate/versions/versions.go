@@ -0,0 +1,317 @@
+// Package versions keeps the files of builds replaced by patches, so
+// that a cave can be rolled back without any network access.
+//
+// Each version is a folder named `build-<id>` in a per-cave folder,
+// containing a `version.json` metadata file and the files listed in
+// the build's receipt under `files/`. Files are copied rather than
+// hardlinked: heals, reinstalls and games themselves modify install
+// files in place, which would change the kept version too.
+package versions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/itchio/butler/installer/bfs"
+	"github.com/itchio/wharf/state"
+	"github.com/pkg/errors"
+)
+
+const metadataName = "version.json"
+
+// A Version is a build that was installed before an upgrade
+type Version struct {
+	// Receipt of the build, as it was before the upgrade
+	Receipt   *bfs.Receipt `json:"receipt"`
+	CreatedAt time.Time    `json:"createdAt"`
+
+	// Folder the version is stored in
+	Path string `json:"-"`
+}
+
+// FilesFolder returns where the files of the version are stored
+func (v *Version) FilesFolder() string {
+	return filepath.Join(v.Path, "files")
+}
+
+func (v *Version) filePath(rel string) string {
+	return filepath.Join(v.FilesFolder(), filepath.FromSlash(rel))
+}
+
+// Snapshot stores the files listed in the receipt of the build
+// installed in installFolder, as a new version in folder.
+func Snapshot(consumer *state.Consumer, installFolder string, folder string, receipt *bfs.Receipt) (*Version, error) {
+	if !receipt.HasFiles() {
+		return nil, errors.New("Receipt has no files, can't keep previous version")
+	}
+
+	name := fmt.Sprintf("at-%d", time.Now().UnixNano())
+	if receipt.Build != nil {
+		name = fmt.Sprintf("build-%d", receipt.Build.ID)
+	}
+
+	v := &Version{
+		Receipt:   receipt,
+		CreatedAt: time.Now().UTC(),
+		Path:      filepath.Join(folder, name),
+	}
+	tmp := &Version{
+		Path: v.Path + ".tmp",
+	}
+
+	for _, p := range []string{v.Path, tmp.Path} {
+		err := os.RemoveAll(p)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	var copied int
+	for _, rel := range receipt.Files {
+		src := filepath.Join(installFolder, filepath.FromSlash(rel))
+		dst := tmp.filePath(rel)
+
+		stats, err := os.Lstat(src)
+		if err != nil {
+			if os.IsNotExist(err) {
+				consumer.Warnf("(%s) is listed in receipt but missing, skipping", rel)
+				continue
+			}
+			return nil, errors.WithStack(err)
+		}
+
+		err = os.MkdirAll(filepath.Dir(dst), 0755)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		switch {
+		case stats.IsDir():
+			err = os.MkdirAll(dst, 0755)
+		case stats.Mode()&os.ModeSymlink != 0:
+			var target string
+			target, err = os.Readlink(src)
+			if err == nil {
+				err = os.Symlink(target, dst)
+			}
+		default:
+			err = copyFile(src, dst, stats.Mode())
+			copied++
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	err := writeMetadata(tmp.Path, v)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	err = os.Rename(tmp.Path, v.Path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	consumer.Infof("Kept previous version in (%s) (%d files copied)", v.Path, copied)
+	return v, nil
+}
+
+// List returns all versions stored in folder, most recent first
+func List(folder string) ([]*Version, error) {
+	entries, err := ioutil.ReadDir(folder)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	var res []*Version
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		p := filepath.Join(folder, e.Name())
+		payload, err := ioutil.ReadFile(filepath.Join(p, metadataName))
+		if err != nil {
+			// unfinished snapshot
+			continue
+		}
+
+		v := &Version{}
+		err = json.Unmarshal(payload, v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading version (%s)", p)
+		}
+		v.Path = p
+		res = append(res, v)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreatedAt.After(res[j].CreatedAt)
+	})
+	return res, nil
+}
+
+// Prune removes all but the keep most recent versions, along
+// with unfinished snapshots.
+func Prune(folder string, keep int) error {
+	all, err := List(folder)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	kept := make(map[string]bool)
+	for i, v := range all {
+		if i < keep {
+			kept[filepath.Base(v.Path)] = true
+		}
+	}
+
+	entries, err := ioutil.ReadDir(folder)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.WithStack(err)
+	}
+
+	for _, e := range entries {
+		if kept[e.Name()] {
+			continue
+		}
+		err = os.RemoveAll(filepath.Join(folder, e.Name()))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// Restore puts the files of v back into installFolder, removes files of
+// the current build that v didn't have, and writes v's receipt.
+// The version is consumed in the process.
+func Restore(consumer *state.Consumer, installFolder string, v *Version, current *bfs.Receipt) error {
+	wanted := make(map[string]bool)
+	for _, rel := range v.Receipt.Files {
+		wanted[rel] = true
+	}
+
+	if current != nil {
+		// deepest paths first, so folders are empty by the time we get to them
+		files := append([]string{}, current.Files...)
+		sort.Sort(sort.Reverse(sort.StringSlice(files)))
+
+		var removed int
+		for _, rel := range files {
+			if wanted[rel] {
+				continue
+			}
+
+			p := filepath.Join(installFolder, filepath.FromSlash(rel))
+			err := os.Remove(p)
+			if err != nil && !os.IsNotExist(err) {
+				consumer.Warnf("Could not remove (%s): %v", rel, err)
+				continue
+			}
+			removed++
+		}
+		consumer.Infof("Removed %d files the previous version didn't have", removed)
+	}
+
+	for _, rel := range v.Receipt.Files {
+		src := v.filePath(rel)
+		dst := filepath.Join(installFolder, filepath.FromSlash(rel))
+
+		stats, err := os.Lstat(src)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return errors.WithStack(err)
+		}
+
+		if stats.IsDir() {
+			err = os.MkdirAll(dst, 0755)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			continue
+		}
+
+		err = os.MkdirAll(filepath.Dir(dst), 0755)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		err = os.RemoveAll(dst)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		err = os.Rename(src, dst)
+		if err != nil {
+			if stats.Mode()&os.ModeSymlink != 0 {
+				return errors.WithStack(err)
+			}
+			err = copyFile(src, dst, stats.Mode())
+			if err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	}
+
+	err := v.Receipt.WriteReceipt(installFolder)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = os.RemoveAll(v.Path)
+	if err != nil {
+		consumer.Warnf("Could not remove restored version: %v", err)
+	}
+	return nil
+}
+
+func writeMetadata(dir string, v *Version) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return ioutil.WriteFile(filepath.Join(dir, metadataName), payload, 0644)
+}
+
+func copyFile(src string, dst string, mode os.FileMode) error {
+	r, err := os.Open(src)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer r.Close()
+
+	w, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = io.Copy(w, r)
+	if err != nil {
+		w.Close()
+		return errors.WithStack(err)
+	}
+
+	return w.Close()
+}
@@ -0,0 +1,108 @@
+package versions_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/itchio/butler/cmd/operate/memorylogger"
+	"github.com/itchio/butler/cmd/operate/versions"
+	"github.com/itchio/butler/installer/bfs"
+	itchio "github.com/itchio/go-itchio"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Versions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "versions")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	installFolder := filepath.Join(dir, "install")
+	folder := filepath.Join(dir, "previous-versions")
+	consumer := memorylogger.New().Consumer()
+
+	write := func(rel string, contents string) {
+		p := filepath.Join(installFolder, filepath.FromSlash(rel))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		assert.NoError(t, ioutil.WriteFile(p, []byte(contents), 0644))
+	}
+	read := func(p string) string {
+		contents, err := ioutil.ReadFile(p)
+		if err != nil {
+			return "<missing>"
+		}
+		return string(contents)
+	}
+	installed := func(rel string) string {
+		return read(filepath.Join(installFolder, filepath.FromSlash(rel)))
+	}
+
+	write("a.txt", "old a")
+	write("b.txt", "same b")
+	write("sub/c.txt", "old c")
+	oldReceipt := &bfs.Receipt{
+		Build: &itchio.Build{ID: 1},
+		Files: []string{"a.txt", "b.txt", "sub/c.txt"},
+	}
+	assert.NoError(t, oldReceipt.WriteReceipt(installFolder))
+
+	v, err := versions.Snapshot(consumer, installFolder, folder, oldReceipt)
+	assert.NoError(t, err)
+	assert.EqualValues(t, filepath.Join(folder, "build-1"), v.Path)
+
+	// heals, reinstalls and games modify files in place
+	f, err := os.OpenFile(filepath.Join(installFolder, "a.txt"), os.O_WRONLY|os.O_TRUNC, 0644)
+	assert.NoError(t, err)
+	_, err = f.Write([]byte("new a"))
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+	write("d.txt", "new d")
+	assert.NoError(t, os.Remove(filepath.Join(installFolder, "sub", "c.txt")))
+
+	newReceipt := &bfs.Receipt{
+		Build: &itchio.Build{ID: 2},
+		Files: []string{"a.txt", "b.txt", "d.txt"},
+	}
+	assert.NoError(t, newReceipt.WriteReceipt(installFolder))
+
+	assert.EqualValues(t, "new a", installed("a.txt"))
+	assert.EqualValues(t, "old a", read(filepath.Join(v.FilesFolder(), "a.txt")))
+	assert.EqualValues(t, "old c", read(filepath.Join(v.FilesFolder(), "sub", "c.txt")))
+
+	all, err := versions.List(folder)
+	assert.NoError(t, err)
+	if assert.Len(t, all, 1) {
+		assert.EqualValues(t, 1, all[0].Receipt.Build.ID)
+		v = all[0]
+	}
+
+	assert.NoError(t, versions.Restore(consumer, installFolder, v, newReceipt))
+	assert.EqualValues(t, "old a", installed("a.txt"))
+	assert.EqualValues(t, "same b", installed("b.txt"))
+	assert.EqualValues(t, "old c", installed("sub/c.txt"))
+	assert.EqualValues(t, "<missing>", installed("d.txt"))
+
+	receipt, err := bfs.ReadReceipt(installFolder)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, receipt.Build.ID)
+
+	all, err = versions.List(folder)
+	assert.NoError(t, err)
+	assert.Len(t, all, 0)
+
+	for buildID := int64(3); buildID <= 5; buildID++ {
+		_, err := versions.Snapshot(consumer, installFolder, folder, &bfs.Receipt{
+			Build: &itchio.Build{ID: buildID},
+			Files: oldReceipt.Files,
+		})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, versions.Prune(folder, 2))
+	all, err = versions.List(folder)
+	assert.NoError(t, err)
+	if assert.Len(t, all, 2) {
+		assert.EqualValues(t, 5, all[0].Receipt.Build.ID)
+		assert.EqualValues(t, 4, all[1].Receipt.Build.ID)
+	}
+}
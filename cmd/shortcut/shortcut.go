@@ -0,0 +1,209 @@
+package shortcut
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/cmd/operate/loopbackconn"
+	"github.com/itchio/butler/comm"
+	"github.com/itchio/butler/database"
+	"github.com/itchio/butler/endpoints/launch"
+	"github.com/itchio/butler/endpoints/shortcuts"
+	"github.com/itchio/butler/mansion"
+	"github.com/pkg/errors"
+)
+
+var createArgs = struct {
+	caveID     *string
+	prereqsDir *string
+}{}
+
+var removeArgs = struct {
+	caveID *string
+}{}
+
+var launchArgs = struct {
+	caveID     *string
+	prereqsDir *string
+}{}
+
+func Register(ctx *mansion.Context) {
+	parentCmd := ctx.App.Command("shortcut", "Manage application menu shortcuts for installed games (requires --dbpath)")
+
+	{
+		cmd := parentCmd.Command("create", "Add an installed game to the application menu")
+		createArgs.caveID = cmd.Arg("cave", "ID of the cave").Required().String()
+		createArgs.prereqsDir = cmd.Flag("prereqs-dir", "Where to store installers for prerequisites").String()
+		ctx.Register(cmd, doCreate)
+	}
+
+	{
+		cmd := parentCmd.Command("remove", "Remove an installed game from the application menu")
+		removeArgs.caveID = cmd.Arg("cave", "ID of the cave").Required().String()
+		ctx.Register(cmd, doRemove)
+	}
+
+	{
+		cmd := parentCmd.Command("launch", "Launch an installed game, as shortcuts do").Hidden()
+		launchArgs.caveID = cmd.Arg("cave", "ID of the cave").Required().String()
+		launchArgs.prereqsDir = cmd.Flag("prereqs-dir", "Where to store installers for prerequisites").String()
+		ctx.Register(cmd, doLaunch)
+	}
+}
+
+func doCreate(ctx *mansion.Context) {
+	ctx.Must(withRequestContext(ctx, func(rc *butlerd.RequestContext) error {
+		res, err := shortcuts.CreateShortcut(rc, butlerd.CavesCreateShortcutParams{
+			CaveID:     *createArgs.caveID,
+			PrereqsDir: *createArgs.prereqsDir,
+		})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		comm.ResultOrPrint(res, func() {
+			comm.Statf("Shortcut written to (%s)", res.Path)
+		})
+		return nil
+	}))
+}
+
+func doRemove(ctx *mansion.Context) {
+	ctx.Must(withRequestContext(ctx, func(rc *butlerd.RequestContext) error {
+		res, err := shortcuts.RemoveShortcut(rc, butlerd.CavesRemoveShortcutParams{
+			CaveID: *removeArgs.caveID,
+		})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		comm.ResultOrPrint(res, func() {
+			if res.Removed {
+				comm.Statf("Shortcut removed")
+			} else {
+				comm.Statf("There was no shortcut for cave (%s)", *removeArgs.caveID)
+			}
+		})
+		return nil
+	}))
+}
+
+// doLaunch runs in its own process, so the games it launches aren't in
+// the daemon's RunningCaves: the launch lock in the install folder is
+// the only thing keeping a game from running twice.
+func doLaunch(ctx *mansion.Context) {
+	prereqsDir := *launchArgs.prereqsDir
+	if prereqsDir == "" {
+		prereqsDir = filepath.Join(os.TempDir(), "butler-prereqs")
+	}
+
+	ctx.Must(withRequestContext(ctx, func(rc *butlerd.RequestContext) error {
+		_, err := launch.Launch(rc, butlerd.LaunchParams{
+			CaveID:     *launchArgs.caveID,
+			PrereqsDir: prereqsDir,
+			ServeHTML:  true,
+			IfRunning:  butlerd.IfRunningPolicyFocus,
+		})
+		return err
+	}))
+}
+
+// withRequestContext opens the database and calls f with a context in
+// which butlerd handlers can be called. Requests sent by handlers are
+// answered as a client would, without asking any questions.
+func withRequestContext(ctx *mansion.Context, f func(rc *butlerd.RequestContext) error) error {
+	if ctx.DBPath == "" {
+		return errors.New("--dbpath must be set")
+	}
+
+	dbPool, err := sqlite.Open(ctx.DBPath, 0, 10)
+	if err != nil {
+		return errors.WithMessage(err, "opening DB")
+	}
+	defer dbPool.Close()
+
+	func() {
+		conn := dbPool.Get(context.Background().Done())
+		defer dbPool.Put(conn)
+		err = database.Prepare(conn)
+	}()
+	if err != nil {
+		return errors.WithMessage(err, "preparing DB")
+	}
+
+	consumer := comm.NewStateConsumer()
+	conn := loopbackconn.New(consumer)
+
+	conn.OnCall("PickManifestAction", func(ctx context.Context, method string, params interface{}, result interface{}) error {
+		result.(*butlerd.PickManifestActionResult).Index = 0
+		return nil
+	})
+	conn.OnCall("AllowSandboxSetup", func(ctx context.Context, method string, params interface{}, result interface{}) error {
+		result.(*butlerd.AllowSandboxSetupResult).Allow = false
+		return nil
+	})
+	conn.OnCall("PrereqsFailed", func(ctx context.Context, method string, params interface{}, result interface{}) error {
+		result.(*butlerd.PrereqsFailedResult).Continue = false
+		return nil
+	})
+	conn.OnCall("URLLaunch", func(ctx context.Context, method string, params interface{}, result interface{}) error {
+		return xdgOpen(params.(butlerd.URLLaunchParams).URL)
+	})
+	conn.OnCall("ShellLaunch", func(ctx context.Context, method string, params interface{}, result interface{}) error {
+		return xdgOpen(params.(butlerd.ShellLaunchParams).ItemPath)
+	})
+	conn.OnCall("HTMLLaunch", func(ctx context.Context, method string, params interface{}, result interface{}) error {
+		p := params.(butlerd.HTMLLaunchParams)
+		err := xdgOpen(p.URL)
+		if err != nil {
+			return err
+		}
+
+		// the game is only served until we reply
+		comm.Logf("Serving game at %s, press Ctrl+C to stop", p.URL)
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(sigs)
+		select {
+		case <-sigs:
+		case <-ctx.Done():
+		}
+		return nil
+	})
+
+	router := butlerd.NewRouter(dbPool, ctx.NewClient, ctx.HTTPClient, ctx.HTTPTransport)
+	router.ButlerVersion = ctx.Version
+	router.ButlerVersionString = ctx.VersionString
+	rc := router.NewRequestContext(context.Background(), conn, consumer)
+
+	return callHandler(rc, f)
+}
+
+// callHandler turns panics into errors, like the daemon does
+func callHandler(rc *butlerd.RequestContext, f func(rc *butlerd.RequestContext) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if rErr, ok := r.(error); ok {
+				err = errors.WithStack(rErr)
+			} else {
+				err = errors.Errorf("panic: %v", r)
+			}
+		}
+	}()
+
+	return f(rc)
+}
+
+func xdgOpen(target string) error {
+	err := exec.Command("xdg-open", target).Run()
+	if err != nil {
+		return errors.Wrapf(err, "opening (%s)", target)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/itchio/httpkit/progress"
@@ -33,13 +34,25 @@ var args = struct {
 	dir      *string
 	platform *string
 	arch     *string
+	locale   *string
+	matrix   *bool
 }{}
 
+var matrixRuntimes = []*ox.Runtime{
+	{Platform: ox.PlatformWindows, Is64: false},
+	{Platform: ox.PlatformWindows, Is64: true},
+	{Platform: ox.PlatformLinux, Is64: false},
+	{Platform: ox.PlatformLinux, Is64: true},
+	{Platform: ox.PlatformOSX, Is64: true},
+}
+
 func Register(ctx *mansion.Context) {
 	cmd := ctx.App.Command("validate", "Validate a build folder, including its maniest if any")
 	args.dir = cmd.Arg("dir", "Path of build folder to validate").Required().String()
 	args.platform = cmd.Flag("platform", "Platform to validate for").Enum(string(ox.PlatformLinux), string(ox.PlatformOSX), string(ox.PlatformWindows))
 	args.arch = cmd.Flag("arch", "Architecture to validate for").Enum(string(dash.Arch386), string(dash.ArchAmd64))
+	args.locale = cmd.Flag("locale", "Locale to expand {{locale}} with").Default("en").String()
+	args.matrix = cmd.Flag("matrix", "Show which actions would be offered on each platform and architecture").Bool()
 	ctx.Register(cmd, doValidate)
 }
 
@@ -95,6 +108,12 @@ func Validate(consumer *state.Consumer) error {
 	consumer.Infof("For runtime %s (use --platform and --arch to simulate others)", runtime)
 	consumer.Infof("")
 
+	vars := &manifest.Vars{
+		Runtime:       runtime,
+		InstallFolder: filepath.Dir(manifestPath),
+		Locale:        *args.locale,
+	}
+
 	if !hasDir {
 		showWarning("In manifest-only validation mode. Pass a valid build directory to perform further checks.")
 	}
@@ -224,6 +243,24 @@ func Validate(consumer *state.Consumer) error {
 					showError("Unknown platform specified: (%s)", action.Platform)
 				}
 			}
+			switch action.Arch {
+			case "":
+				// universal
+			case string(dash.Arch386):
+				consumer.Infof("    Only for 32-bit")
+			case string(dash.ArchAmd64):
+				consumer.Infof("    Only for 64-bit")
+			default:
+				showError("Unknown arch specified: (%s)", action.Arch)
+			}
+			if action.Condition != "" {
+				err := manifest.CheckCondition(action.Condition)
+				if err != nil {
+					showError("Invalid condition: %s", err.Error())
+				} else {
+					consumer.Infof("    Only if (%s)", action.Condition)
+				}
+			}
 			if action.Scope != "" {
 				consumer.Infof("    Requests API scope (%s)", action.Scope)
 			}
@@ -234,15 +271,65 @@ func Validate(consumer *state.Consumer) error {
 				consumer.Infof("    Console")
 			}
 			if len(action.Args) > 0 {
-				consumer.Infof("    Passes arguments: %s", strings.Join(action.Args, " ::: "))
+				consumer.Infof("    Passes arguments: %s", strings.Join(manifest.ExpandArgs(action, vars), " ::: "))
+			}
+			if len(action.Env) > 0 {
+				for k, v := range manifest.ExpandEnv(action, vars) {
+					consumer.Infof("    Sets environment variable %s=%s", k, v)
+				}
+			}
+			for _, name := range manifest.UnknownVars(action) {
+				showError("Unknown template variable used: {{%s}}", name)
 			}
 			if hasDir {
-				sr, err := launch.DetermineStrategy(consumer, runtime, dir, action)
+				sr, err := launch.DetermineStrategy(consumer, vars, action)
 				if err != nil {
 					showError(err.Error())
 				} else {
 					printStrategyResult(sr)
 				}
+
+				if action.WorkingDir != "" {
+					workingDir := manifest.ExpandWorkingDir(action, vars)
+					stats, err := os.Stat(workingDir)
+					if err != nil {
+						showError("Working directory (%s) does not exist", workingDir)
+					} else if !stats.IsDir() {
+						showError("Working directory (%s) is not a directory", workingDir)
+					} else {
+						consumer.Infof("    Runs in working directory (%s)", workingDir)
+					}
+				}
+			} else if action.WorkingDir != "" {
+				consumer.Infof("    Runs in working directory (%s)", action.WorkingDir)
+			}
+		}
+
+		if *args.matrix {
+			consumer.Infof("")
+			consumer.Statf("Actions offered per runtime:")
+			for _, r := range matrixRuntimes {
+				host := &manifest.Host{
+					Runtime:   r,
+					LookupEnv: os.LookupEnv,
+				}
+				if r.Platform == ox.CurrentRuntime().Platform {
+					host.OSVersion = manifest.OSVersion()
+				}
+
+				consumer.Infof("")
+				consumer.Infof("  → %s", r)
+				actions := manifest.ListActions(appManifest, host)
+				if len(actions) == 0 {
+					consumer.Infof("    (no actions, heuristics will be used)")
+				}
+				for _, action := range actions {
+					consumer.Infof("    '%s' (%s)", action.Name, manifest.Expand(action.Path, &manifest.Vars{
+						Runtime:       r,
+						InstallFolder: vars.InstallFolder,
+						Locale:        vars.Locale,
+					}))
+				}
 			}
 		}
 	} else {
@@ -253,6 +340,57 @@ func Validate(consumer *state.Consumer) error {
 		}
 	}
 
+	if len(appManifest.Hooks) > 0 {
+		consumer.Infof("")
+		consumer.Statf("Validating %d hooks...", len(appManifest.Hooks))
+		for _, hook := range appManifest.Hooks {
+			consumer.Infof("")
+			consumer.Infof("  → Hook (%s) on (%s)", hook.Path, hook.Event)
+			switch hook.Event {
+			case butlerd.HookEventPreLaunch, butlerd.HookEventPostExit:
+				// good
+			default:
+				showError("Unknown hook event specified: (%s)", hook.Event)
+			}
+			if hook.Platform != "" {
+				consumer.Infof("    Only for %s", hook.Platform)
+			}
+			if hook.Timeout > 0 {
+				consumer.Infof("    Times out after %d seconds", hook.Timeout)
+			}
+			if len(hook.Args) > 0 {
+				consumer.Infof("    Passes arguments: %s", strings.Join(manifest.ExpandHookArgs(hook, vars), " ::: "))
+			}
+			if hasDir && (hook.Platform == "" || hook.Platform == runtime.Platform) {
+				hookPath := manifest.ExpandHookPath(hook, vars)
+				_, err := os.Stat(hookPath)
+				if err != nil {
+					showError("Hook refers to non-existent path (%s)", hookPath)
+				}
+			}
+		}
+	}
+
+	if len(appManifest.Saves) > 0 {
+		consumer.Infof("")
+		consumer.Statf("Validating %d save paths...", len(appManifest.Saves))
+		saveVars := *vars
+		saveVars.UserDirs = manifest.CurrentUserDirs(runtime)
+		for _, save := range appManifest.Saves {
+			consumer.Infof("")
+			consumer.Infof("  → Save path (%s)", save.Path)
+			if save.Platform != "" {
+				consumer.Infof("    Only for %s", save.Platform)
+			}
+			root := manifest.ExpandSavePath(save, &saveVars)
+			if strings.Contains(root.Base, "{{") {
+				showError("Save path uses unknown template variables (%s)", root.Base)
+				continue
+			}
+			consumer.Infof("    Matches (%s) in (%s)", root.Glob, root.Base)
+		}
+	}
+
 	consumer.Infof("")
 	if len(appManifest.Prereqs) > 0 {
 		consumer.Statf("Validating %d prereqs...", len(appManifest.Prereqs))
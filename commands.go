@@ -7,6 +7,7 @@ import (
 	"github.com/itchio/butler/cmd/clean"
 	"github.com/itchio/butler/cmd/configure"
 	"github.com/itchio/butler/cmd/cp"
+	"github.com/itchio/butler/cmd/crashes"
 	"github.com/itchio/butler/cmd/daemon"
 	"github.com/itchio/butler/cmd/diff"
 	"github.com/itchio/butler/cmd/ditto"
@@ -31,6 +32,7 @@ import (
 	"github.com/itchio/butler/cmd/rediff"
 	"github.com/itchio/butler/cmd/repack"
 	"github.com/itchio/butler/cmd/run"
+	"github.com/itchio/butler/cmd/shortcut"
 	"github.com/itchio/butler/cmd/sign"
 	"github.com/itchio/butler/cmd/singlediff"
 	"github.com/itchio/butler/cmd/sizeof"
@@ -113,6 +115,8 @@ func registerCommands(ctx *mansion.Context) {
 
 	fujicmd.Register(ctx)
 	validate.Register(ctx)
+	crashes.Register(ctx)
+	shortcut.Register(ctx)
 
 	singlediff.Register(ctx)
 	rediff.Register(ctx)
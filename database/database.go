@@ -2,6 +2,7 @@ package database
 
 import (
 	"crawshaw.io/sqlite"
+	"crawshaw.io/sqlite/sqliteutil"
 	"github.com/itchio/butler/database/models"
 	"github.com/pkg/errors"
 )
@@ -15,3 +16,19 @@ func Prepare(conn *sqlite.Conn) error {
 
 	return nil
 }
+
+// Path returns the path of the file conn's main database is stored in,
+// or an empty string for in-memory databases.
+func Path(conn *sqlite.Conn) (string, error) {
+	var path string
+	err := sqliteutil.Exec(conn, "PRAGMA database_list", func(stmt *sqlite.Stmt) error {
+		if stmt.GetText("name") == "main" {
+			path = stmt.GetText("file")
+		}
+		return nil
+	})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return path, nil
+}
@@ -24,4 +24,11 @@ var AllModels = []interface{}{
 	&ProfileData{},
 	&FetchInfo{},
 	&GameUpload{},
+	&PlaySession{},
+	&CrashReport{},
+	&CompatRunner{},
+	&LaunchOptions{},
+	&Setting{},
+	&UpdateCheck{},
+	&TransferRecord{},
 }
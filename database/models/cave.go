@@ -1,6 +1,7 @@
 package models
 
 import (
+	"path/filepath"
 	"time"
 
 	"crawshaw.io/sqlite"
@@ -26,6 +27,10 @@ type Cave struct {
 	Morphing bool `json:"morphing"`
 	Pinned   bool `json:"pinned"`
 
+	// What to do when an update is found, see butlerd.UpdatePolicy.
+	// Empty means "notify".
+	UpdatePolicy string `json:"updatePolicy"`
+
 	InstalledAt   *time.Time `json:"installedAt"`
 	LastTouchedAt *time.Time `json:"lastTouchedAt"`
 	SecondsRun    int64      `json:"secondsRun"`
@@ -43,6 +48,13 @@ type Cave struct {
 	// If set, InstallLocationID is empty and this is used
 	// for all operations instead
 	CustomInstallFolder string `json:"customInstallFolder"`
+
+	// If set, Windows executables are launched through this
+	// compatibility runner on Linux
+	CompatRunnerID string `json:"compatRunnerId"`
+
+	// Wine prefix (or Proton compat data path) used by the runner
+	CompatPrefix string `json:"compatPrefix"`
 }
 
 func (c *Cave) SetVerdict(verdict *dash.Verdict) {
@@ -105,6 +117,22 @@ func (c *Cave) GetInstallFolder(conn *sqlite.Conn) string {
 	return c.GetInstallLocation(conn).GetInstallFolder(c.InstallFolderName)
 }
 
+func (c *Cave) GetSaveBackupFolder(conn *sqlite.Conn) string {
+	if c.CustomInstallFolder != "" {
+		return filepath.Join(filepath.Dir(c.CustomInstallFolder), "save-backups", c.ID)
+	}
+
+	return c.GetInstallLocation(conn).GetSaveBackupFolder(c.ID)
+}
+
+func (c *Cave) GetPreviousVersionsFolder(conn *sqlite.Conn) string {
+	if c.CustomInstallFolder != "" {
+		return filepath.Join(filepath.Dir(c.CustomInstallFolder), "previous-versions", c.ID)
+	}
+
+	return c.GetInstallLocation(conn).GetPreviousVersionsFolder(c.ID)
+}
+
 func (c *Cave) Preload(conn *sqlite.Conn) {
 	if c == nil {
 		return
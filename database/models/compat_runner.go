@@ -0,0 +1,41 @@
+package models
+
+import (
+	"crawshaw.io/sqlite"
+	"github.com/go-xorm/builder"
+	"github.com/itchio/hades"
+)
+
+// A CompatRunner is a user-configured compatibility tool, like
+// Wine or Proton, used to launch Windows executables on Linux.
+type CompatRunner struct {
+	// Chosen by the client, like "wine-staging"
+	ID string `json:"id" hades:"primary_key"`
+
+	// Human-friendly name
+	Name string `json:"name"`
+
+	// "wine" or "proton", decides how the command is invoked
+	Kind string `json:"kind"`
+
+	// Absolute path to the `wine` binary or `proton` script
+	Command string `json:"command"`
+}
+
+func CompatRunnerByID(conn *sqlite.Conn, id string) *CompatRunner {
+	var cr CompatRunner
+	if MustSelectOne(conn, &cr, builder.Eq{"id": id}) {
+		return &cr
+	}
+	return nil
+}
+
+func AllCompatRunners(conn *sqlite.Conn) []*CompatRunner {
+	var crs []*CompatRunner
+	MustSelect(conn, &crs, builder.NewCond(), hades.Search{}.OrderBy("name ASC"))
+	return crs
+}
+
+func (cr *CompatRunner) Save(conn *sqlite.Conn) {
+	MustSave(conn, cr)
+}
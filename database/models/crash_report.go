@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"crawshaw.io/sqlite"
+	"github.com/go-xorm/builder"
+	"github.com/itchio/hades"
+)
+
+// A CrashReport indexes a crash report written to a cave's
+// install folder, cf. package `endpoints/launch/crashes`.
+type CrashReport struct {
+	// An UUID
+	ID string `json:"id" hades:"primary_key"`
+
+	// Same as the play session ID
+	LaunchID string `json:"launchId"`
+
+	CaveID  string `json:"caveId"`
+	GameID  int64  `json:"gameId"`
+	BuildID int64  `json:"buildId"`
+
+	CreatedAt *time.Time `json:"createdAt"`
+
+	ExitCode int64  `json:"exitCode"`
+	Signal   string `json:"signal"`
+
+	// Absolute path of the report on disk
+	Path string `json:"path"`
+}
+
+// CrashReports returns indexed crash reports, most recent first.
+// If caveID is non-empty, only reports for that cave are returned.
+func CrashReports(conn *sqlite.Conn, caveID string, limit int64) []*CrashReport {
+	var cond builder.Cond = builder.NewCond()
+	if caveID != "" {
+		cond = builder.Eq{"cave_id": caveID}
+	}
+
+	search := hades.Search{}.OrderBy("created_at DESC")
+	if limit > 0 {
+		search = search.Limit(limit)
+	}
+
+	var crs []*CrashReport
+	MustSelect(conn, &crs, cond, search)
+	return crs
+}
+
+func (cr *CrashReport) Save(conn *sqlite.Conn) {
+	MustSave(conn, cr)
+}
@@ -45,6 +45,12 @@ type Download struct {
 
 	Discarded bool `json:"discarded"`
 	Fresh     bool `json:"fresh"`
+	// Paused downloads are kept, but not performed
+	Paused bool `json:"paused"`
+	// Bandwidth cap in kbps, 0 if none
+	BandwidthCap int64 `json:"bandwidthCap"`
+	// Free space needed to perform the download, in bytes, 0 if unknown
+	NeededFreeSpace int64 `json:"neededFreeSpace"`
 }
 
 func AllDownloads(conn *sqlite.Conn) []*Download {
@@ -32,6 +32,18 @@ func (il *InstallLocation) GetStagingFolder(installID string) string {
 	return filepath.Join(il.Path, "downloads", installID)
 }
 
+// GetSaveBackupFolder returns where save snapshots of a cave are kept.
+// It's outside of the cave's install folder so they survive uninstalls.
+func (il *InstallLocation) GetSaveBackupFolder(caveID string) string {
+	return filepath.Join(il.Path, "save-backups", caveID)
+}
+
+// GetPreviousVersionsFolder returns where the files of builds replaced
+// by patches are kept, so that a cave can be rolled back.
+func (il *InstallLocation) GetPreviousVersionsFolder(caveID string) string {
+	return filepath.Join(il.Path, "previous-versions", caveID)
+}
+
 func (il *InstallLocation) GetCaves(conn *sqlite.Conn) []*Cave {
 	MustPreload(conn, il,
 		hades.Assoc("Caves"),
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"encoding/json"
+
+	"crawshaw.io/sqlite"
+	"github.com/go-xorm/builder"
+)
+
+// LaunchOptions are overrides set by the player for a single cave
+type LaunchOptions struct {
+	CaveID string `json:"caveId" hades:"primary_key"`
+
+	// JSON-encoded []string, appended to the manifest action's arguments
+	Args JSON `json:"args"`
+
+	// JSON-encoded map[string]string, merged over the manifest action's environment
+	Env JSON `json:"env"`
+
+	// Index into the manifest actions listed for this host. If set,
+	// the player isn't asked to pick one.
+	ManifestActionIndex *int64 `json:"manifestActionIndex"`
+
+	// Path of the verdict candidate to launch, relative to the install folder
+	CandidatePath string `json:"candidatePath"`
+
+	// If set, forces the sandbox on or off
+	Sandbox *bool `json:"sandbox"`
+}
+
+func LaunchOptionsByCaveID(conn *sqlite.Conn, caveID string) *LaunchOptions {
+	var lo LaunchOptions
+	if MustSelectOne(conn, &lo, builder.Eq{"cave_id": caveID}) {
+		return &lo
+	}
+	return nil
+}
+
+func DeleteLaunchOptions(conn *sqlite.Conn, caveID string) {
+	MustDelete(conn, &LaunchOptions{}, builder.Eq{"cave_id": caveID})
+}
+
+func (lo *LaunchOptions) GetArgs() []string {
+	var args []string
+	if lo.Args != "" {
+		Must(json.Unmarshal([]byte(lo.Args), &args))
+	}
+	return args
+}
+
+func (lo *LaunchOptions) SetArgs(args []string) {
+	contents, err := json.Marshal(args)
+	Must(err)
+	lo.Args = JSON(contents)
+}
+
+func (lo *LaunchOptions) GetEnv() map[string]string {
+	var env map[string]string
+	if lo.Env != "" {
+		Must(json.Unmarshal([]byte(lo.Env), &env))
+	}
+	return env
+}
+
+func (lo *LaunchOptions) SetEnv(env map[string]string) {
+	contents, err := json.Marshal(env)
+	Must(err)
+	lo.Env = JSON(contents)
+}
+
+func (lo *LaunchOptions) Save(conn *sqlite.Conn) {
+	MustSave(conn, lo)
+}
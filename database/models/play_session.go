@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"crawshaw.io/sqlite"
+	"github.com/go-xorm/builder"
+	"github.com/itchio/hades"
+)
+
+// A PlaySession is recorded every time a cave is launched
+type PlaySession struct {
+	// An UUID, same as the launch ID
+	ID string `json:"id" hades:"primary_key"`
+
+	CaveID string `json:"caveId"`
+	GameID int64  `json:"gameId"`
+
+	StartedAt  *time.Time `json:"startedAt"`
+	EndedAt    *time.Time `json:"endedAt"`
+	SecondsRun int64      `json:"secondsRun"`
+
+	// Only set for launch strategies that wait for a process
+	ExitCode *int64 `json:"exitCode"`
+
+	// "native", "html", "url" or "shell"
+	LaunchStrategy string `json:"launchStrategy"`
+
+	// Name of the manifest action that was launched, if any
+	ManifestAction string `json:"manifestAction"`
+
+	Crashed bool `json:"crashed"`
+}
+
+func PlaySessionByID(conn *sqlite.Conn, id string) *PlaySession {
+	var ps PlaySession
+	if MustSelectOne(conn, &ps, builder.Eq{"id": id}) {
+		return &ps
+	}
+	return nil
+}
+
+// PlaySessionsSince returns all sessions started after since, oldest first.
+// If caveID is non-empty, only sessions of that cave are returned.
+func PlaySessionsSince(conn *sqlite.Conn, caveID string, since time.Time) []*PlaySession {
+	var cond builder.Cond = builder.Gte{"started_at": since.UTC().Format(time.RFC3339Nano)}
+	if caveID != "" {
+		cond = builder.And(cond, builder.Eq{"cave_id": caveID})
+	}
+
+	var pss []*PlaySession
+	MustSelect(conn, &pss, cond, hades.Search{}.OrderBy("started_at ASC"))
+	return pss
+}
+
+func (ps *PlaySession) Start() {
+	startedAt := time.Now().UTC()
+	ps.StartedAt = &startedAt
+}
+
+func (ps *PlaySession) End() {
+	endedAt := time.Now().UTC()
+	ps.EndedAt = &endedAt
+}
+
+func (ps *PlaySession) Save(conn *sqlite.Conn) {
+	MustSave(conn, ps)
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"encoding/json"
+
+	"crawshaw.io/sqlite"
+	"github.com/go-xorm/builder"
+)
+
+// A Setting is a daemon-wide preference, stored as JSON
+type Setting struct {
+	Key   string `json:"key" hades:"primary_key"`
+	Value JSON   `json:"value"`
+}
+
+// GetSetting decodes the setting named key into dst.
+// Returns false if it was never set.
+func GetSetting(conn *sqlite.Conn, key string, dst interface{}) bool {
+	var s Setting
+	if !MustSelectOne(conn, &s, builder.Eq{"key": key}) {
+		return false
+	}
+	Must(json.Unmarshal([]byte(s.Value), dst))
+	return true
+}
+
+func SetSetting(conn *sqlite.Conn, key string, value interface{}) {
+	contents, err := json.Marshal(value)
+	Must(err)
+	MustSave(conn, &Setting{
+		Key:   key,
+		Value: JSON(contents),
+	})
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// A TransferRecord is appended to the transfer log every time
+// performing a download stops, whether it finished or not. Unlike
+// downloads, they're never cleared.
+type TransferRecord struct {
+	// An UUID
+	ID string `json:"id" hades:"primary_key"`
+
+	DownloadID string `json:"downloadId"`
+	CaveID     string `json:"caveId"`
+	GameID     int64  `json:"gameId"`
+	UploadID   int64  `json:"uploadId"`
+	BuildID    int64  `json:"buildId"`
+	Reason     string `json:"reason"`
+
+	// "finished", "errored" or "stopped"
+	Status string `json:"status"`
+
+	StartedAt  *time.Time `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt"`
+	// In seconds
+	Duration float64 `json:"duration"`
+
+	// Bytes received over the network
+	Bytes int64 `json:"bytes"`
+	// Bytes that didn't need to be received thanks to patching
+	BytesSaved int64 `json:"bytesSaved"`
+	// In bytes per second
+	AverageBPS float64 `json:"averageBps"`
+
+	// Standard butlerd error code, if errored
+	ErrorCode *int64 `json:"errorCode"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"crawshaw.io/sqlite"
+	"github.com/go-xorm/builder"
+)
+
+// An UpdateCheck records when a cave was last checked for updates,
+// along with what the server replied, so the next check can be
+// a conditional request.
+type UpdateCheck struct {
+	CaveID string `json:"caveId" hades:"primary_key"`
+
+	CheckedAt *time.Time `json:"checkedAt"`
+
+	// Game whose uploads were listed
+	GameID int64 `json:"gameId"`
+
+	// Entity tag of the last upload listing, if the server sent one
+	ETag string `json:"etag"`
+
+	// Body of the last upload listing, reused when the server
+	// replies that it wasn't modified
+	Body string `json:"body"`
+}
+
+func UpdateCheckByCaveID(conn *sqlite.Conn, caveID string) *UpdateCheck {
+	var uc UpdateCheck
+	if MustSelectOne(conn, &uc, builder.Eq{"cave_id": caveID}) {
+		return &uc
+	}
+	return nil
+}
+
+func DeleteUpdateCheck(conn *sqlite.Conn, caveID string) {
+	MustDelete(conn, &UpdateCheck{}, builder.Eq{"cave_id": caveID})
+}
+
+func (uc *UpdateCheck) Save(conn *sqlite.Conn) {
+	MustSave(conn, uc)
+}
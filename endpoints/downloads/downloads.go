@@ -14,4 +14,9 @@ func Register(router *butlerd.Router) {
 	messages.DownloadsClearFinished.Register(router, DownloadsClearFinished)
 	messages.DownloadsDiscard.Register(router, DownloadsDiscard)
 	messages.DownloadsRetry.Register(router, DownloadsRetry)
+	messages.DownloadsSetConcurrency.Register(router, DownloadsSetConcurrency)
+	messages.DownloadsPause.Register(router, DownloadsPause)
+	messages.DownloadsResume.Register(router, DownloadsResume)
+	messages.DownloadsSetBandwidthCap.Register(router, DownloadsSetBandwidthCap)
+	messages.DownloadsHistory.Register(router, DownloadsHistory)
 }
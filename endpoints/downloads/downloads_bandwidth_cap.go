@@ -0,0 +1,22 @@
+package downloads
+
+import (
+	"crawshaw.io/sqlite"
+	"github.com/go-xorm/builder"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/database/models"
+	"github.com/itchio/hades"
+)
+
+func DownloadsSetBandwidthCap(rc *butlerd.RequestContext, params butlerd.DownloadsSetBandwidthCapParams) (*butlerd.DownloadsSetBandwidthCapResult, error) {
+	rc.WithConn(func(conn *sqlite.Conn) {
+		download := ValidateDownload(conn, params.DownloadID)
+		models.MustUpdate(conn, &models.Download{},
+			hades.Where(builder.Eq{"id": download.ID}),
+			builder.Eq{"bandwidth_cap": params.Rate},
+		)
+	})
+
+	res := &butlerd.DownloadsSetBandwidthCapResult{}
+	return res, nil
+}
@@ -0,0 +1,54 @@
+package downloads
+
+import (
+	"crawshaw.io/sqlite"
+	"github.com/go-xorm/builder"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/database/models"
+	"github.com/itchio/hades"
+)
+
+const concurrencySetting = "downloads_concurrency"
+
+func DownloadsSetConcurrency(rc *butlerd.RequestContext, params butlerd.DownloadsSetConcurrencyParams) (*butlerd.DownloadsSetConcurrencyResult, error) {
+	rc.WithConn(func(conn *sqlite.Conn) {
+		models.SetSetting(conn, concurrencySetting, params.Concurrency)
+	})
+	rc.Consumer.Statf("Now performing up to %d downloads at a time", params.Concurrency)
+
+	res := &butlerd.DownloadsSetConcurrencyResult{}
+	return res, nil
+}
+
+// downloadsConcurrency returns how many downloads can be performed at once
+func downloadsConcurrency(conn *sqlite.Conn) int64 {
+	var concurrency int64 = 1
+	models.GetSetting(conn, concurrencySetting, &concurrency)
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return concurrency
+}
+
+func pendingDownloadsCond() builder.Cond {
+	return builder.And(
+		builder.IsNull{"finished_at"},
+		builder.Not{builder.Expr("discarded")},
+	)
+}
+
+// queuedDownloads returns the pending downloads that aren't paused,
+// lowest positions first. The driver performs the first of them that
+// can proceed, up to the concurrency setting, see driver.fill.
+func queuedDownloads(conn *sqlite.Conn) []*models.Download {
+	var downloads []*models.Download
+	models.MustSelect(conn, &downloads,
+		builder.And(
+			pendingDownloadsCond(),
+			// downloads queued before pausing existed have a NULL paused column
+			builder.Or(builder.IsNull{"paused"}, builder.Not{builder.Expr("paused")}),
+		),
+		hades.Search{}.OrderBy("position ASC"),
+	)
+	return downloads
+}
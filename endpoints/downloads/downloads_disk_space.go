@@ -0,0 +1,198 @@
+package downloads
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/butlerd/messages"
+	"github.com/itchio/butler/cmd/operate"
+	"github.com/itchio/butler/cmd/sizeof"
+	"github.com/itchio/butler/database/models"
+	"github.com/itchio/butler/endpoints/system"
+	"github.com/itchio/httpkit/progress"
+)
+
+// diskSpaceMargin is kept free on top of what downloads
+// are estimated to need, since estimates can be off.
+const diskSpaceMargin int64 = 256 * 1024 * 1024
+
+// statFS is swapped out in tests
+var statFS = system.StatFS
+
+// diskSpace keeps track of how much free space a download needs
+type diskSpace struct {
+	// the install folder, or the install location if it
+	// doesn't exist yet
+	path string
+	need int64
+
+	lock      sync.Mutex
+	lastFree  int64
+	startFree int64
+}
+
+func newDiskSpace(conn *sqlite.Conn, download *models.Download) *diskSpace {
+	ds := &diskSpace{
+		path:      download.InstallFolder,
+		need:      estimateNeededSpace(download),
+		startFree: -1,
+	}
+	if ds.path == "" {
+		if il := models.InstallLocationByID(conn, download.InstallLocationID); il != nil {
+			ds.path = il.Path
+		}
+	}
+
+	// whatever was downloaded or extracted by a previous
+	// attempt doesn't need to be done again.
+	if download.StagingFolder != "" {
+		size, _ := sizeof.Do(download.StagingFolder)
+		ds.need -= size
+	}
+	if download.Fresh && download.InstallFolder != "" {
+		size, _ := sizeof.Do(download.InstallFolder)
+		ds.need -= size
+	}
+	if ds.need < 0 {
+		ds.need = 0
+	}
+	return ds
+}
+
+// estimateNeededSpace returns how many bytes download will
+// take up on disk, including its install source.
+func estimateNeededSpace(download *models.Download) int64 {
+	if download.NeededFreeSpace > 0 {
+		return download.NeededFreeSpace
+	}
+
+	var size int64
+	if download.Upload != nil {
+		size = download.Upload.Size
+	}
+	if download.Fresh {
+		return size + operate.GuessInstallSize(size)
+	}
+	// installing over an existing folder, most files are probably there
+	return size
+}
+
+// check returns the free space at the download's install location, and
+// how much of it is needed for the download to proceed, margin included.
+func (ds *diskSpace) check() (free int64, needed int64, err error) {
+	stats, err := statFS(existingParent(ds.path))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+
+	free = stats.FreeSize
+	ds.lastFree = free
+
+	needed = ds.need
+	if ds.startFree >= 0 {
+		// we've used up some of it since we started
+		if used := ds.startFree - free; used > 0 {
+			needed -= used
+		}
+		if needed < 0 {
+			needed = 0
+		}
+	}
+	return free, needed + diskSpaceMargin, nil
+}
+
+// start marks the download as started with the free
+// space found by the last check.
+func (ds *diskSpace) start() {
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+	ds.startFree = ds.lastFree
+}
+
+func existingParent(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// hasRoom returns true if there's enough free space left to perform
+// download. If there isn't, clients are notified, once until there
+// is enough space again.
+func (d *driver) hasRoom(download *models.Download, ds *diskSpace) bool {
+	consumer := d.rc.Consumer
+	if ds.path == "" {
+		// nowhere to check
+		return true
+	}
+
+	free, needed, err := ds.check()
+	if err != nil {
+		consumer.Warnf("Could not check free space for %s: %v", operate.GameToString(download.Game), err)
+		return true
+	}
+
+	d.lock.Lock()
+	wasLow := d.lowSpace[download.ID]
+	if free >= needed {
+		delete(d.lowSpace, download.ID)
+	} else {
+		d.lowSpace[download.ID] = true
+	}
+	d.lock.Unlock()
+
+	if free >= needed {
+		if wasLow {
+			consumer.Infof("Enough free space for %s again (%s available), resuming", operate.GameToString(download.Game), progress.FormatBytes(free))
+		}
+		return true
+	}
+
+	if !wasLow {
+		consumer.Warnf("Not enough free space for %s (%s available, %s needed), waiting",
+			operate.GameToString(download.Game),
+			progress.FormatBytes(free),
+			progress.FormatBytes(needed),
+		)
+		messages.DownloadsDriveDiskSpaceLow.Notify(d.rc, butlerd.DownloadsDriveDiskSpaceLowNotification{
+			Download:   formatDownload(download),
+			FreeSize:   free,
+			NeededSize: needed,
+		})
+	}
+	return false
+}
+
+// forgetDeferred stops tracking downloads that aren't queued anymore,
+// because they were paused, discarded, or are done.
+func (d *driver) forgetDeferred(queued []*models.Download) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	isQueued := make(map[string]bool)
+	for _, download := range queued {
+		isQueued[download.ID] = true
+	}
+	for downloadID := range d.lowSpace {
+		if !isQueued[downloadID] {
+			delete(d.lowSpace, downloadID)
+		}
+	}
+	for downloadID := range d.running {
+		if !isQueued[downloadID] {
+			delete(d.running, downloadID)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package downloads
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/butlerd/butlerdtest"
+	"github.com/itchio/butler/butlerd/messages"
+	"github.com/itchio/butler/cmd/sizeof"
+	"github.com/itchio/butler/database/models"
+	itchio "github.com/itchio/go-itchio"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeStatFS swaps out statFS for one that reports free bytes
+// free, and the last path it was called with in path.
+type fakeStatFS struct {
+	free int64
+	err  error
+	path string
+}
+
+func (fs *fakeStatFS) swap() func() {
+	orig := statFS
+	statFS = func(path string) (*butlerd.SystemStatFSResult, error) {
+		fs.path = path
+		if fs.err != nil {
+			return nil, fs.err
+		}
+		return &butlerd.SystemStatFSResult{FreeSize: fs.free}, nil
+	}
+	return func() {
+		statFS = orig
+	}
+}
+
+func Test_NewDiskSpace(t *testing.T) {
+	h := butlerdtest.New(t)
+	defer h.Close()
+
+	staging := filepath.Join(h.Dir, "staging")
+	assert.NoError(t, os.MkdirAll(staging, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(staging, "archive.zip"), make([]byte, 300), 0644))
+	stagingSize, _ := sizeof.Do(staging)
+
+	h.RC.WithConn(func(conn *sqlite.Conn) {
+		models.MustSave(conn, &models.InstallLocation{ID: "il", Path: h.Dir})
+
+		ds := newDiskSpace(conn, &models.Download{
+			InstallLocationID: "il",
+			Fresh:             true,
+			Upload:            &itchio.Upload{Size: 1000},
+		})
+		assert.EqualValues(t, h.Dir, ds.path, "fresh installs are checked at their install location")
+		assert.EqualValues(t, 2300, ds.need, "fresh installs need room for the install source and what it extracts to")
+
+		ds = newDiskSpace(conn, &models.Download{
+			InstallLocationID: "il",
+			Fresh:             true,
+			Upload:            &itchio.Upload{Size: 1000},
+			NeededFreeSpace:   5000,
+			StagingFolder:     staging,
+		})
+		assert.EqualValues(t, 5000-stagingSize, ds.need, "what previous attempts downloaded doesn't count")
+
+		ds = newDiskSpace(conn, &models.Download{
+			InstallFolder:     filepath.Join(h.Dir, "garden"),
+			InstallLocationID: "il",
+			Upload:            &itchio.Upload{Size: 1000},
+		})
+		assert.EqualValues(t, filepath.Join(h.Dir, "garden"), ds.path)
+		assert.EqualValues(t, 1000, ds.need, "updates only need room for the install source")
+
+		ds = newDiskSpace(conn, &models.Download{
+			InstallLocationID: "il",
+			NeededFreeSpace:   100,
+			StagingFolder:     staging,
+		})
+		assert.EqualValues(t, 0, ds.need)
+	})
+}
+
+func Test_DiskSpaceCheck(t *testing.T) {
+	fs := &fakeStatFS{free: 5000}
+	defer fs.swap()()
+
+	dir, err := ioutil.TempDir("", "disk-space")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ds := &diskSpace{path: filepath.Join(dir, "not", "there", "yet"), need: 1000, startFree: -1}
+
+	free, needed, err := ds.check()
+	assert.NoError(t, err)
+	assert.EqualValues(t, dir, fs.path, "the closest existing parent should be checked")
+	assert.EqualValues(t, 5000, free)
+	assert.EqualValues(t, 1000+diskSpaceMargin, needed)
+
+	// what the download used up since it started doesn't need to be free anymore
+	ds.start()
+	fs.free = 4600
+	_, needed, err = ds.check()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 600+diskSpaceMargin, needed)
+
+	fs.free = 10
+	_, needed, err = ds.check()
+	assert.NoError(t, err)
+	assert.EqualValues(t, diskSpaceMargin, needed)
+
+	fs.err = errors.New("no such device")
+	_, _, err = ds.check()
+	assert.Error(t, err)
+}
+
+func Test_HasRoom(t *testing.T) {
+	h := butlerdtest.New(t)
+	defer h.Close()
+
+	fs := &fakeStatFS{free: diskSpaceMargin + 1000}
+	defer fs.swap()()
+
+	var lowNotifications int
+	h.Conn.OnNotification(messages.DownloadsDriveDiskSpaceLow.Method(), func(ctx context.Context, method string, params interface{}) error {
+		lowNotifications++
+		return nil
+	})
+
+	d := newDriver(h.RC, context.Background())
+	download := &models.Download{ID: "dl"}
+	ds := &diskSpace{path: h.Dir, need: 1000, startFree: -1}
+
+	assert.True(t, d.hasRoom(download, ds))
+	assert.True(t, d.hasRoom(download, &diskSpace{need: 1 << 50, startFree: -1}), "downloads with nowhere to check shouldn't wait")
+
+	fs.free = 999
+	assert.False(t, d.hasRoom(download, ds))
+	assert.False(t, d.hasRoom(download, ds))
+	assert.EqualValues(t, 1, lowNotifications, "clients should only be notified once")
+	assert.True(t, d.lowSpace["dl"])
+
+	d.forgetDeferred([]*models.Download{download})
+	assert.True(t, d.lowSpace["dl"], "queued downloads should stay tracked")
+	d.forgetDeferred(nil)
+	assert.False(t, d.lowSpace["dl"], "downloads that aren't queued anymore should be forgotten")
+
+	assert.False(t, d.hasRoom(download, ds))
+	fs.free = diskSpaceMargin + 1000
+	assert.True(t, d.hasRoom(download, ds))
+	assert.Empty(t, d.lowSpace)
+	assert.EqualValues(t, 2, lowNotifications)
+
+	fs.err = errors.New("no such device")
+	assert.True(t, d.hasRoom(download, ds), "downloads shouldn't wait if free space can't be checked")
+}
+
+func Test_DriveSkipsLowSpace(t *testing.T) {
+	h := butlerdtest.New(t)
+	defer h.Close()
+
+	fp := newFakePerformer()
+	defer swapPerformer(fp)()
+
+	fs := &fakeStatFS{free: diskSpaceMargin + 500}
+	defer fs.swap()()
+
+	h.RC.WithConn(func(conn *sqlite.Conn) {
+		models.MustSave(conn, &models.InstallLocation{ID: "il", Path: h.Dir})
+		models.MustSave(conn, &models.Download{
+			ID:                "big",
+			Position:          0,
+			InstallLocationID: "il",
+			NeededFreeSpace:   1000,
+		})
+		models.MustSave(conn, &models.Download{
+			ID:                "small",
+			Position:          1,
+			InstallLocationID: "il",
+			NeededFreeSpace:   100,
+		})
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := newDriver(h.RC, ctx)
+	defer d.wg.Wait()
+	defer cancel()
+
+	d.fill()
+	assert.EqualValues(t, "small", receive(t, fp.started), "downloads that fit shouldn't wait for ones that don't")
+	assert.False(t, d.holdsSlot("big"))
+	assert.True(t, d.isDeferred("big"))
+
+	cancel()
+	assert.EqualValues(t, "small", receive(t, fp.stopped))
+}
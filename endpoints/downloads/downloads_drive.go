@@ -3,14 +3,12 @@ package downloads
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/itchio/wharf/werrors"
 
 	"github.com/itchio/httpkit/neterr"
-	"github.com/itchio/httpkit/timeout"
 
 	"github.com/sourcegraph/jsonrpc2"
 
@@ -19,6 +17,7 @@ import (
 	"github.com/itchio/butler/butlerd"
 	"github.com/itchio/butler/butlerd/messages"
 	"github.com/itchio/butler/cmd/operate"
+	"github.com/itchio/butler/cmd/operate/connectivity"
 	"github.com/itchio/butler/cmd/wipe"
 	"github.com/itchio/butler/database/models"
 
@@ -29,10 +28,205 @@ import (
 
 var downloadsDriveCancelID = "Downloads.Drive"
 
-const pingURL = "https://itch.io/static/ping.txt"
+// swapped out in tests
+var installPerform = operate.InstallPerform
+
+// how often downloads being performed check whether they
+// should stop, shortened in tests
+var watchInterval = 5 * time.Second
 
 type Status struct {
-	Online bool
+	Network butlerd.NetworkStatus
+}
+
+// driver keeps track of the downloads being performed by a
+// @@DownloadsDriveParams call
+type driver struct {
+	rc  *butlerd.RequestContext
+	ctx context.Context
+	wg  sync.WaitGroup
+
+	lock         sync.Mutex
+	active       map[string]bool
+	disconnected bool
+	// downloads that should be performed right now, see fill
+	slots map[string]bool
+	// downloads waiting for free space, see hasRoom
+	lowSpace map[string]bool
+	// updates waiting for their game to exit, see caveIdle
+	running map[string]bool
+
+	// only accessed from the polling loop
+	paused map[string]bool
+}
+
+func newDriver(rc *butlerd.RequestContext, ctx context.Context) *driver {
+	return &driver{
+		rc:       rc,
+		ctx:      ctx,
+		active:   make(map[string]bool),
+		slots:    make(map[string]bool),
+		lowSpace: make(map[string]bool),
+		running:  make(map[string]bool),
+		paused:   make(map[string]bool),
+	}
+}
+
+func (d *driver) isActive(downloadID string) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.active[downloadID]
+}
+
+// holdsSlot returns true if downloadID was picked by the last fill
+func (d *driver) holdsSlot(downloadID string) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.slots[downloadID]
+}
+
+// isDeferred returns true if downloadID is waiting for disk space,
+// or for its game to exit.
+func (d *driver) isDeferred(downloadID string) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.lowSpace[downloadID] || d.running[downloadID]
+}
+
+// fill picks which downloads hold a slot: the queued ones with the
+// lowest positions, up to the concurrency setting. Downloads that
+// can't proceed right now, because there isn't enough disk space or
+// because their game is running, are skipped so they don't keep
+// others from starting. Picked downloads that aren't being performed
+// yet are started.
+func (d *driver) fill() {
+	var queued []*models.Download
+	var concurrency, numPending int64
+	d.rc.WithConn(func(conn *sqlite.Conn) {
+		queued = queuedDownloads(conn)
+		concurrency = downloadsConcurrency(conn)
+		numPending = models.MustCount(conn, &models.Download{}, pendingDownloadsCond())
+	})
+	d.forgetDeferred(queued)
+
+	type pendingStart struct {
+		download *models.Download
+		ds       *diskSpace
+	}
+	var starts []*pendingStart
+
+	slots := make(map[string]bool)
+	for _, download := range queued {
+		if int64(len(slots)) >= concurrency {
+			break
+		}
+
+		if d.isActive(download.ID) {
+			// stopping soon if it was deferred, see performOne
+			if !d.isDeferred(download.ID) {
+				slots[download.ID] = true
+			}
+			continue
+		}
+
+		if !d.caveIdle(download) {
+			continue
+		}
+
+		var ds *diskSpace
+		d.rc.WithConn(func(conn *sqlite.Conn) {
+			download.Preload(conn)
+			ds = newDiskSpace(conn, download)
+		})
+		if !d.hasRoom(download, ds) {
+			continue
+		}
+
+		slots[download.ID] = true
+		starts = append(starts, &pendingStart{download: download, ds: ds})
+	}
+
+	d.lock.Lock()
+	d.slots = slots
+	d.lock.Unlock()
+
+	for _, ps := range starts {
+		download, ds := ps.download, ps.ds
+		ds.start()
+
+		d.rc.Consumer.Infof("%d pending downloads, performing for %s", numPending, operate.GameToString(download.Game))
+
+		d.lock.Lock()
+		d.active[download.ID] = true
+		d.lock.Unlock()
+
+		d.wg.Add(1)
+		go func(download *models.Download) {
+			defer d.wg.Done()
+
+			hasRoom := func() bool {
+				return d.hasRoom(download, ds)
+			}
+			caveIdle := func() bool {
+				return d.caveIdle(download)
+			}
+			holdsSlot := func() bool {
+				return d.holdsSlot(download.ID)
+			}
+			err := performOne(d.ctx, d.rc, download, hasRoom, caveIdle, holdsSlot)
+
+			d.lock.Lock()
+			defer d.lock.Unlock()
+			delete(d.active, download.ID)
+			if err != nil {
+				if err == butlerd.CodeNetworkDisconnected {
+					d.disconnected = true
+				} else {
+					d.rc.Consumer.Warnf("%+v", errors.WithMessage(err, "while performing download:"))
+				}
+			}
+		}(download)
+	}
+}
+
+// notifyPaused lets clients know about downloads
+// that were paused since the last call
+func (d *driver) notifyPaused() {
+	var pausedDownloads []*models.Download
+	d.rc.WithConn(func(conn *sqlite.Conn) {
+		models.MustSelect(conn, &pausedDownloads,
+			builder.And(
+				pendingDownloadsCond(),
+				builder.Expr("paused"),
+			),
+			hades.Search{},
+		)
+		models.PreloadDownloads(conn, pausedDownloads)
+	})
+
+	paused := make(map[string]bool)
+	for _, download := range pausedDownloads {
+		paused[download.ID] = true
+		if d.paused[download.ID] {
+			continue
+		}
+
+		d.rc.Consumer.Infof("Download for %s is paused, skipping it", operate.GameToString(download.Game))
+		messages.DownloadsDrivePaused.Notify(d.rc, butlerd.DownloadsDrivePausedNotification{
+			Download: formatDownload(download),
+		})
+	}
+	d.paused = paused
+}
+
+// takeDisconnected returns true if a download stopped because
+// we went offline since the last call
+func (d *driver) takeDisconnected() bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	disconnected := d.disconnected
+	d.disconnected = false
+	return disconnected
 }
 
 func DownloadsDrive(rc *butlerd.RequestContext, params butlerd.DownloadsDriveParams) (*butlerd.DownloadsDriveResult, error) {
@@ -46,9 +240,13 @@ func DownloadsDrive(rc *butlerd.RequestContext, params butlerd.DownloadsDrivePar
 	defer rc.CancelFuncs.Remove(downloadsDriveCancelID)
 
 	status := &Status{
-		Online: true,
+		Network: butlerd.NetworkStatusOnline,
 	}
 
+	d := newDriver(rc, ctx)
+	// downloads stop when ctx is cancelled, wait for them to do so
+	defer d.wg.Wait()
+
 poll:
 	for {
 		select {
@@ -59,23 +257,18 @@ poll:
 			// let's keep going
 		}
 
-		err := cleanDiscarded(rc)
+		err := cleanDiscarded(rc, d.isActive)
 		if err != nil {
 			consumer.Warnf("%+v", errors.WithMessage(err, "while cleaning discarded:"))
 		}
 
-		err = performOne(ctx, rc)
-		if err != nil {
-			if err == butlerd.CodeNetworkDisconnected {
-				err = waitForInternet(rc, status)
-				if err != nil {
-					consumer.Warnf("%+v", errors.WithMessage(err, "while waiting for internet:"))
-				}
-			} else {
-				consumer.Warnf("%+v", errors.WithMessage(err, "while performing download:"))
-			}
+		if d.takeDisconnected() {
+			d.waitForInternet(status)
 		}
 
+		d.notifyPaused()
+		d.fill()
+
 		time.Sleep(1 * time.Second)
 	}
 
@@ -83,46 +276,66 @@ poll:
 	return res, nil
 }
 
-func waitForInternet(rc *butlerd.RequestContext, status *Status) error {
+// waitForInternet probes connectivity until we're back online,
+// or until driving is cancelled.
+func (d *driver) waitForInternet(status *Status) {
+	rc := d.rc
 	consumer := rc.Consumer
 
-	// notify always, but only log once
-	messages.DownloadsDriveNetworkStatus.Notify(rc, butlerd.DownloadsDriveNetworkStatusNotification{
-		Status: butlerd.NetworkStatusOffline,
-	})
-	if status.Online {
-		status.Online = false
-		consumer.Opf("Looks like we're offline! Waiting for an internet connection...")
+	// notify always, but only log changes
+	notified := false
+	setStatus := func(network butlerd.NetworkStatus) {
+		if !notified || network != status.Network {
+			messages.DownloadsDriveNetworkStatus.Notify(rc, butlerd.DownloadsDriveNetworkStatusNotification{
+				Status: network,
+			})
+			notified = true
+		}
+		if network == status.Network {
+			return
+		}
+		status.Network = network
+
+		switch network {
+		case butlerd.NetworkStatusOffline:
+			consumer.Opf("Looks like we're offline! Waiting for an internet connection...")
+		case butlerd.NetworkStatusCaptive:
+			consumer.Opf("Looks like we're behind a captive portal! Waiting to get through...")
+		}
 	}
 
-	client := timeout.NewDefaultClient()
+	for {
+		// the probe may be changed while we wait
+		var probe *butlerd.ConnectivityProbe
+		rc.WithConn(func(conn *sqlite.Conn) {
+			probe = operate.ConnectivityProbe(conn)
+		})
 
-	// wait up to 120 rounds (2 minutes if tries take 0s,
-	// which they don't), then give up waiting
-	for i := 0; i < 120; i++ {
-		res, err := client.Get(pingURL)
-		if err != nil {
-			if neterr.IsNetworkError(err) {
-				// keep going...
-			} else {
-				consumer.Warnf("Got non-network error while pinging: %+v", err)
-			}
-		} else {
-			payload, _ := ioutil.ReadAll(res.Body)
-			consumer.Statf("Looks like we're back online! (%s)", strings.TrimSpace(string(payload)))
+		res := connectivity.Check(d.ctx, probe)
+		if res.Status == butlerd.NetworkStatusOnline {
+			consumer.Statf("Looks like we're back online! (%s)", res.Body)
+			status.Network = butlerd.NetworkStatusOnline
 			messages.DownloadsDriveNetworkStatus.Notify(rc, butlerd.DownloadsDriveNetworkStatusNotification{
 				Status: butlerd.NetworkStatusOnline,
 			})
-			status.Online = true
-			return nil
+			return
 		}
 
-		time.Sleep(1 * time.Second)
+		if res.Err != nil && res.Status == butlerd.NetworkStatusOffline && !neterr.IsNetworkError(res.Err) {
+			consumer.Warnf("Got non-network error while probing connectivity: %+v", res.Err)
+		}
+		setStatus(res.Status)
+
+		select {
+		case <-time.After(connectivity.Seconds(probe.Interval)):
+			// try again
+		case <-d.ctx.Done():
+			return
+		}
 	}
-	return nil
 }
 
-func cleanDiscarded(rc *butlerd.RequestContext) error {
+func cleanDiscarded(rc *butlerd.RequestContext, isActive func(downloadID string) bool) error {
 	consumer := rc.Consumer
 
 	var discardedDownloads []*models.Download
@@ -131,6 +344,11 @@ func cleanDiscarded(rc *butlerd.RequestContext) error {
 		models.PreloadDownloads(conn, discardedDownloads)
 	})
 	for _, download := range discardedDownloads {
+		if isActive(download.ID) {
+			// still stopping, we'll clean it up once it's done
+			continue
+		}
+
 		consumer.Opf("Cleaning up download for %s", operate.GameToString(download.Game))
 
 		if download.StagingFolder == "" {
@@ -166,43 +384,30 @@ func cleanDiscarded(rc *butlerd.RequestContext) error {
 	return nil
 }
 
-func performOne(parentCtx context.Context, rc *butlerd.RequestContext) error {
-	consumer := rc.Consumer
-
-	var pendingDownloads []*models.Download
-	var download *models.Download
-	rc.WithConn(func(conn *sqlite.Conn) {
-		models.MustSelect(conn, &pendingDownloads,
-			builder.And(
-				builder.IsNull{"finished_at"},
-				builder.Not{builder.Expr("discarded")},
-			),
-			hades.Search{}.OrderBy("position ASC"),
-		)
-		if len(pendingDownloads) == 0 {
-			return
-		}
-
-		download = pendingDownloads[0]
-		download.Preload(conn)
-	})
-	if download == nil {
-		return nil
-	}
-	consumer.Infof("%d pending downloads, performing for %s", len(pendingDownloads), operate.GameToString(download.Game))
-
+// performOne performs download until it's done, or until it's stopped because
+// it was discarded or paused, or because hasRoom, caveIdle or holdsSlot
+// returned false.
+func performOne(parentCtx context.Context, parentRC *butlerd.RequestContext, download *models.Download, hasRoom func() bool, caveIdle func() bool, holdsSlot func() bool) error {
 	ctx, cancelFunc := context.WithCancel(parentCtx)
 	defer cancelFunc()
 
+	// other downloads may be performed at the same time, so
+	// track progress and intercept notifications separately.
+	// The database is accessed through parentRC, since rc's
+	// context is done once the download stops.
+	rc := parentRC.Fork(ctx)
+	consumer := rc.Consumer
+
 	wasDiscarded := func() bool {
-		// have we been discarded?
+		// have we been discarded or paused?
 		{
-			var discarded bool
-			rc.WithConn(func(conn *sqlite.Conn) {
+			var discarded, paused bool
+			parentRC.WithConn(func(conn *sqlite.Conn) {
 				models.MustExec(conn,
-					builder.Select("discarded").From("downloads").Where(builder.Eq{"id": download.ID}),
+					builder.Select("discarded", "paused").From("downloads").Where(builder.Eq{"id": download.ID}),
 					func(stmt *sqlite.Stmt) error {
 						discarded = stmt.ColumnInt(0) == 1
+						paused = stmt.ColumnInt(1) == 1
 						return nil
 					},
 				)
@@ -211,37 +416,40 @@ func performOne(parentCtx context.Context, rc *butlerd.RequestContext) error {
 				consumer.Infof("Download was cancelled from under us, bailing out!")
 				return true
 			}
-		}
-
-		// has something else been prioritized?
-		{
-			var priorityDownloadID string
-			rc.WithConn(func(conn *sqlite.Conn) {
-				models.MustExecWithSearch(conn,
-					builder.Select("id").From("downloads").Where(
-						builder.And(
-							builder.IsNull{"finished_at"},
-							builder.Not{builder.Expr("discarded")},
-						),
-					),
-					hades.Search{}.Limit(1),
-					func(stmt *sqlite.Stmt) error {
-						priorityDownloadID = stmt.ColumnText(0)
-						return nil
-					},
-				)
-			})
-			if priorityDownloadID != download.ID {
-				consumer.Infof("%s deprioritized (for %s), bailing out!", download.ID, priorityDownloadID)
+			if paused {
+				// the staging folder is left as-is, so we can resume later
+				consumer.Infof("Download was paused, stopping it.")
 				return true
 			}
 		}
+
+		// are we running out of disk space?
+		if !hasRoom() {
+			// the staging folder is kept, we'll resume once there's room
+			consumer.Infof("Running low on disk space, stopping download.")
+			return true
+		}
+
+		// has the game been launched since?
+		if !caveIdle() {
+			// the staging folder is kept, we'll resume once it exits
+			consumer.Infof("Game is running, stopping update.")
+			return true
+		}
+
+		// have other downloads been prioritized over us?
+		if !holdsSlot() {
+			consumer.Infof("%s deprioritized, bailing out!", download.ID)
+			return true
+		}
 		return false
 	}
+	watcherDone := make(chan struct{})
 	goGadgetoDiscardWatcher := func() {
+		defer close(watcherDone)
 		for {
 			select {
-			case <-time.After(5 * time.Second):
+			case <-time.After(watchInterval):
 				if wasDiscarded() {
 					cancelFunc()
 				}
@@ -251,6 +459,10 @@ func performOne(parentCtx context.Context, rc *butlerd.RequestContext) error {
 		}
 	}
 	go goGadgetoDiscardWatcher()
+	defer func() {
+		cancelFunc()
+		<-watcherDone
+	}()
 
 	var stage = "prepare"
 	var progress, eta, bps float64
@@ -319,7 +531,7 @@ func performOne(parentCtx context.Context, rc *butlerd.RequestContext) error {
 			Download: formatDownload(download),
 		})
 
-		err = operate.InstallPerform(ctx, rc, butlerd.InstallPerformParams{
+		err = installPerform(ctx, rc, butlerd.InstallPerformParams{
 			ID:            download.ID,
 			StagingFolder: download.StagingFolder,
 		})
@@ -341,7 +553,7 @@ func performOne(parentCtx context.Context, rc *butlerd.RequestContext) error {
 				return nil
 			case butlerd.CodeOperationAborted:
 				consumer.Warnf("Download aborted, cleaning it out.")
-				rc.WithConn(func(conn *sqlite.Conn) {
+				parentRC.WithConn(func(conn *sqlite.Conn) {
 					models.MustDelete(conn, &models.Download{}, builder.Eq{"id": download.ID})
 				})
 				return nil
@@ -373,7 +585,7 @@ func performOne(parentCtx context.Context, rc *butlerd.RequestContext) error {
 
 		finishedAt := time.Now().UTC()
 		download.FinishedAt = &finishedAt
-		rc.WithConn(download.Save)
+		parentRC.WithConn(download.Save)
 
 		messages.DownloadsDriveErrored.Notify(rc, butlerd.DownloadsDriveErroredNotification{
 			Download: formatDownload(download),
@@ -385,7 +597,7 @@ func performOne(parentCtx context.Context, rc *butlerd.RequestContext) error {
 	consumer.Infof("Download finished!")
 	finishedAt := time.Now().UTC()
 	download.FinishedAt = &finishedAt
-	rc.WithConn(download.Save)
+	parentRC.WithConn(download.Save)
 
 	messages.DownloadsDriveFinished.Notify(rc, butlerd.DownloadsDriveFinishedNotification{
 		Download: formatDownload(download),
@@ -0,0 +1,229 @@
+package downloads
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/butlerd/butlerdtest"
+	"github.com/itchio/butler/butlerd/messages"
+	"github.com/itchio/butler/cmd/operate"
+	"github.com/itchio/butler/database/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePerformer stands in for operate.InstallPerform: downloads
+// run until they're stopped.
+type fakePerformer struct {
+	started chan string
+	stopped chan string
+}
+
+func newFakePerformer() *fakePerformer {
+	return &fakePerformer{
+		started: make(chan string, 16),
+		stopped: make(chan string, 16),
+	}
+}
+
+func (fp *fakePerformer) perform(ctx context.Context, rc *butlerd.RequestContext, params butlerd.InstallPerformParams) error {
+	fp.started <- params.ID
+	<-ctx.Done()
+	fp.stopped <- params.ID
+	return butlerd.CodeOperationCancelled
+}
+
+func receive(t *testing.T, c chan string) string {
+	select {
+	case id := <-c:
+		return id
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for download")
+		return ""
+	}
+}
+
+func waitInactive(t *testing.T, d *driver, downloadID string) {
+	for i := 0; i < 500; i++ {
+		if !d.isActive(downloadID) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("%s still active", downloadID)
+}
+
+func swapPerformer(fp *fakePerformer) func() {
+	origPerform, origInterval := installPerform, watchInterval
+	installPerform = fp.perform
+	watchInterval = 10 * time.Millisecond
+	return func() {
+		installPerform, watchInterval = origPerform, origInterval
+	}
+}
+
+func Test_DriveConcurrently(t *testing.T) {
+	h := butlerdtest.New(t)
+	defer h.Close()
+
+	fp := newFakePerformer()
+	defer swapPerformer(fp)()
+
+	h.RC.WithConn(func(conn *sqlite.Conn) {
+		for i, id := range []string{"first", "second", "third"} {
+			models.MustSave(conn, &models.Download{
+				ID:       id,
+				Position: int64(i + 1),
+				Reason:   string(butlerd.DownloadReasonInstall),
+			})
+		}
+	})
+
+	_, err := DownloadsSetConcurrency(h.RC, butlerd.DownloadsSetConcurrencyParams{Concurrency: 2})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := newDriver(h.RC, ctx)
+	defer d.wg.Wait()
+	defer cancel()
+
+	d.fill()
+	started := []string{receive(t, fp.started), receive(t, fp.started)}
+	sort.Strings(started)
+	assert.EqualValues(t, []string{"first", "second"}, started, "the two lowest positions should be performed")
+	assert.False(t, d.isActive("third"))
+
+	d.fill()
+	assert.Len(t, fp.started, 0, "active downloads shouldn't be started twice")
+
+	// cancelling one download leaves the other running
+	_, err = DownloadsDiscard(h.RC, butlerd.DownloadsDiscardParams{DownloadID: "first"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, "first", receive(t, fp.stopped))
+	waitInactive(t, d, "first")
+	assert.True(t, d.isActive("second"))
+
+	// its slot goes to the next download
+	d.fill()
+	assert.EqualValues(t, "third", receive(t, fp.started))
+
+	// lowering concurrency stops the highest positions
+	_, err = DownloadsSetConcurrency(h.RC, butlerd.DownloadsSetConcurrencyParams{Concurrency: 1})
+	assert.NoError(t, err)
+	d.fill()
+	assert.EqualValues(t, "third", receive(t, fp.stopped))
+	waitInactive(t, d, "third")
+	assert.True(t, d.isActive("second"))
+	assert.True(t, d.holdsSlot("second"))
+	assert.False(t, d.holdsSlot("third"))
+
+	cancel()
+	assert.EqualValues(t, "second", receive(t, fp.stopped))
+}
+
+func Test_DriveSkipsDeferred(t *testing.T) {
+	h := butlerdtest.New(t)
+	defer h.Close()
+
+	fp := newFakePerformer()
+	defer swapPerformer(fp)()
+
+	h.RC.WithConn(func(conn *sqlite.Conn) {
+		models.MustSave(conn, &models.Download{
+			ID:       "update",
+			CaveID:   "garden",
+			Position: 0,
+			Reason:   string(butlerd.DownloadReasonUpdate),
+		})
+		models.MustSave(conn, &models.Download{
+			ID:       "install",
+			Position: 1,
+			Reason:   string(butlerd.DownloadReasonInstall),
+		})
+	})
+	h.RC.RunningCaves.Add(&butlerd.RunningCave{LaunchID: "a", CaveID: "garden"})
+	defer h.RC.RunningCaves.Remove("a")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := newDriver(h.RC, ctx)
+	defer d.wg.Wait()
+	defer cancel()
+
+	// only one download at a time, but the update can't proceed
+	d.fill()
+	assert.EqualValues(t, "install", receive(t, fp.started), "deferred updates shouldn't hold a slot")
+	assert.False(t, d.isActive("update"))
+	assert.False(t, d.holdsSlot("update"))
+
+	d.fill()
+	assert.Len(t, fp.started, 0)
+	assert.True(t, d.holdsSlot("install"))
+
+	cancel()
+	assert.EqualValues(t, "install", receive(t, fp.stopped))
+}
+
+func Test_WaitForInternet(t *testing.T) {
+	h := butlerdtest.New(t)
+	defer h.Close()
+
+	statuses := make(chan butlerd.NetworkStatus, 16)
+	h.Conn.OnNotification(messages.DownloadsDriveNetworkStatus.Method(), func(ctx context.Context, method string, params interface{}) error {
+		statuses <- params.(butlerd.DownloadsDriveNetworkStatusNotification).Status
+		return nil
+	})
+
+	setProbe := func(url string) {
+		h.RC.WithConn(func(conn *sqlite.Conn) {
+			operate.SetConnectivityProbe(conn, &butlerd.ConnectivityProbe{
+				URLs:     []string{url},
+				Timeout:  1,
+				Interval: 0.1,
+			})
+		})
+	}
+	waitStatus := func() butlerd.NetworkStatus {
+		select {
+		case s := <-statuses:
+			return s
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for network status")
+			return ""
+		}
+	}
+
+	// stays offline until driving is cancelled
+	setProbe("http://127.0.0.1:1/ping.txt")
+	ctx, cancel := context.WithCancel(context.Background())
+	d := newDriver(h.RC, ctx)
+	status := &Status{Network: butlerd.NetworkStatusOnline}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.waitForInternet(status)
+	}()
+	assert.EqualValues(t, butlerd.NetworkStatusOffline, waitStatus())
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("waitForInternet should return once driving is cancelled")
+	}
+
+	// comes back once the probe answers
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("pong"))
+	}))
+	defer server.Close()
+	setProbe(server.URL)
+
+	d = newDriver(h.RC, context.Background())
+	d.waitForInternet(status)
+	assert.EqualValues(t, butlerd.NetworkStatusOnline, status.Network)
+}
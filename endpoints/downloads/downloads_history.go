@@ -0,0 +1,105 @@
+package downloads
+
+import (
+	"time"
+
+	"crawshaw.io/sqlite"
+	"github.com/go-xorm/builder"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/database/models"
+	"github.com/itchio/butler/endpoints/fetch/pager"
+	"github.com/itchio/hades"
+)
+
+const defaultHistoryDays = 28
+
+func DownloadsHistory(rc *butlerd.RequestContext, params butlerd.DownloadsHistoryParams) (*butlerd.DownloadsHistoryResult, error) {
+	days := params.Days
+	if days == 0 {
+		days = defaultHistoryDays
+	}
+	loc := time.FixedZone("", int(params.UTCOffset)*60)
+	since := startOfDay(time.Now().In(loc)).AddDate(0, 0, -int(days-1))
+
+	var cond builder.Cond = builder.Gte{"started_at": since.UTC().Format(time.RFC3339Nano)}
+	if params.GameID != 0 {
+		cond = builder.And(cond, builder.Eq{"game_id": params.GameID})
+	}
+	if params.Status != "" {
+		cond = builder.And(cond, builder.Eq{"status": string(params.Status)})
+	}
+
+	res := &butlerd.DownloadsHistoryResult{
+		Items: []*butlerd.TransferRecord{},
+	}
+	var records []*models.TransferRecord
+	rc.WithConn(func(conn *sqlite.Conn) {
+		var items []*models.TransferRecord
+		search := hades.Search{}.OrderBy("started_at DESC")
+		res.NextCursor = pager.New(params).Fetch(conn, &items, cond, search)
+		for _, r := range items {
+			res.Items = append(res.Items, formatTransferRecord(r))
+		}
+
+		models.MustSelect(conn, &records, cond, hades.Search{})
+	})
+	res.Daily = AggregateTransfers(records, since, time.Now().In(loc))
+
+	return res, nil
+}
+
+// AggregateTransfers sums records started between since and until
+// into daily buckets. Days start at midnight in since's location.
+func AggregateTransfers(records []*models.TransferRecord, since time.Time, until time.Time) []*butlerd.BandwidthBucket {
+	loc := since.Location()
+	daily := []*butlerd.BandwidthBucket{}
+
+	byStart := make(map[time.Time]*butlerd.BandwidthBucket)
+	for day := startOfDay(since); !day.After(until); day = day.AddDate(0, 0, 1) {
+		b := &butlerd.BandwidthBucket{Start: day}
+		daily = append(daily, b)
+		byStart[day] = b
+	}
+
+	for _, r := range records {
+		if r.StartedAt == nil {
+			continue
+		}
+		b := byStart[startOfDay(r.StartedAt.In(loc))]
+		if b == nil {
+			continue
+		}
+		b.Bytes += r.Bytes
+		b.BytesSaved += r.BytesSaved
+		b.Transfers++
+	}
+
+	return daily
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func formatTransferRecord(r *models.TransferRecord) *butlerd.TransferRecord {
+	return &butlerd.TransferRecord{
+		ID:         r.ID,
+		DownloadID: r.DownloadID,
+		CaveID:     r.CaveID,
+		GameID:     r.GameID,
+		UploadID:   r.UploadID,
+		BuildID:    r.BuildID,
+		Reason:     butlerd.DownloadReason(r.Reason),
+		Status:     butlerd.TransferStatus(r.Status),
+
+		StartedAt:  r.StartedAt,
+		FinishedAt: r.FinishedAt,
+		Duration:   r.Duration,
+
+		Bytes:      r.Bytes,
+		BytesSaved: r.BytesSaved,
+		AverageBPS: r.AverageBPS,
+
+		ErrorCode: r.ErrorCode,
+	}
+}
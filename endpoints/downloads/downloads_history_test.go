@@ -0,0 +1,101 @@
+package downloads_test
+
+import (
+	"testing"
+	"time"
+
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/butlerd/butlerdtest"
+	"github.com/itchio/butler/database/models"
+	"github.com/itchio/butler/endpoints/downloads"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AggregateTransfers(t *testing.T) {
+	at := func(s string) *time.Time {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			panic(err)
+		}
+		return &t
+	}
+
+	records := []*models.TransferRecord{
+		{StartedAt: at("2018-08-03T10:00:00Z"), Bytes: 1024},
+		{StartedAt: at("2018-08-03T23:30:00Z"), Bytes: 512, BytesSaved: 2048},
+		{StartedAt: at("2018-08-05T12:00:00Z"), Bytes: 64},
+		// before the window
+		{StartedAt: at("2018-07-30T12:00:00Z"), Bytes: 4096},
+	}
+
+	since := *at("2018-08-02T00:00:00Z")
+	until := *at("2018-08-05T18:00:00Z")
+	daily := downloads.AggregateTransfers(records, since, until)
+
+	assert.Len(t, daily, 4)
+	assert.EqualValues(t, 0, daily[0].Transfers)
+	assert.EqualValues(t, 2, daily[1].Transfers)
+	assert.EqualValues(t, 1536, daily[1].Bytes)
+	assert.EqualValues(t, 2048, daily[1].BytesSaved)
+	assert.EqualValues(t, 64, daily[3].Bytes)
+
+	// one hour ahead of UTC, the late transfer happens the next day
+	since = since.In(time.FixedZone("", 60*60))
+	daily = downloads.AggregateTransfers(records, since, until)
+	assert.EqualValues(t, 1024, daily[1].Bytes)
+	assert.EqualValues(t, 512, daily[2].Bytes)
+}
+
+func Test_DownloadsHistory(t *testing.T) {
+	h := butlerdtest.New(t)
+	defer h.Close()
+
+	now := time.Now().UTC()
+	daysAgo := func(days int) *time.Time {
+		t := now.AddDate(0, 0, -days)
+		return &t
+	}
+
+	h.RC.WithConn(func(conn *sqlite.Conn) {
+		for _, r := range []*models.TransferRecord{
+			{ID: "a", GameID: 1, Status: string(butlerd.TransferStatusFinished), StartedAt: daysAgo(0), Bytes: 100},
+			{ID: "b", GameID: 1, Status: string(butlerd.TransferStatusErrored), StartedAt: daysAgo(1), Bytes: 10},
+			{ID: "c", GameID: 2, Status: string(butlerd.TransferStatusFinished), StartedAt: daysAgo(2), Bytes: 1000},
+			{ID: "old", GameID: 1, Status: string(butlerd.TransferStatusFinished), StartedAt: daysAgo(60), Bytes: 5000},
+		} {
+			models.MustSave(conn, r)
+		}
+	})
+
+	ids := func(res *butlerd.DownloadsHistoryResult) []string {
+		var ids []string
+		for _, item := range res.Items {
+			ids = append(ids, item.ID)
+		}
+		return ids
+	}
+
+	res, err := downloads.DownloadsHistory(h.RC, butlerd.DownloadsHistoryParams{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"a", "b", "c"}, ids(res), "old transfers should be left out, newest first")
+	assert.Len(t, res.Daily, 28)
+
+	res, err = downloads.DownloadsHistory(h.RC, butlerd.DownloadsHistoryParams{GameID: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"a", "b"}, ids(res))
+
+	res, err = downloads.DownloadsHistory(h.RC, butlerd.DownloadsHistoryParams{Status: butlerd.TransferStatusFinished})
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"a", "c"}, ids(res))
+
+	var total int64
+	for _, b := range res.Daily {
+		total += b.Bytes
+	}
+	assert.EqualValues(t, 1100, total, "daily totals should follow filters")
+
+	res, err = downloads.DownloadsHistory(h.RC, butlerd.DownloadsHistoryParams{Days: 90})
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"a", "b", "c", "old"}, ids(res))
+}
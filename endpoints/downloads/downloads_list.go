@@ -39,5 +39,7 @@ func formatDownload(download *models.Download) *butlerd.Download {
 		FinishedAt:    download.FinishedAt,
 		StagingFolder: download.StagingFolder,
 		Reason:        butlerd.DownloadReason(download.Reason),
+		Paused:        download.Paused,
+		BandwidthCap:  download.BandwidthCap,
 	}
 }
@@ -0,0 +1,65 @@
+package downloads
+
+import (
+	"crawshaw.io/sqlite"
+	"github.com/go-xorm/builder"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/cmd/operate"
+	"github.com/itchio/butler/database/models"
+	"github.com/itchio/hades"
+	"github.com/pkg/errors"
+)
+
+func DownloadsPause(rc *butlerd.RequestContext, params butlerd.DownloadsPauseParams) (*butlerd.DownloadsPauseResult, error) {
+	consumer := rc.Consumer
+
+	var download *models.Download
+	rc.WithConn(func(conn *sqlite.Conn) {
+		download = ValidateDownload(conn, params.DownloadID)
+	})
+	if download.FinishedAt != nil {
+		return nil, errors.Errorf("Download already finished, can't pause")
+	}
+
+	if download.Paused {
+		consumer.Warnf("Download already paused")
+	} else {
+		rc.WithConn(func(conn *sqlite.Conn) {
+			setPaused(conn, download.ID, true)
+		})
+		consumer.Statf("Paused download for %s", operate.GameToString(download.Game))
+	}
+
+	res := &butlerd.DownloadsPauseResult{}
+	return res, nil
+}
+
+func DownloadsResume(rc *butlerd.RequestContext, params butlerd.DownloadsResumeParams) (*butlerd.DownloadsResumeResult, error) {
+	consumer := rc.Consumer
+
+	var download *models.Download
+	rc.WithConn(func(conn *sqlite.Conn) {
+		download = ValidateDownload(conn, params.DownloadID)
+	})
+
+	if !download.Paused {
+		consumer.Warnf("Download not paused, nothing to resume")
+	} else {
+		rc.WithConn(func(conn *sqlite.Conn) {
+			setPaused(conn, download.ID, false)
+		})
+		consumer.Statf("Resumed download for %s", operate.GameToString(download.Game))
+	}
+
+	res := &butlerd.DownloadsResumeResult{}
+	return res, nil
+}
+
+// setPaused only updates the paused column, so whatever
+// performing the download saves in the meantime is kept.
+func setPaused(conn *sqlite.Conn, downloadID string, paused bool) {
+	models.MustUpdate(conn, &models.Download{},
+		hades.Where(builder.Eq{"id": downloadID}),
+		builder.Eq{"paused": paused},
+	)
+}
@@ -0,0 +1,68 @@
+package downloads
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/butlerd/butlerdtest"
+	"github.com/itchio/butler/database/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PauseResume(t *testing.T) {
+	h := butlerdtest.New(t)
+	defer h.Close()
+
+	fp := newFakePerformer()
+	defer swapPerformer(fp)()
+
+	h.RC.WithConn(func(conn *sqlite.Conn) {
+		models.MustSave(conn, &models.Download{ID: "dl", Position: 1, Reason: string(butlerd.DownloadReasonInstall)})
+		models.MustSave(conn, &models.Download{ID: "done", Position: 2, FinishedAt: &time.Time{}})
+	})
+	download := func(id string) *models.Download {
+		var d *models.Download
+		h.RC.WithConn(func(conn *sqlite.Conn) {
+			d = models.DownloadByID(conn, id)
+		})
+		return d
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := newDriver(h.RC, ctx)
+	defer d.wg.Wait()
+	defer cancel()
+
+	d.fill()
+	assert.EqualValues(t, "dl", receive(t, fp.started))
+
+	_, err := DownloadsSetBandwidthCap(h.RC, butlerd.DownloadsSetBandwidthCapParams{DownloadID: "dl", Rate: 512})
+	assert.NoError(t, err)
+
+	_, err = DownloadsPause(h.RC, butlerd.DownloadsPauseParams{DownloadID: "dl"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, "dl", receive(t, fp.stopped), "pausing should stop the download")
+	waitInactive(t, d, "dl")
+
+	dl := download("dl")
+	assert.True(t, dl.Paused)
+	assert.EqualValues(t, 512, dl.BandwidthCap, "pausing should leave other columns alone")
+	assert.Nil(t, dl.FinishedAt, "paused downloads aren't finished")
+
+	d.fill()
+	assert.Len(t, fp.started, 0, "paused downloads shouldn't be performed")
+
+	_, err = DownloadsResume(h.RC, butlerd.DownloadsResumeParams{DownloadID: "dl"})
+	assert.NoError(t, err)
+	assert.False(t, download("dl").Paused)
+	assert.EqualValues(t, 512, download("dl").BandwidthCap)
+
+	d.fill()
+	assert.EqualValues(t, "dl", receive(t, fp.started), "resumed downloads should be performed again")
+
+	_, err = DownloadsPause(h.RC, butlerd.DownloadsPauseParams{DownloadID: "done"})
+	assert.Error(t, err, "finished downloads can't be paused")
+}
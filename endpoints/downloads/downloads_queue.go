@@ -73,6 +73,7 @@ func DownloadsQueue(rc *butlerd.RequestContext, params butlerd.DownloadsQueuePar
 		InstallFolder:     item.InstallFolder,
 		StagingFolder:     item.StagingFolder,
 		InstallLocationID: item.InstallLocationID,
+		NeededFreeSpace:   item.NeededFreeSpace,
 		StartedAt:         &startedAt,
 		Fresh:             Fresh,
 	}
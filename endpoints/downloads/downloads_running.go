@@ -0,0 +1,42 @@
+package downloads
+
+import (
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/cmd/operate"
+	"github.com/itchio/butler/database/models"
+)
+
+// caveIdle returns false if download is an update for a cave that's
+// running, so it isn't installed over a game that's being played.
+// Caves set to update when idle also wait for every game to exit.
+// Updates may have been queued before the game was launched, so this
+// is checked by the drive, not just when queuing.
+func (d *driver) caveIdle(download *models.Download) bool {
+	if download.Reason != string(butlerd.DownloadReasonUpdate) || download.CaveID == "" {
+		return true
+	}
+
+	busy := len(d.rc.RunningCaves.ByCaveID(download.CaveID)) > 0
+	if !busy && len(d.rc.RunningCaves.List()) > 0 {
+		var cave *models.Cave
+		d.rc.WithConn(func(conn *sqlite.Conn) {
+			cave = models.CaveByID(conn, download.CaveID)
+		})
+		busy = cave != nil && operate.CaveUpdatePolicy(cave) == butlerd.UpdatePolicyAutoWhenIdle
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if !busy {
+		delete(d.running, download.ID)
+		return true
+	}
+
+	if !d.running[download.ID] {
+		d.running[download.ID] = true
+		d.rc.Consumer.Infof("Deferring update for %s while it's running", operate.GameToString(download.Game))
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package downloads
+
+import (
+	"context"
+	"testing"
+
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/butlerd/butlerdtest"
+	"github.com/itchio/butler/database/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CaveIdle(t *testing.T) {
+	h := butlerdtest.New(t)
+	defer h.Close()
+
+	d := &driver{
+		rc:      h.RC,
+		running: make(map[string]bool),
+	}
+	h.RC.WithConn(func(conn *sqlite.Conn) {
+		models.MustSave(conn, &models.Cave{ID: "immediate", UpdatePolicy: string(butlerd.UpdatePolicyAutoImmediate)})
+		models.MustSave(conn, &models.Cave{ID: "idle", UpdatePolicy: string(butlerd.UpdatePolicyAutoWhenIdle)})
+	})
+
+	update := func(caveID string) *models.Download {
+		return &models.Download{ID: caveID + "-update", CaveID: caveID, Reason: string(butlerd.DownloadReasonUpdate)}
+	}
+	install := &models.Download{ID: "install", CaveID: "immediate", Reason: string(butlerd.DownloadReasonInstall)}
+
+	assert.True(t, d.caveIdle(update("immediate")))
+	assert.True(t, d.caveIdle(update("idle")))
+
+	h.RC.RunningCaves.Add(&butlerd.RunningCave{LaunchID: "a", CaveID: "immediate"})
+	assert.False(t, d.caveIdle(update("immediate")))
+	assert.True(t, d.caveIdle(install))
+	// another game is running, only when-idle updates wait
+	assert.False(t, d.caveIdle(update("idle")))
+
+	h.RC.RunningCaves.Remove("a")
+	h.RC.RunningCaves.Add(&butlerd.RunningCave{LaunchID: "b", CaveID: "other"})
+	assert.True(t, d.caveIdle(update("immediate")))
+	assert.False(t, d.caveIdle(update("idle")))
+
+	h.RC.RunningCaves.Remove("b")
+	assert.True(t, d.caveIdle(update("idle")))
+	assert.Empty(t, d.running)
+}
+
+func Test_DriveDeferredUpdates(t *testing.T) {
+	h := butlerdtest.New(t)
+	defer h.Close()
+
+	fp := newFakePerformer()
+	defer swapPerformer(fp)()
+
+	h.RC.WithConn(func(conn *sqlite.Conn) {
+		models.MustSave(conn, &models.Cave{ID: "idle", UpdatePolicy: string(butlerd.UpdatePolicyAutoWhenIdle)})
+		models.MustSave(conn, &models.Download{
+			ID:       "update",
+			CaveID:   "idle",
+			Position: 0,
+			Reason:   string(butlerd.DownloadReasonUpdate),
+		})
+		models.MustSave(conn, &models.Download{
+			ID:       "install",
+			Position: 1,
+			Reason:   string(butlerd.DownloadReasonInstall),
+		})
+	})
+
+	// any game running holds up when-idle updates
+	h.RC.RunningCaves.Add(&butlerd.RunningCave{LaunchID: "a", CaveID: "other"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := newDriver(h.RC, ctx)
+	defer d.wg.Wait()
+	defer cancel()
+
+	d.fill()
+	assert.EqualValues(t, "install", receive(t, fp.started), "the deferred update should release its slot")
+	assert.True(t, d.running["update"])
+
+	// once the game exits, the update gets its slot back
+	h.RC.RunningCaves.Remove("a")
+	d.fill()
+	assert.EqualValues(t, "update", receive(t, fp.started))
+	assert.EqualValues(t, "install", receive(t, fp.stopped))
+	waitInactive(t, d, "install")
+	assert.True(t, d.isActive("update"))
+	assert.False(t, d.holdsSlot("install"))
+
+	cancel()
+	assert.EqualValues(t, "update", receive(t, fp.stopped))
+}
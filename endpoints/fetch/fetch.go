@@ -17,6 +17,8 @@ func Register(router *butlerd.Router) {
 	messages.FetchProfileOwnedKeys.Register(router, FetchProfileOwnedKeys)
 	messages.FetchCommons.Register(router, FetchCommons)
 	messages.FetchCave.Register(router, FetchCave)
+	messages.FetchCavePlaySessions.Register(router, FetchCavePlaySessions)
+	messages.FetchPlayStats.Register(router, FetchPlayStats)
 	messages.FetchCaves.Register(router, FetchCaves)
 	messages.FetchExpireAll.Register(router, FetchExpireAll)
 	messages.FetchDownloadKey.Register(router, FetchDownloadKey)
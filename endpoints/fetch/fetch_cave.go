@@ -3,6 +3,7 @@ package fetch
 import (
 	"crawshaw.io/sqlite"
 	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/cmd/operate"
 	"github.com/itchio/butler/database/models"
 )
 
@@ -35,6 +36,8 @@ func FormatCave(conn *sqlite.Conn, cave *models.Cave) *butlerd.Cave {
 			InstalledSize:   cave.InstalledSize,
 			InstallLocation: cave.InstallLocationID,
 			Pinned:          cave.Pinned,
+			CompatRunnerID:  cave.CompatRunnerID,
+			UpdatePolicy:    operate.CaveUpdatePolicy(cave),
 		},
 
 		Stats: &butlerd.CaveStats{
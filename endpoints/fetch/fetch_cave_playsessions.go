@@ -0,0 +1,44 @@
+package fetch
+
+import (
+	"crawshaw.io/sqlite"
+	"github.com/go-xorm/builder"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/database/models"
+	"github.com/itchio/butler/endpoints/fetch/pager"
+	"github.com/itchio/hades"
+)
+
+func FetchCavePlaySessions(rc *butlerd.RequestContext, params butlerd.FetchCavePlaySessionsParams) (*butlerd.FetchCavePlaySessionsResult, error) {
+	res := &butlerd.FetchCavePlaySessionsResult{
+		Items: []*butlerd.PlaySession{},
+	}
+
+	rc.WithConn(func(conn *sqlite.Conn) {
+		var items []*models.PlaySession
+		cond := builder.Eq{"cave_id": params.CaveID}
+		search := hades.Search{}.OrderBy("started_at DESC")
+		res.NextCursor = pager.New(params).Fetch(conn, &items, cond, search)
+		for _, ps := range items {
+			res.Items = append(res.Items, FormatPlaySession(ps))
+		}
+	})
+	return res, nil
+}
+
+func FormatPlaySession(ps *models.PlaySession) *butlerd.PlaySession {
+	return &butlerd.PlaySession{
+		ID:     ps.ID,
+		CaveID: ps.CaveID,
+		GameID: ps.GameID,
+
+		StartedAt:  ps.StartedAt,
+		EndedAt:    ps.EndedAt,
+		SecondsRun: ps.SecondsRun,
+
+		ExitCode:       ps.ExitCode,
+		LaunchStrategy: ps.LaunchStrategy,
+		ManifestAction: ps.ManifestAction,
+		Crashed:        ps.Crashed,
+	}
+}
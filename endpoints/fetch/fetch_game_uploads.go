@@ -71,7 +71,9 @@ func FetchGameUploads(rc *butlerd.RequestContext, params butlerd.FetchGameUpload
 	if params.OnlyCompatible {
 		game := LazyFetchGame(rc, params.GameID)
 		runtime := ox.CurrentRuntime()
-		narrowRes := manager.NarrowDownUploads(rc.Consumer, game, uploads, runtime)
+		narrowRes := manager.NarrowDownUploadsWithOptions(rc.Consumer, game, uploads, runtime, manager.NarrowDownUploadsOptions{
+			AllowCompatRunners: params.AllowCompatRunners,
+		})
 		uploads = narrowRes.Uploads
 	}
 
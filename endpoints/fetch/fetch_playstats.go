@@ -0,0 +1,85 @@
+package fetch
+
+import (
+	"time"
+
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/database/models"
+)
+
+const defaultPlayStatsDays = 28
+
+func FetchPlayStats(rc *butlerd.RequestContext, params butlerd.FetchPlayStatsParams) (*butlerd.FetchPlayStatsResult, error) {
+	days := params.Days
+	if days == 0 {
+		days = defaultPlayStatsDays
+	}
+	loc := time.FixedZone("", int(params.UTCOffset)*60)
+
+	var sessions []*models.PlaySession
+	since := startOfDay(time.Now().In(loc)).AddDate(0, 0, -int(days-1))
+	rc.WithConn(func(conn *sqlite.Conn) {
+		sessions = models.PlaySessionsSince(conn, params.CaveID, since)
+	})
+
+	return AggregatePlaySessions(sessions, since, time.Now().In(loc)), nil
+}
+
+// AggregatePlaySessions sums sessions started between since and until
+// into daily and weekly buckets. Days start at midnight in since's location,
+// weeks start on Monday.
+func AggregatePlaySessions(sessions []*models.PlaySession, since time.Time, until time.Time) *butlerd.FetchPlayStatsResult {
+	loc := since.Location()
+	res := &butlerd.FetchPlayStatsResult{
+		Daily:  []*butlerd.PlayStatsBucket{},
+		Weekly: []*butlerd.PlayStatsBucket{},
+	}
+
+	dailyByStart := make(map[time.Time]*butlerd.PlayStatsBucket)
+	for day := startOfDay(since); !day.After(until); day = day.AddDate(0, 0, 1) {
+		b := &butlerd.PlayStatsBucket{Start: day}
+		res.Daily = append(res.Daily, b)
+		dailyByStart[day] = b
+	}
+
+	weeklyByStart := make(map[time.Time]*butlerd.PlayStatsBucket)
+	for week := startOfWeek(since); !week.After(until); week = week.AddDate(0, 0, 7) {
+		b := &butlerd.PlayStatsBucket{Start: week}
+		res.Weekly = append(res.Weekly, b)
+		weeklyByStart[week] = b
+	}
+
+	for _, ps := range sessions {
+		if ps.StartedAt == nil {
+			continue
+		}
+		startedAt := ps.StartedAt.In(loc)
+
+		for _, b := range []*butlerd.PlayStatsBucket{
+			dailyByStart[startOfDay(startedAt)],
+			weeklyByStart[startOfWeek(startedAt)],
+		} {
+			if b == nil {
+				continue
+			}
+			b.SecondsRun += ps.SecondsRun
+			b.Sessions++
+			if ps.Crashed {
+				b.Crashes++
+			}
+		}
+	}
+
+	return res
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func startOfWeek(t time.Time) time.Time {
+	// time.Sunday is 0, we want weeks to start on Monday
+	offset := (int(t.Weekday()) + 6) % 7
+	return startOfDay(t).AddDate(0, 0, -offset)
+}
@@ -0,0 +1,50 @@
+package fetch_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/itchio/butler/database/models"
+	"github.com/itchio/butler/endpoints/fetch"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AggregatePlaySessions(t *testing.T) {
+	at := func(s string) *time.Time {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			panic(err)
+		}
+		return &t
+	}
+
+	sessions := []*models.PlaySession{
+		// Friday
+		{StartedAt: at("2018-08-03T10:00:00Z"), SecondsRun: 60},
+		{StartedAt: at("2018-08-03T23:30:00Z"), SecondsRun: 30, Crashed: true},
+		// Monday
+		{StartedAt: at("2018-08-06T12:00:00Z"), SecondsRun: 120},
+	}
+
+	since := *at("2018-08-02T00:00:00Z")
+	until := *at("2018-08-06T18:00:00Z")
+	res := fetch.AggregatePlaySessions(sessions, since, until)
+
+	assert.Len(t, res.Daily, 5)
+	assert.EqualValues(t, 0, res.Daily[0].Sessions)
+	assert.EqualValues(t, 2, res.Daily[1].Sessions)
+	assert.EqualValues(t, 90, res.Daily[1].SecondsRun)
+	assert.EqualValues(t, 1, res.Daily[1].Crashes)
+	assert.EqualValues(t, 120, res.Daily[4].SecondsRun)
+
+	assert.Len(t, res.Weekly, 2)
+	assert.EqualValues(t, *at("2018-07-30T00:00:00Z"), res.Weekly[0].Start)
+	assert.EqualValues(t, 90, res.Weekly[0].SecondsRun)
+	assert.EqualValues(t, 1, res.Weekly[1].Sessions)
+
+	// one hour ahead of UTC, the late friday session happens on saturday
+	since = since.In(time.FixedZone("", 60*60))
+	res = fetch.AggregatePlaySessions(sessions, since, until)
+	assert.EqualValues(t, 1, res.Daily[1].Sessions)
+	assert.EqualValues(t, 1, res.Daily[2].Sessions)
+}
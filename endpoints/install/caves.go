@@ -2,8 +2,11 @@ package install
 
 import (
 	"crawshaw.io/sqlite"
+	"github.com/go-xorm/builder"
 	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/cmd/operate"
 	"github.com/itchio/butler/database/models"
+	"github.com/pkg/errors"
 )
 
 func CavesSetPinned(rc *butlerd.RequestContext, params butlerd.CavesSetPinnedParams) (*butlerd.CavesSetPinnedResult, error) {
@@ -15,3 +18,51 @@ func CavesSetPinned(rc *butlerd.RequestContext, params butlerd.CavesSetPinnedPar
 
 	return &butlerd.CavesSetPinnedResult{}, nil
 }
+
+func CavesSetUpdatePolicy(rc *butlerd.RequestContext, params butlerd.CavesSetUpdatePolicyParams) (*butlerd.CavesSetUpdatePolicyResult, error) {
+	cave := operate.ValidateCave(rc, params.CaveID)
+	rc.WithConn(func(conn *sqlite.Conn) {
+		cave.UpdatePolicy = string(params.Policy)
+		cave.Save(conn)
+	})
+
+	return &butlerd.CavesSetUpdatePolicyResult{}, nil
+}
+
+func InstallSetPreviousVersionsKept(rc *butlerd.RequestContext, params butlerd.InstallSetPreviousVersionsKeptParams) (*butlerd.InstallSetPreviousVersionsKeptResult, error) {
+	rc.WithConn(func(conn *sqlite.Conn) {
+		operate.SetPreviousVersionsKept(conn, params.Count)
+	})
+
+	return &butlerd.InstallSetPreviousVersionsKeptResult{}, nil
+}
+
+func CavesRollback(rc *butlerd.RequestContext, params butlerd.CavesRollbackParams) (*butlerd.CavesRollbackResult, error) {
+	cave := operate.ValidateCave(rc, params.CaveID)
+
+	if len(rc.RunningCaves.ByCaveID(cave.ID)) > 0 {
+		return nil, errors.WithStack(butlerd.CodeAlreadyRunning)
+	}
+
+	var pending bool
+	rc.WithConn(func(conn *sqlite.Conn) {
+		pending = models.MustSelectOne(conn, &models.Download{}, builder.And(
+			builder.Eq{"cave_id": cave.ID},
+			builder.IsNull{"finished_at"},
+			builder.Not{builder.Expr("discarded")},
+		))
+	})
+	if pending {
+		return nil, errors.New("Cave has a pending download, discard it before rolling back")
+	}
+
+	v, err := operate.RollbackCave(rc, cave)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &butlerd.CavesRollbackResult{
+		Upload: v.Receipt.Upload,
+		Build:  v.Receipt.Build,
+	}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"crawshaw.io/sqlite"
 	"github.com/itchio/butler/butlerd"
 	"github.com/itchio/butler/cmd/operate"
+	"github.com/itchio/butler/manager"
 	"github.com/pkg/errors"
 )
 
@@ -22,7 +23,9 @@ func GameFindUploads(rc *butlerd.RequestContext, params butlerd.GameFindUploadsP
 	})
 	client := rc.Client(access.APIKey)
 
-	uploads, err := operate.GetFilteredUploads(client, params.Game, access.Credentials, consumer)
+	uploads, err := operate.GetFilteredUploads(client, params.Game, access.Credentials, consumer, manager.NarrowDownUploadsOptions{
+		AllowCompatRunners: params.AllowCompatRunners,
+	})
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
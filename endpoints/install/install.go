@@ -10,6 +10,7 @@ func Register(router *butlerd.Router) {
 	messages.InstallPlan.Register(router, InstallPlan)
 	messages.InstallQueue.Register(router, InstallQueue)
 	messages.InstallPerform.Register(router, InstallPerform)
+	messages.InstallFromLocalFile.Register(router, InstallFromLocalFile)
 	messages.InstallCancel.Register(router, InstallCancel)
 	messages.UninstallPerform.Register(router, UninstallPerform)
 	messages.InstallVersionSwitchQueue.Register(router, InstallVersionSwitchQueue)
@@ -20,4 +21,7 @@ func Register(router *butlerd.Router) {
 	messages.InstallLocationsScan.Register(router, InstallLocationsScan)
 
 	messages.CavesSetPinned.Register(router, CavesSetPinned)
+	messages.CavesSetUpdatePolicy.Register(router, CavesSetUpdatePolicy)
+	messages.InstallSetPreviousVersionsKept.Register(router, InstallSetPreviousVersionsKept)
+	messages.CavesRollback.Register(router, CavesRollback)
 }
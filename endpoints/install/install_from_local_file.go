@@ -0,0 +1,217 @@
+package install
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"crawshaw.io/sqlite"
+	"github.com/go-xorm/builder"
+	"github.com/google/uuid"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/cmd/operate"
+	"github.com/itchio/butler/cmd/wipe"
+	"github.com/itchio/butler/database/models"
+	"github.com/itchio/butler/endpoints/fetch"
+	itchio "github.com/itchio/go-itchio"
+	"github.com/itchio/wharf/state"
+	"github.com/pkg/errors"
+)
+
+func InstallFromLocalFile(rc *butlerd.RequestContext, params butlerd.InstallFromLocalFileParams) (*butlerd.InstallFromLocalFileResult, error) {
+	consumer := rc.Consumer
+
+	stats, err := os.Stat(params.Path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if stats.IsDir() {
+		return nil, errors.Errorf("(%s) is a folder, not a file", params.Path)
+	}
+
+	var installLocation *models.InstallLocation
+	rc.WithConn(func(conn *sqlite.Conn) {
+		installLocation = models.InstallLocationByID(conn, params.InstallLocationID)
+	})
+	if installLocation == nil {
+		return nil, errors.Errorf("Install location not found (%s)", params.InstallLocationID)
+	}
+
+	var game *itchio.Game
+	var upload *itchio.Upload
+	if params.GameID != 0 {
+		game = fetch.LazyFetchGame(rc, params.GameID)
+		if game == nil {
+			return nil, errors.Errorf("Game not found (%d)", params.GameID)
+		}
+
+		if params.UploadID != 0 {
+			for _, u := range fetch.LazyFetchGameUploads(rc, params.GameID) {
+				if u.ID == params.UploadID {
+					upload = u
+					break
+				}
+			}
+			if upload == nil {
+				return nil, errors.Errorf("Upload %d not found for %s", params.UploadID, operate.GameToString(game))
+			}
+		}
+	}
+
+	meta := operate.NewMetaSubcontext()
+	mp := meta.Data
+	mp.StagingFolder = installLocation.GetStagingFolder(params.ID)
+	mp.Reason = butlerd.DownloadReasonInstall
+	mp.Upload = upload
+	mp.LocalFile = params.Path
+	mp.IgnoreInstallers = params.IgnoreInstallers
+
+	var reserveErr error
+	rc.WithConn(func(conn *sqlite.Conn) {
+		if game == nil {
+			game = localGame(conn, params.Path)
+			consumer.Infof("No game specified, using local stand-in game %d", game.ID)
+		}
+		mp.Game = game
+
+		var cave *models.Cave
+		cave, reserveErr = reserveCave(conn, consumer, installLocation, game, upload)
+		if reserveErr != nil {
+			return
+		}
+
+		mp.CaveID = cave.ID
+		mp.InstallFolder = cave.GetInstallFolder(conn)
+		mp.InstallLocationID = cave.InstallLocationID
+		mp.InstallFolderName = cave.InstallFolderName
+		mp.Access = localFileAccess(conn, game.ID)
+	})
+	if reserveErr != nil {
+		return nil, reserveErr
+	}
+
+	consumer.Infof("Installing (%s) for %s", params.Path, operate.GameToString(game))
+	operate.LogUpload(consumer, upload, nil)
+
+	err = func() error {
+		oc, err := operate.LoadContext(rc.Ctx, rc, mp.StagingFolder)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer oc.Release()
+		return oc.Save(meta)
+	}()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	parentCtx := rc.Ctx
+	ctx, cancelFunc := context.WithCancel(parentCtx)
+
+	rc.CancelFuncs.Add(params.ID, cancelFunc)
+	defer rc.CancelFuncs.Remove(params.ID)
+
+	err = operate.InstallPerform(ctx, rc, butlerd.InstallPerformParams{
+		ID:            params.ID,
+		StagingFolder: mp.StagingFolder,
+	})
+	if err != nil {
+		// there's no download to resume from, so clean up after ourselves
+		consumer.Warnf("Install failed, wiping staging and install folders")
+		wipeErr := wipe.Do(consumer, mp.StagingFolder)
+		if wipeErr != nil {
+			consumer.Warnf("While wiping staging folder: %s", wipeErr.Error())
+		}
+		wipeErr = wipe.Do(consumer, mp.InstallFolder)
+		if wipeErr != nil {
+			consumer.Warnf("While wiping install folder: %s", wipeErr.Error())
+		}
+		if operate.IsLocalGame(game.ID) {
+			rc.WithConn(func(conn *sqlite.Conn) {
+				models.MustDelete(conn, &itchio.Game{}, builder.Eq{"id": game.ID})
+			})
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	res := &butlerd.InstallFromLocalFileResult{}
+	rc.WithConn(func(conn *sqlite.Conn) {
+		cave := models.CaveByID(conn, mp.CaveID)
+		cave.Preload(conn)
+		res.Cave = fetch.FormatCave(conn, cave)
+	})
+	return res, nil
+}
+
+// localGamesLock makes sure files installed without a game at
+// the same time get different stand-in games.
+var localGamesLock sync.Mutex
+
+// localGame saves and returns a stand-in game for a file installed
+// without a game, named after the file. Its ID is negative so it never
+// collides with itch.io games, see operate.IsLocalGame.
+func localGame(conn *sqlite.Conn, path string) *itchio.Game {
+	localGamesLock.Lock()
+	defer localGamesLock.Unlock()
+
+	var minID int64
+	models.MustExecRaw(conn, `SELECT coalesce(min(id), 0) FROM games`, func(stmt *sqlite.Stmt) error {
+		minID = stmt.ColumnInt64(0)
+		return nil
+	})
+	if minID > 0 {
+		minID = 0
+	}
+
+	name := filepath.Base(path)
+	title := strings.TrimSuffix(name, filepath.Ext(name))
+	if title == "" {
+		title = name
+	}
+
+	game := &itchio.Game{
+		ID:             minID - 1,
+		Title:          title,
+		Classification: itchio.GameClassificationGame,
+	}
+	models.MustSave(conn, game)
+	return game
+}
+
+// reserveCave returns a cave with a fresh install folder for game,
+// or an error if upload is already installed.
+func reserveCave(conn *sqlite.Conn, consumer *state.Consumer, installLocation *models.InstallLocation, game *itchio.Game, upload *itchio.Upload) (*models.Cave, error) {
+	if upload != nil {
+		dupCond := builder.Eq{
+			"game_id":   game.ID,
+			"upload_id": upload.ID,
+		}
+		if models.MustSelectOne(conn, &models.Cave{}, dupCond) {
+			return nil, errors.Errorf("That upload is already installed!")
+		}
+	}
+
+	cave := &models.Cave{
+		ID:                uuid.New().String(),
+		InstallLocationID: installLocation.ID,
+	}
+	if operate.IsLocalGame(game.ID) {
+		cave.InstallFolderName = game.Title
+	} else {
+		cave.InstallFolderName = makeInstallFolderName(game, consumer)
+	}
+	ensureUniqueFolderName(conn, cave)
+	return cave, nil
+}
+
+// localFileAccess returns the credentials to install a local file
+// with. Nobody has to be logged in, since installing a local file
+// may well happen offline.
+func localFileAccess(conn *sqlite.Conn, gameID int64) *operate.GameAccess {
+	if models.MustCount(conn, &models.Profile{}, builder.NewCond()) == 0 {
+		return &operate.GameAccess{}
+	}
+	return operate.AccessForGameID(conn, gameID)
+}
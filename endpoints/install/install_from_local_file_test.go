@@ -0,0 +1,75 @@
+package install
+
+import (
+	"os"
+	"testing"
+
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd/butlerdtest"
+	"github.com/itchio/butler/database/models"
+	itchio "github.com/itchio/go-itchio"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LocalGame(t *testing.T) {
+	h := butlerdtest.New(t)
+	defer h.Close()
+
+	h.RC.WithConn(func(conn *sqlite.Conn) {
+		models.MustSave(conn, &itchio.Game{ID: 123, Title: "Real game"})
+
+		first := localGame(conn, "/downloads/garden-1.0.zip")
+		assert.EqualValues(t, -1, first.ID)
+		assert.EqualValues(t, "garden-1.0", first.Title)
+
+		second := localGame(conn, "/downloads/garden-1.0.zip")
+		assert.EqualValues(t, -2, second.ID, "local games should get different IDs")
+
+		assert.NotNil(t, models.GameByID(conn, -1), "local games should be saved right away")
+	})
+}
+
+func Test_ReserveCave(t *testing.T) {
+	h := butlerdtest.New(t)
+	defer h.Close()
+
+	il := &models.InstallLocation{ID: "default", Path: h.Dir}
+	game := &itchio.Game{ID: 123, Title: "Garden", URL: "https://example.itch.io/garden"}
+	upload := &itchio.Upload{ID: 456}
+
+	h.RC.WithConn(func(conn *sqlite.Conn) {
+		models.MustSave(conn, il)
+
+		cave, err := reserveCave(conn, h.RC.Consumer, il, game, upload)
+		assert.NoError(t, err)
+		assert.EqualValues(t, "default", cave.InstallLocationID)
+		assert.NotEmpty(t, cave.InstallFolderName)
+
+		models.MustSave(conn, &models.Cave{
+			ID:                cave.ID,
+			GameID:            game.ID,
+			UploadID:          upload.ID,
+			InstallLocationID: il.ID,
+			InstallFolderName: cave.InstallFolderName,
+		})
+		assert.NoError(t, os.MkdirAll(cave.GetInstallFolder(conn), 0755))
+
+		_, err = reserveCave(conn, h.RC.Consumer, il, game, upload)
+		assert.Error(t, err, "installing the same upload twice should be rejected")
+
+		other, err := reserveCave(conn, h.RC.Consumer, il, game, &itchio.Upload{ID: 789})
+		assert.NoError(t, err)
+		assert.NotEqual(t, cave.InstallFolderName, other.InstallFolderName, "install folders should not collide")
+	})
+}
+
+func Test_LocalFileAccess(t *testing.T) {
+	h := butlerdtest.New(t)
+	defer h.Close()
+
+	h.RC.WithConn(func(conn *sqlite.Conn) {
+		access := localFileAccess(conn, -1)
+		assert.NotNil(t, access, "nobody being logged in should be fine")
+		assert.EqualValues(t, "", access.APIKey)
+	})
+}
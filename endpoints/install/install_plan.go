@@ -30,7 +30,9 @@ func InstallPlan(rc *butlerd.RequestContext, params butlerd.InstallPlanParams) (
 	consumer.Opf("Planning install for %s", operate.GameToString(game))
 
 	baseUploads := fetch.LazyFetchGameUploads(rc, params.GameID)
-	baseUploads = manager.NarrowDownUploads(consumer, game, baseUploads, ox.CurrentRuntime()).Uploads
+	baseUploads = manager.NarrowDownUploadsWithOptions(consumer, game, baseUploads, ox.CurrentRuntime(), manager.NarrowDownUploadsOptions{
+		AllowCompatRunners: params.AllowCompatRunners,
+	}).Uploads
 
 	// exclude already-installed and currently-installing uploads
 	var uploadIDs []interface{}
@@ -16,6 +16,7 @@ import (
 	"github.com/itchio/butler/cmd/operate"
 	"github.com/itchio/butler/database/models"
 	"github.com/itchio/butler/endpoints/downloads"
+	"github.com/itchio/butler/manager"
 	itchio "github.com/itchio/go-itchio"
 	"github.com/itchio/wharf/state"
 	"github.com/pkg/errors"
@@ -148,7 +149,10 @@ func InstallQueue(rc *butlerd.RequestContext, queueParams butlerd.InstallQueuePa
 
 	if params.Upload == nil {
 		consumer.Infof("No upload specified, looking for compatible ones...")
-		uploadsFilterResult, err := operate.GetFilteredUploads(client, params.Game, params.Access.Credentials, consumer)
+		narrowOpts := manager.NarrowDownUploadsOptions{
+			AllowCompatRunners: queueParams.AllowCompatRunners || (cave != nil && cave.CompatRunnerID != ""),
+		}
+		uploadsFilterResult, err := operate.GetFilteredUploads(client, params.Game, params.Access.Credentials, consumer, narrowOpts)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
@@ -277,6 +281,7 @@ func InstallQueue(rc *butlerd.RequestContext, queueParams butlerd.InstallQueuePa
 		StagingFolder:     params.StagingFolder,
 		Reason:            params.Reason,
 		InstallLocationID: params.InstallLocationID,
+		NeededFreeSpace:   istate.NeededFreeSpace,
 	}
 
 	if queueParams.QueueDownload {
@@ -0,0 +1,158 @@
+package launch
+
+import (
+	"os"
+	"path/filepath"
+
+	"crawshaw.io/sqlite"
+	"github.com/go-xorm/builder"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/cmd/operate"
+	"github.com/itchio/butler/database/models"
+	"github.com/itchio/hades"
+	"github.com/pkg/errors"
+)
+
+// CompatParams describes how to launch Windows executables
+// through a compatibility runner
+type CompatParams struct {
+	Kind    butlerd.CompatRunnerKind
+	Command string
+
+	// Wine prefix or Proton compat data path
+	PrefixDir string
+}
+
+// Wrap returns the executable, arguments and additional
+// environment variables needed to run target through the runner.
+func (cp *CompatParams) Wrap(target string, args []string) (string, []string, map[string]string) {
+	var cmdArgs []string
+	env := make(map[string]string)
+
+	switch cp.Kind {
+	case butlerd.CompatRunnerKindProton:
+		cmdArgs = append(cmdArgs, "run")
+		env["STEAM_COMPAT_DATA_PATH"] = cp.PrefixDir
+		// proton refuses to run without it. Outside of Steam, there's no
+		// client install to point to, unless the player set one up.
+		clientPath := os.Getenv("STEAM_COMPAT_CLIENT_INSTALL_PATH")
+		if clientPath == "" {
+			clientPath = cp.PrefixDir
+		}
+		env["STEAM_COMPAT_CLIENT_INSTALL_PATH"] = clientPath
+	default:
+		env["WINEPREFIX"] = cp.PrefixDir
+	}
+
+	cmdArgs = append(cmdArgs, target)
+	cmdArgs = append(cmdArgs, args...)
+	return cp.Command, cmdArgs, env
+}
+
+// defaultCompatPrefix returns where a cave's prefix lives if
+// the client didn't pick one.
+func defaultCompatPrefix(installFolder string) string {
+	return filepath.Join(installFolder, ".itch", "compat-prefix")
+}
+
+// compatParamsForCave returns nil if the cave doesn't use
+// a compatibility runner, or if it's gone.
+func compatParamsForCave(conn *sqlite.Conn, cave *models.Cave) *CompatParams {
+	if cave.CompatRunnerID == "" {
+		return nil
+	}
+
+	runner := models.CompatRunnerByID(conn, cave.CompatRunnerID)
+	if runner == nil {
+		return nil
+	}
+
+	prefixDir := cave.CompatPrefix
+	if prefixDir == "" {
+		prefixDir = defaultCompatPrefix(cave.GetInstallFolder(conn))
+	}
+
+	return &CompatParams{
+		Kind:      butlerd.CompatRunnerKind(runner.Kind),
+		Command:   runner.Command,
+		PrefixDir: prefixDir,
+	}
+}
+
+func CompatRunnersList(rc *butlerd.RequestContext, params butlerd.CompatRunnersListParams) (*butlerd.CompatRunnersListResult, error) {
+	res := &butlerd.CompatRunnersListResult{
+		Runners: []*butlerd.CompatRunner{},
+	}
+
+	rc.WithConn(func(conn *sqlite.Conn) {
+		for _, r := range models.AllCompatRunners(conn) {
+			res.Runners = append(res.Runners, &butlerd.CompatRunner{
+				ID:      r.ID,
+				Name:    r.Name,
+				Kind:    butlerd.CompatRunnerKind(r.Kind),
+				Command: r.Command,
+			})
+		}
+	})
+	return res, nil
+}
+
+func CompatRunnersSave(rc *butlerd.RequestContext, params butlerd.CompatRunnersSaveParams) (*butlerd.CompatRunnersSaveResult, error) {
+	if !filepath.IsAbs(params.Runner.Command) {
+		return nil, errors.Errorf("compatibility runner command must be an absolute path, got (%s)", params.Runner.Command)
+	}
+
+	rc.WithConn(func(conn *sqlite.Conn) {
+		r := &models.CompatRunner{
+			ID:      params.Runner.ID,
+			Name:    params.Runner.Name,
+			Kind:    string(params.Runner.Kind),
+			Command: params.Runner.Command,
+		}
+		r.Save(conn)
+	})
+	return &butlerd.CompatRunnersSaveResult{}, nil
+}
+
+func CompatRunnersRemove(rc *butlerd.RequestContext, params butlerd.CompatRunnersRemoveParams) (*butlerd.CompatRunnersRemoveResult, error) {
+	rc.WithConn(func(conn *sqlite.Conn) {
+		models.MustDelete(conn, &models.CompatRunner{}, builder.Eq{"id": params.ID})
+		models.MustUpdate(conn, &models.Cave{},
+			hades.Where(builder.Eq{"compat_runner_id": params.ID}),
+			builder.Eq{"compat_runner_id": ""},
+		)
+	})
+	return &butlerd.CompatRunnersRemoveResult{}, nil
+}
+
+func CavesSetCompatRunner(rc *butlerd.RequestContext, params butlerd.CavesSetCompatRunnerParams) (*butlerd.CavesSetCompatRunnerResult, error) {
+	cave := operate.ValidateCave(rc, params.CaveID)
+	res := &butlerd.CavesSetCompatRunnerResult{}
+
+	var err error
+	rc.WithConn(func(conn *sqlite.Conn) {
+		if params.RunnerID == "" {
+			cave.CompatRunnerID = ""
+			cave.Save(conn)
+			return
+		}
+
+		if models.CompatRunnerByID(conn, params.RunnerID) == nil {
+			err = errors.Errorf("compatibility runner not found: (%s)", params.RunnerID)
+			return
+		}
+
+		cave.CompatRunnerID = params.RunnerID
+		if params.PrefixDir != "" {
+			cave.CompatPrefix = params.PrefixDir
+		} else if cave.CompatPrefix == "" {
+			cave.CompatPrefix = defaultCompatPrefix(cave.GetInstallFolder(conn))
+		}
+		cave.Save(conn)
+		res.PrefixDir = cave.CompatPrefix
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return res, nil
+}
@@ -0,0 +1,69 @@
+package launch_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/endpoints/launch"
+	"github.com/stretchr/testify/assert"
+)
+
+const fakeRunnerScript = `#!/bin/sh
+echo "wineprefix=$WINEPREFIX"
+echo "compatdata=$STEAM_COMPAT_DATA_PATH"
+echo "compatclient=$STEAM_COMPAT_CLIENT_INSTALL_PATH"
+echo "args=$*"
+`
+
+func Test_CompatWrap(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("compatibility runners are only used on Linux")
+	}
+
+	dir, err := ioutil.TempDir("", "compat")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// tested below as if Steam wasn't installed
+	os.Unsetenv("STEAM_COMPAT_CLIENT_INSTALL_PATH")
+
+	fakeRunner := filepath.Join(dir, "fake-wine")
+	assert.NoError(t, ioutil.WriteFile(fakeRunner, []byte(fakeRunnerScript), 0755))
+
+	run := func(cp *launch.CompatParams) string {
+		name, args, env := cp.Wrap("Game.exe", []string{"-windowed"})
+		cmd := exec.Command(name, args...)
+		for k, v := range env {
+			cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+		}
+		out, err := cmd.Output()
+		assert.NoError(t, err)
+		return string(out)
+	}
+
+	out := run(&launch.CompatParams{
+		Kind:      butlerd.CompatRunnerKindWine,
+		Command:   fakeRunner,
+		PrefixDir: "/prefixes/garden",
+	})
+	assert.Contains(t, out, "wineprefix=/prefixes/garden\n")
+	assert.Contains(t, out, "compatdata=\n")
+	assert.Contains(t, out, "compatclient=\n")
+	assert.Contains(t, out, "args=Game.exe -windowed\n")
+
+	out = run(&launch.CompatParams{
+		Kind:      butlerd.CompatRunnerKindProton,
+		Command:   fakeRunner,
+		PrefixDir: "/compatdata/garden",
+	})
+	assert.Contains(t, out, "wineprefix=\n")
+	assert.Contains(t, out, "compatdata=/compatdata/garden\n")
+	assert.Contains(t, out, "compatclient=/compatdata/garden\n")
+	assert.Contains(t, out, "args=run Game.exe -windowed\n")
+}
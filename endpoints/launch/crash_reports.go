@@ -0,0 +1,118 @@
+package launch
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"crawshaw.io/sqlite"
+	"github.com/google/uuid"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/database/models"
+	"github.com/itchio/butler/endpoints/launch/crashes"
+	itchio "github.com/itchio/go-itchio"
+	"github.com/itchio/ox"
+	"github.com/pkg/errors"
+)
+
+type crashContext struct {
+	LaunchID      string
+	Cave          *models.Cave
+	Upload        *itchio.Upload
+	Build         *itchio.Build
+	InstallFolder string
+	Runtime       *ox.Runtime
+}
+
+// recordCrash writes a crash report to the install folder and
+// indexes it in the database.
+func recordCrash(rc *butlerd.RequestContext, cc *crashContext, crash *CrashInfo) error {
+	target := crash.Target
+	if rel, err := filepath.Rel(cc.InstallFolder, target); err == nil {
+		target = filepath.ToSlash(rel)
+	}
+
+	report := &crashes.Report{
+		ID:        uuid.New().String(),
+		LaunchID:  cc.LaunchID,
+		CaveID:    cc.Cave.ID,
+		GameID:    cc.Cave.GameID,
+		CreatedAt: time.Now().UTC(),
+		ExitCode:  crash.ExitCode,
+		Signal:    crash.Signal,
+		Duration:  crash.Duration.Seconds(),
+		Runtime:   fmt.Sprintf("%s-%s", cc.Runtime.OS(), cc.Runtime.Arch()),
+		Target:    target,
+		Stdout:    crash.Stdout,
+		Stderr:    crash.Stderr,
+	}
+	if cc.Upload != nil {
+		report.UploadID = cc.Upload.ID
+	}
+	if cc.Build != nil {
+		report.BuildID = cc.Build.ID
+	}
+
+	path, err := report.Write(cc.InstallFolder)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	rc.Consumer.Infof("Wrote crash report to (%s)", path)
+
+	rc.WithConn(func(conn *sqlite.Conn) {
+		cr := &models.CrashReport{
+			ID:        report.ID,
+			LaunchID:  report.LaunchID,
+			CaveID:    report.CaveID,
+			GameID:    report.GameID,
+			BuildID:   report.BuildID,
+			CreatedAt: &report.CreatedAt,
+			ExitCode:  report.ExitCode,
+			Signal:    report.Signal,
+			Path:      path,
+		}
+		cr.Save(conn)
+	})
+	return nil
+}
+
+func LaunchCrashReports(rc *butlerd.RequestContext, params butlerd.LaunchCrashReportsParams) (*butlerd.LaunchCrashReportsResult, error) {
+	var indexed []*models.CrashReport
+	rc.WithConn(func(conn *sqlite.Conn) {
+		indexed = models.CrashReports(conn, params.CaveID, params.Limit)
+	})
+
+	res := &butlerd.LaunchCrashReportsResult{
+		Reports: []*butlerd.CrashReport{},
+	}
+	for _, cr := range indexed {
+		formatted := &butlerd.CrashReport{
+			ID:       cr.ID,
+			LaunchID: cr.LaunchID,
+			CaveID:   cr.CaveID,
+			GameID:   cr.GameID,
+			BuildID:  cr.BuildID,
+			ExitCode: cr.ExitCode,
+			Signal:   cr.Signal,
+			Path:     cr.Path,
+		}
+		if cr.CreatedAt != nil {
+			formatted.CreatedAt = *cr.CreatedAt
+		}
+
+		report, err := crashes.Read(cr.Path)
+		if err != nil {
+			// the cave may have been uninstalled since
+			rc.Consumer.Debugf("Could not read crash report: %s", err.Error())
+		} else {
+			formatted.UploadID = report.UploadID
+			formatted.Duration = report.Duration
+			formatted.Runtime = report.Runtime
+			formatted.Target = report.Target
+			formatted.Stdout = report.Stdout
+			formatted.Stderr = report.Stderr
+		}
+		res.Reports = append(res.Reports, formatted)
+	}
+	return res, nil
+}
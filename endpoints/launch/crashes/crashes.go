@@ -0,0 +1,126 @@
+// Package crashes reads and writes the crash reports that are
+// recorded when a natively-launched game exits abnormally.
+//
+// Reports are written as JSON to `./.itch/crashes/` in the install
+// folder, so they survive log rotation and can be collected by support
+// even without access to butler's database.
+package crashes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// A Report describes an abnormal exit of a game
+type Report struct {
+	ID       string `json:"id"`
+	LaunchID string `json:"launchId"`
+
+	CaveID   string `json:"caveId"`
+	GameID   int64  `json:"gameId"`
+	UploadID int64  `json:"uploadId"`
+	BuildID  int64  `json:"buildId,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Exit code of the process, as reported by the OS
+	ExitCode int64 `json:"exitCode"`
+	// Name of the signal that killed the process, if any
+	Signal string `json:"signal,omitempty"`
+	// How long the game ran for, in seconds
+	Duration float64 `json:"duration"`
+
+	// Something like `linux-amd64`
+	Runtime string `json:"runtime"`
+	// Path of the executable, relative to the install folder if possible
+	Target string `json:"target"`
+
+	// Last lines of output
+	Stdout []string `json:"stdout"`
+	Stderr []string `json:"stderr"`
+}
+
+const reportExt = ".json"
+
+// Dir returns the folder crash reports of a given install folder live in
+func Dir(installFolder string) string {
+	return filepath.Join(installFolder, ".itch", "crashes")
+}
+
+// Write saves r to the crash folder of installFolder and returns
+// the path it was written to.
+func (r *Report) Write(installFolder string) (string, error) {
+	dir := Dir(installFolder)
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	name := fmt.Sprintf("crash-%s-%s%s", r.CreatedAt.UTC().Format("20060102-150405"), r.ID, reportExt)
+	path := filepath.Join(dir, name)
+
+	bs, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	err = ioutil.WriteFile(path, bs, 0644)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return path, nil
+}
+
+// Read reads a single crash report
+func Read(path string) (*Report, error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var r Report
+	err = json.Unmarshal(bs, &r)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding crash report (%s)", path)
+	}
+	return &r, nil
+}
+
+// List returns all crash reports for installFolder, most recent first.
+// Reports that can't be read are skipped.
+func List(installFolder string) ([]*Report, error) {
+	dir := Dir(installFolder)
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	var reports []*Report
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), reportExt) {
+			continue
+		}
+
+		r, err := Read(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		reports = append(reports, r)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].CreatedAt.After(reports[j].CreatedAt)
+	})
+	return reports, nil
+}
@@ -0,0 +1,46 @@
+package crashes_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/itchio/butler/endpoints/launch/crashes"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WriteList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crashes")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	reports, err := crashes.List(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, reports)
+
+	now := time.Now().UTC()
+	for i, id := range []string{"older", "newer"} {
+		r := &crashes.Report{
+			ID:        id,
+			CreatedAt: now.Add(time.Duration(i) * time.Second),
+			ExitCode:  -1,
+			Signal:    "segmentation fault",
+			Stderr:    []string{"oh no"},
+		}
+		_, err := r.Write(dir)
+		assert.NoError(t, err)
+	}
+
+	// unreadable reports are skipped
+	err = ioutil.WriteFile(filepath.Join(crashes.Dir(dir), "garbage.json"), []byte("{"), 0644)
+	assert.NoError(t, err)
+
+	reports, err = crashes.List(dir)
+	assert.NoError(t, err)
+	assert.Len(t, reports, 2)
+	assert.EqualValues(t, "newer", reports[0].ID)
+	assert.EqualValues(t, "segmentation fault", reports[1].Signal)
+	assert.EqualValues(t, []string{"oh no"}, reports[1].Stderr)
+}
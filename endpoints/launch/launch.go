@@ -1,6 +1,7 @@
 package launch
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -15,10 +16,13 @@ import (
 	goerrors "errors"
 
 	"crawshaw.io/sqlite"
+	"github.com/google/uuid"
 	"github.com/itchio/butler/butlerd"
 	"github.com/itchio/butler/butlerd/messages"
 	"github.com/itchio/butler/cmd/operate"
+	"github.com/itchio/butler/database/models"
 	"github.com/itchio/butler/endpoints/launch/manifest"
+	"github.com/itchio/butler/endpoints/saves/snapshots"
 	"github.com/itchio/butler/installer"
 	"github.com/itchio/butler/installer/bfs"
 	"github.com/itchio/butler/manager"
@@ -31,6 +35,17 @@ var ErrCandidateDisappeared = goerrors.New("candidate disappeared from disk!")
 
 func Register(router *butlerd.Router) {
 	messages.Launch.Register(router, Launch)
+	messages.LaunchList.Register(router, LaunchList)
+	messages.LaunchStop.Register(router, LaunchStop)
+	messages.LaunchCrashReports.Register(router, LaunchCrashReports)
+	messages.CompatRunnersList.Register(router, CompatRunnersList)
+	messages.CompatRunnersSave.Register(router, CompatRunnersSave)
+	messages.CompatRunnersRemove.Register(router, CompatRunnersRemove)
+	messages.CavesSetCompatRunner.Register(router, CavesSetCompatRunner)
+	messages.CavesSetLaunchOptions.Register(router, CavesSetLaunchOptions)
+	messages.CavesGetLaunchOptions.Register(router, CavesGetLaunchOptions)
+	messages.LaunchSetSandboxBackend.Register(router, LaunchSetSandboxBackend)
+	messages.LaunchGetSandboxBackend.Register(router, LaunchGetSandboxBackend)
 }
 
 func Launch(rc *butlerd.RequestContext, params butlerd.LaunchParams) (*butlerd.LaunchResult, error) {
@@ -38,8 +53,10 @@ func Launch(rc *butlerd.RequestContext, params butlerd.LaunchParams) (*butlerd.L
 
 	cave := operate.ValidateCave(rc, params.CaveID)
 	var installFolder string
+	var launchOptions *models.LaunchOptions
 	rc.WithConn(func(conn *sqlite.Conn) {
 		installFolder = cave.GetInstallFolder(conn)
+		launchOptions = getLaunchOptions(conn, cave.ID)
 	})
 
 	_, err := os.Stat(installFolder)
@@ -50,6 +67,14 @@ func Launch(rc *butlerd.RequestContext, params butlerd.LaunchParams) (*butlerd.L
 		}
 	}
 
+	runningRes, err := checkAlreadyRunning(rc, params, installFolder)
+	if err != nil {
+		return nil, err
+	}
+	if runningRes != nil {
+		return runningRes, nil
+	}
+
 	game := cave.Game
 	upload := cave.Upload
 	build := cave.Build
@@ -63,6 +88,27 @@ func Launch(rc *butlerd.RequestContext, params butlerd.LaunchParams) (*butlerd.L
 
 	runtime := ox.CurrentRuntime()
 
+	var compat *CompatParams
+	if runtime.Platform == ox.PlatformLinux {
+		rc.WithConn(func(conn *sqlite.Conn) {
+			compat = compatParamsForCave(conn, cave)
+		})
+		if compat != nil {
+			consumer.Infof("Windows executables will be launched with (%s) (%s prefix at %s)", compat.Command, compat.Kind, compat.PrefixDir)
+		}
+	}
+	targetRuntime := launchRuntime(runtime, compat)
+
+	locale := params.Locale
+	if locale == "" {
+		locale = "en"
+	}
+	vars := &manifest.Vars{
+		Runtime:       targetRuntime,
+		InstallFolder: installFolder,
+		Locale:        locale,
+	}
+
 	consumer.Infof("→ Launching %s", operate.GameToString(game))
 	consumer.Infof("   on runtime %s", runtime)
 	consumer.Infof("   (%s) is our install folder", installFolder)
@@ -115,15 +161,18 @@ func Launch(rc *butlerd.RequestContext, params butlerd.LaunchParams) (*butlerd.L
 			return nil
 		}
 
-		actions := manifest.ListActions(appManifest, runtime)
+		actions := manifest.ListActions(appManifest, manifest.CurrentHost(targetRuntime))
 
 		if len(actions) == 0 {
-			consumer.Warnf("Had manifest, but no actions available (for this platform at least)")
+			consumer.Warnf("Had manifest, but no actions available (for this runtime at least)")
 			return nil
 		}
 
 		if len(actions) == 1 {
 			manifestAction = actions[0]
+		} else if index := launchOptions.ManifestActionIndex; index != nil && *index >= 0 && *index < int64(len(actions)) {
+			manifestAction = actions[*index]
+			consumer.Infof("Using manifest action (%s) from launch options", manifestAction.Name)
 		} else {
 			r, err := messages.PickManifestAction.Call(rc, butlerd.PickManifestActionParams{
 				Actions: actions,
@@ -137,6 +186,12 @@ func Launch(rc *butlerd.RequestContext, params butlerd.LaunchParams) (*butlerd.L
 			}
 
 			manifestAction = actions[r.Index]
+
+			if r.Remember {
+				index := int64(r.Index)
+				launchOptions.ManifestActionIndex = &index
+				rc.WithConn(launchOptions.Save)
+			}
 		}
 
 		if manifestAction == nil {
@@ -145,7 +200,7 @@ func Launch(rc *butlerd.RequestContext, params butlerd.LaunchParams) (*butlerd.L
 		}
 
 		// is it a path?
-		res, err := DetermineStrategy(consumer, runtime, installFolder, manifestAction)
+		res, err := DetermineStrategy(consumer, vars, manifestAction)
 		if err != nil {
 			return errors.WithStack(err)
 		}
@@ -215,13 +270,13 @@ func Launch(rc *butlerd.RequestContext, params butlerd.LaunchParams) (*butlerd.L
 
 		var nativeFlavor dash.Flavor
 		var nativeArch dash.Arch
-		switch runtime.Platform {
+		switch targetRuntime.Platform {
 		case ox.PlatformWindows:
 			nativeFlavor = dash.FlavorNativeWindows
 		case ox.PlatformLinux:
 			nativeFlavor = dash.FlavorNativeLinux
 		}
-		if runtime.Is64 {
+		if targetRuntime.Is64 {
 			nativeArch = dash.ArchAmd64
 		} else {
 			nativeArch = dash.Arch386
@@ -276,6 +331,16 @@ func Launch(rc *butlerd.RequestContext, params butlerd.LaunchParams) (*butlerd.L
 			}
 		}
 
+		if launchOptions.CandidatePath != "" {
+			for _, c := range verdict.Candidates {
+				if c.Path == launchOptions.CandidatePath {
+					consumer.Infof("Using candidate (%s) from launch options", c.Path)
+					candidates = []*dash.Candidate{c}
+					break
+				}
+			}
+		}
+
 		switch len(candidates) {
 		case 0:
 			return errors.WithStack(butlerd.CodeNoLaunchCandidates)
@@ -302,6 +367,11 @@ func Launch(rc *butlerd.RequestContext, params butlerd.LaunchParams) (*butlerd.L
 				return errors.WithStack(butlerd.CodeOperationAborted)
 			}
 			candidate = candidates[r.Index]
+
+			if r.Remember {
+				launchOptions.CandidatePath = candidate.Path
+				rc.WithConn(launchOptions.Save)
+			}
 		}
 
 		fullPath := filepath.Join(installFolder, candidate.Path)
@@ -329,7 +399,7 @@ func Launch(rc *butlerd.RequestContext, params butlerd.LaunchParams) (*butlerd.L
 		if verdict == nil {
 			consumer.Infof("No verdict, configuring now")
 
-			newVerdict, err := manager.Configure(consumer, installFolder, runtime)
+			newVerdict, err := manager.Configure(consumer, installFolder, targetRuntime)
 			if err != nil {
 				return nil, errors.WithStack(err)
 			}
@@ -356,7 +426,7 @@ func Launch(rc *butlerd.RequestContext, params butlerd.LaunchParams) (*butlerd.L
 				if redoReason != "" {
 					consumer.Warnf("%s Re-configuring...", redoReason)
 
-					newVerdict, err := manager.Configure(consumer, installFolder, runtime)
+					newVerdict, err := manager.Configure(consumer, installFolder, targetRuntime)
 					if err != nil {
 						return nil, errors.WithStack(err)
 					}
@@ -402,9 +472,14 @@ func Launch(rc *butlerd.RequestContext, params butlerd.LaunchParams) (*butlerd.L
 
 	var args = []string{}
 	var env = make(map[string]string)
+	var workingDir string
 
 	if manifestAction != nil {
-		args = append(args, manifestAction.Args...)
+		args = append(args, manifest.ExpandArgs(manifestAction, vars)...)
+		for k, v := range manifest.ExpandEnv(manifestAction, vars) {
+			env[k] = v
+		}
+		workingDir = manifest.ExpandWorkingDir(manifestAction, vars)
 
 		err = requestAPIKeyIfNecessary(rc, manifestAction, game, access, env)
 		if err != nil {
@@ -412,23 +487,63 @@ func Launch(rc *butlerd.RequestContext, params butlerd.LaunchParams) (*butlerd.L
 		}
 	}
 
+	for _, arg := range launchOptions.GetArgs() {
+		args = append(args, manifest.Expand(arg, vars))
+	}
+	for k, v := range launchOptions.GetEnv() {
+		env[k] = manifest.Expand(v, vars)
+	}
+
 	sandbox := params.Sandbox
 	if manifestAction != nil && manifestAction.Sandbox {
 		consumer.Infof("Enabling sandbox because of manifest opt-in")
 		sandbox = true
 	}
+	if launchOptions.Sandbox != nil {
+		sandbox = *launchOptions.Sandbox
+		consumer.Infof("Sandbox forced (%v) by launch options", sandbox)
+	}
+
+	sandboxBackend := params.SandboxBackend
+	if sandboxBackend == "" {
+		rc.WithConn(func(conn *sqlite.Conn) {
+			sandboxBackend = getSandboxBackend(conn)
+		})
+	}
+
+	launchID := uuid.New().String()
+	env[launchIDEnvVar] = launchID
+
+	launchCtx, cancelLaunch := context.WithCancel(rc.Ctx)
+	defer cancelLaunch()
+
+	session := &models.PlaySession{
+		ID:             launchID,
+		CaveID:         cave.ID,
+		GameID:         cave.GameID,
+		LaunchStrategy: string(strategy),
+	}
+	if manifestAction != nil {
+		session.ManifestAction = manifestAction.Name
+	}
 
 	launcherParams := LauncherParams{
 		RequestContext: rc,
-		Ctx:            rc.Ctx,
-
-		FullTargetPath: fullTargetPath,
-		Candidate:      candidate,
-		AppManifest:    appManifest,
-		Action:         manifestAction,
-		Sandbox:        sandbox,
-		Args:           args,
-		Env:            env,
+		Ctx:            launchCtx,
+
+		FullTargetPath:   fullTargetPath,
+		Candidate:        candidate,
+		AppManifest:      appManifest,
+		Action:           manifestAction,
+		Vars:             vars,
+		Sandbox:          sandbox,
+		SandboxBackend:   sandboxBackend,
+		SandboxNoNetwork: params.SandboxNoNetwork,
+		Args:             args,
+		Env:              env,
+		WorkingDir:       workingDir,
+		ServeHTML:        params.ServeHTML,
+		Compat:           compat,
 
 		PrereqsDir:    params.PrereqsDir,
 		ForcePrereqs:  params.ForcePrereqs,
@@ -445,13 +560,75 @@ func Launch(rc *butlerd.RequestContext, params butlerd.LaunchParams) (*butlerd.L
 
 			cave.RecordPlayTime(playTime)
 			rc.WithConn(cave.Save)
+
+			session.SecondsRun += int64(playTime.Seconds())
 			return nil
 		},
+
+		RecordExitCode: func(exitCode int64) {
+			session.ExitCode = &exitCode
+			// games we stopped ourselves didn't crash
+			session.Crashed = exitCode != 0 && launchCtx.Err() == nil
+		},
+
+		RecordCrash: func(crash *CrashInfo) {
+			err := recordCrash(rc, &crashContext{
+				LaunchID:      launchID,
+				Cave:          cave,
+				Upload:        upload,
+				Build:         build,
+				InstallFolder: installFolder,
+				Runtime:       runtime,
+			}, crash)
+			if err != nil {
+				consumer.Warnf("Could not record crash: %+v", err)
+			}
+		},
 	}
 
 	cave.Touch()
 	rc.WithConn(cave.Save)
 
+	running, finishRunning := newRunningCave(launchID, cave.ID, cancelLaunch)
+	if params.IfRunning == butlerd.IfRunningPolicyLaunch {
+		rc.RunningCaves.Add(running)
+	} else {
+		// another launch may have gotten here while we were preparing
+		if rc.RunningCaves.AddUnlessRunning(running) != nil {
+			return nil, errors.WithStack(butlerd.CodeAlreadyRunning)
+		}
+
+		held, err := acquireLaunchLock(installFolder, launchID)
+		if err != nil {
+			rc.RunningCaves.Remove(launchID)
+			return nil, errors.WithStack(err)
+		}
+		if held != nil {
+			rc.RunningCaves.Remove(launchID)
+			return nil, errors.WithStack(butlerd.CodeAlreadyRunning)
+		}
+		defer releaseLaunchLock(installFolder, launchID)
+	}
+	defer func() {
+		finishRunning()
+		rc.RunningCaves.Remove(launchID)
+		close(running.Done)
+	}()
+	go monitorResourceUsage(rc, launchID, running.Done)
+
+	session.Start()
+	rc.WithConn(session.Save)
+	defer func() {
+		session.End()
+		rc.WithConn(session.Save)
+
+		var backupFolder string
+		rc.WithConn(func(conn *sqlite.Conn) {
+			backupFolder = cave.GetSaveBackupFolder(conn)
+		})
+		snapshots.Auto(consumer, installFolder, backupFolder, butlerd.SaveSnapshotReasonPlaySession)
+	}()
+
 	err = launcher.Do(launcherParams)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -494,3 +671,16 @@ func requestAPIKeyIfNecessary(rc *butlerd.RequestContext, manifestAction *butler
 	env["ITCHIO_API_KEY_EXPIRES_AT"] = res.ExpiresAt
 	return nil
 }
+
+// launchRuntime returns the runtime launch targets are picked for.
+// Caves with a compatibility runner set launch Windows executables,
+// whatever the host platform is.
+func launchRuntime(host *ox.Runtime, compat *CompatParams) *ox.Runtime {
+	if compat == nil {
+		return host
+	}
+	return &ox.Runtime{
+		Platform: ox.PlatformWindows,
+		Is64:     host.Is64,
+	}
+}
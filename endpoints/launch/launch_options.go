@@ -0,0 +1,56 @@
+package launch
+
+import (
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/cmd/operate"
+	"github.com/itchio/butler/database/models"
+)
+
+func CavesSetLaunchOptions(rc *butlerd.RequestContext, params butlerd.CavesSetLaunchOptionsParams) (*butlerd.CavesSetLaunchOptionsResult, error) {
+	cave := operate.ValidateCave(rc, params.CaveID)
+	opts := params.Options
+
+	lo := &models.LaunchOptions{
+		CaveID:              cave.ID,
+		ManifestActionIndex: opts.ManifestActionIndex,
+		CandidatePath:       opts.CandidatePath,
+		Sandbox:             opts.Sandbox,
+	}
+	lo.SetArgs(opts.Args)
+	lo.SetEnv(opts.Env)
+	rc.WithConn(lo.Save)
+
+	res := &butlerd.CavesSetLaunchOptionsResult{}
+	return res, nil
+}
+
+func CavesGetLaunchOptions(rc *butlerd.RequestContext, params butlerd.CavesGetLaunchOptionsParams) (*butlerd.CavesGetLaunchOptionsResult, error) {
+	cave := operate.ValidateCave(rc, params.CaveID)
+
+	var lo *models.LaunchOptions
+	rc.WithConn(func(conn *sqlite.Conn) {
+		lo = getLaunchOptions(conn, cave.ID)
+	})
+
+	res := &butlerd.CavesGetLaunchOptionsResult{
+		Options: &butlerd.LaunchOptions{
+			Args:                lo.GetArgs(),
+			Env:                 lo.GetEnv(),
+			ManifestActionIndex: lo.ManifestActionIndex,
+			CandidatePath:       lo.CandidatePath,
+			Sandbox:             lo.Sandbox,
+		},
+	}
+	return res, nil
+}
+
+// getLaunchOptions returns the launch options of a cave,
+// or empty ones if none were set.
+func getLaunchOptions(conn *sqlite.Conn, caveID string) *models.LaunchOptions {
+	lo := models.LaunchOptionsByCaveID(conn, caveID)
+	if lo == nil {
+		lo = &models.LaunchOptions{CaveID: caveID}
+	}
+	return lo
+}
@@ -0,0 +1,115 @@
+package launch_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/butlerd/butlerdtest"
+	"github.com/itchio/butler/butlerd/messages"
+	"github.com/itchio/butler/database/models"
+	"github.com/itchio/butler/endpoints/launch"
+	"github.com/itchio/dash"
+	itchio "github.com/itchio/go-itchio"
+	"github.com/stretchr/testify/assert"
+)
+
+const twoActionsManifest = `
+[[actions]]
+name = "play"
+path = "game.sh"
+
+[[actions]]
+name = "editor"
+path = "editor.sh"
+args = ["--editor"]
+`
+
+type fakeLauncher struct {
+	params *launch.LauncherParams
+}
+
+func (fl *fakeLauncher) Do(params launch.LauncherParams) error {
+	fl.params = &params
+	return nil
+}
+
+func Test_LaunchOptions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts")
+	}
+
+	h := butlerdtest.New(t)
+	defer h.Close()
+
+	installFolder := filepath.Join(h.Dir, "garden")
+	assert.NoError(t, os.MkdirAll(installFolder, 0755))
+	for _, name := range []string{"game.sh", "editor.sh"} {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(installFolder, name), []byte("#!/bin/sh\n"), 0755))
+	}
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(installFolder, ".itch.toml"), []byte(twoActionsManifest), 0644))
+
+	h.RC.WithConn(func(conn *sqlite.Conn) {
+		models.MustSave(conn, &models.Profile{ID: 1, APIKey: "key"})
+		models.MustSave(conn, &itchio.Game{ID: 123, Title: "Garden"})
+		models.MustSave(conn, &models.InstallLocation{ID: "il", Path: h.Dir})
+		cave := &models.Cave{ID: "cave", GameID: 123, InstallLocationID: "il", InstallFolderName: "garden"}
+		cave.SetVerdict(&dash.Verdict{BasePath: installFolder})
+		models.MustSave(conn, cave)
+	})
+
+	fl := &fakeLauncher{}
+	launch.RegisterLauncher(launch.LaunchStrategyNative, fl)
+
+	h.Conn.OnCall(messages.PickManifestAction.Method(), func(ctx context.Context, method string, params interface{}, result interface{}) error {
+		t.Errorf("the player shouldn't be asked which action to launch")
+		return butlerd.CodeOperationAborted
+	})
+
+	index := int64(1)
+	noSandbox := false
+	opts := &butlerd.LaunchOptions{
+		Args:                []string{"--level", "{{installFolder}}/levels/1"},
+		Env:                 map[string]string{"GARDEN_DEBUG": "1"},
+		ManifestActionIndex: &index,
+		Sandbox:             &noSandbox,
+	}
+	_, err := launch.CavesSetLaunchOptions(h.RC, butlerd.CavesSetLaunchOptionsParams{CaveID: "cave", Options: opts})
+	assert.NoError(t, err)
+
+	getRes, err := launch.CavesGetLaunchOptions(h.RC, butlerd.CavesGetLaunchOptionsParams{CaveID: "cave"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, opts, getRes.Options, "launch options should be stored as-is")
+
+	_, err = launch.Launch(h.RC, butlerd.LaunchParams{
+		CaveID:     "cave",
+		PrereqsDir: filepath.Join(h.Dir, "prereqs"),
+		Sandbox:    true,
+	})
+	assert.NoError(t, err)
+
+	if assert.NotNil(t, fl.params, "the game should be launched") {
+		assert.EqualValues(t, "editor", fl.params.Action.Name)
+		assert.EqualValues(t, filepath.Join(installFolder, "editor.sh"), fl.params.FullTargetPath)
+		assert.EqualValues(t, []string{"--editor", "--level", installFolder + "/levels/1"}, fl.params.Args)
+		assert.EqualValues(t, "1", fl.params.Env["GARDEN_DEBUG"])
+		assert.False(t, fl.params.Sandbox, "launch options should win over launch params")
+	}
+}
+
+func Test_LaunchOptionsValidate(t *testing.T) {
+	index := int64(-1)
+	params := butlerd.CavesSetLaunchOptionsParams{
+		CaveID:  "cave",
+		Options: &butlerd.LaunchOptions{ManifestActionIndex: &index},
+	}
+	assert.Error(t, params.Validate())
+
+	index = 0
+	assert.NoError(t, params.Validate())
+}
@@ -0,0 +1,119 @@
+package launch
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/butlerd/butlerdtest"
+	"github.com/itchio/butler/cmd/operate/memorylogger"
+	"github.com/itchio/butler/database/models"
+	"github.com/itchio/butler/manager"
+	"github.com/itchio/dash"
+	itchio "github.com/itchio/go-itchio"
+	"github.com/itchio/ox"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePE returns the smallest thing that sniffs as a 32-bit
+// Windows executable
+func fakePE() []byte {
+	buf := make([]byte, 4096)
+	// DOS header, as written by most linkers
+	copy(buf, "MZ")
+	binary.LittleEndian.PutUint16(buf[0x02:], 0x90)
+	binary.LittleEndian.PutUint16(buf[0x04:], 3)
+	binary.LittleEndian.PutUint16(buf[0x08:], 4)
+	binary.LittleEndian.PutUint16(buf[0x0c:], 0xffff)
+	binary.LittleEndian.PutUint16(buf[0x18:], 0x40)
+	binary.LittleEndian.PutUint32(buf[0x3c:], 0x40)
+	copy(buf[0x40:], "PE\x00\x00")
+	// i386, no sections, optional header follows
+	binary.LittleEndian.PutUint16(buf[0x44:], 0x14c)
+	binary.LittleEndian.PutUint16(buf[0x54:], 0xe0)
+	binary.LittleEndian.PutUint16(buf[0x56:], 0x0102)
+	binary.LittleEndian.PutUint16(buf[0x58:], 0x10b)
+	// Windows GUI subsystem
+	binary.LittleEndian.PutUint16(buf[0x58+68:], 2)
+	return buf
+}
+
+func Test_LaunchRuntimeWindowsOnly(t *testing.T) {
+	installFolder, err := ioutil.TempDir("", "launch-runtime")
+	assert.NoError(t, err)
+	defer os.RemoveAll(installFolder)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(installFolder, "Game.exe"), fakePE(), 0644))
+
+	consumer := memorylogger.New().Consumer()
+	host := &ox.Runtime{Platform: ox.PlatformLinux, Is64: true}
+
+	verdict, err := manager.Configure(consumer, installFolder, launchRuntime(host, nil))
+	assert.NoError(t, err)
+	assert.Empty(t, verdict.Candidates)
+
+	compat := &CompatParams{Command: "wine", PrefixDir: filepath.Join(installFolder, "prefix")}
+	target := launchRuntime(host, compat)
+	assert.EqualValues(t, ox.PlatformWindows, target.Platform)
+	assert.True(t, target.Is64)
+
+	verdict, err = manager.Configure(consumer, installFolder, target)
+	assert.NoError(t, err)
+	if assert.Len(t, verdict.Candidates, 1) {
+		assert.EqualValues(t, "Game.exe", verdict.Candidates[0].Path)
+	}
+}
+
+type recordingLauncher struct {
+	params *LauncherParams
+}
+
+func (rl *recordingLauncher) Do(params LauncherParams) error {
+	rl.params = &params
+	return nil
+}
+
+func Test_LaunchCompatManifestVars(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("compatibility runners are only used on Linux")
+	}
+
+	h := butlerdtest.New(t)
+	defer h.Close()
+
+	installFolder := filepath.Join(h.Dir, "garden")
+	assert.NoError(t, os.MkdirAll(installFolder, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(installFolder, "Garden.exe"), fakePE(), 0644))
+	manifestContents := "[[actions]]\nname = \"play\"\npath = \"Garden{{EXT}}\"\nargs = [\"--platform={{platform}}\"]\n"
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(installFolder, ".itch.toml"), []byte(manifestContents), 0644))
+
+	h.RC.WithConn(func(conn *sqlite.Conn) {
+		models.MustSave(conn, &models.Profile{ID: 1, APIKey: "key"})
+		models.MustSave(conn, &itchio.Game{ID: 123, Title: "Garden"})
+		models.MustSave(conn, &models.InstallLocation{ID: "il", Path: h.Dir})
+		models.MustSave(conn, &models.CompatRunner{ID: "wine", Name: "Wine", Kind: string(butlerd.CompatRunnerKindWine), Command: "/usr/bin/wine"})
+		cave := &models.Cave{ID: "cave", GameID: 123, InstallLocationID: "il", InstallFolderName: "garden", CompatRunnerID: "wine"}
+		cave.SetVerdict(&dash.Verdict{BasePath: installFolder})
+		models.MustSave(conn, cave)
+	})
+
+	rl := &recordingLauncher{}
+	RegisterLauncher(LaunchStrategyNative, rl)
+
+	_, err := Launch(h.RC, butlerd.LaunchParams{
+		CaveID:     "cave",
+		PrereqsDir: filepath.Join(h.Dir, "prereqs"),
+	})
+	assert.NoError(t, err)
+
+	if assert.NotNil(t, rl.params, "the game should be launched") {
+		assert.EqualValues(t, filepath.Join(installFolder, "Garden.exe"), rl.params.FullTargetPath)
+		assert.EqualValues(t, []string{"--platform=windows"}, rl.params.Args)
+		assert.NotNil(t, rl.params.Compat)
+	}
+}
@@ -20,12 +20,26 @@ type Launcher struct{}
 var _ launch.Launcher = (*Launcher)(nil)
 
 func (l *Launcher) Do(params launch.LauncherParams) error {
+	consumer := params.RequestContext.Consumer
+
 	rootFolder := params.InstallFolder
 	indexPath, err := filepath.Rel(rootFolder, params.FullTargetPath)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
+	var indexURL string
+	if params.ServeHTML {
+		s, err := startServer(consumer, rootFolder)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer s.stop()
+
+		indexURL = s.URL(indexPath)
+		consumer.Infof("Serving (%s) at (%s)", rootFolder, indexURL)
+	}
+
 	startTime := time.Now()
 
 	messages.LaunchRunning.Notify(params.RequestContext, butlerd.LaunchRunningNotification{})
@@ -34,6 +48,7 @@ func (l *Launcher) Do(params launch.LauncherParams) error {
 		IndexPath:  indexPath,
 		Args:       params.Args,
 		Env:        params.Env,
+		URL:        indexURL,
 	})
 	messages.LaunchExited.Notify(params.RequestContext, butlerd.LaunchExitedNotification{})
 	if err != nil {
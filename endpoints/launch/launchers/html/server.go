@@ -0,0 +1,229 @@
+package html
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/itchio/wharf/state"
+	"github.com/pkg/errors"
+)
+
+// mimeTypes takes precedence over the system's MIME database,
+// which is often incomplete (or wrong) for web game assets.
+var mimeTypes = map[string]string{
+	".html":  "text/html; charset=utf-8",
+	".htm":   "text/html; charset=utf-8",
+	".js":    "application/javascript",
+	".mjs":   "application/javascript",
+	".css":   "text/css; charset=utf-8",
+	".json":  "application/json",
+	".wasm":  "application/wasm",
+	".data":  "application/octet-stream",
+	".mem":   "application/octet-stream",
+	".pck":   "application/octet-stream",
+	".png":   "image/png",
+	".jpg":   "image/jpeg",
+	".jpeg":  "image/jpeg",
+	".gif":   "image/gif",
+	".svg":   "image/svg+xml",
+	".ico":   "image/x-icon",
+	".webp":  "image/webp",
+	".ogg":   "audio/ogg",
+	".mp3":   "audio/mpeg",
+	".wav":   "audio/wav",
+	".mp4":   "video/mp4",
+	".webm":  "video/webm",
+	".txt":   "text/plain; charset=utf-8",
+	".xml":   "text/xml; charset=utf-8",
+	".ttf":   "font/ttf",
+	".otf":   "font/otf",
+	".woff":  "font/woff",
+	".woff2": "font/woff2",
+}
+
+// encodings lists precompressed variants we know how to serve,
+// in order of preference
+var encodings = []struct {
+	ext      string
+	encoding string
+}{
+	{".br", "br"},
+	{".gz", "gzip"},
+}
+
+func contentType(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	if ct, ok := mimeTypes[ext]; ok {
+		return ct
+	}
+	if ct := mime.TypeByExtension(ext); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
+
+// fileServer serves a folder with correct MIME types, precompressed
+// assets, and the cross-origin isolation headers required for
+// SharedArrayBuffer.
+type fileServer struct {
+	root string
+}
+
+func (fs *fileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	h := w.Header()
+	h.Set("Cross-Origin-Opener-Policy", "same-origin")
+	h.Set("Cross-Origin-Embedder-Policy", "require-corp")
+	h.Set("Cross-Origin-Resource-Policy", "same-origin")
+	h.Set("Cache-Control", "no-cache")
+
+	name := path.Clean("/" + r.URL.Path)
+	fullPath := filepath.Join(fs.root, filepath.FromSlash(name))
+	if stats, err := os.Stat(fullPath); err == nil && stats.IsDir() {
+		name = path.Join(name, "index.html")
+		fullPath = filepath.Join(fullPath, "index.html")
+	}
+
+	// requests for `game.data.br` are served as `game.data` with
+	// a content encoding, that's what Unity WebGL builds expect
+	for _, e := range encodings {
+		if strings.HasSuffix(name, e.ext) {
+			if fs.serveFile(w, r, fullPath, strings.TrimSuffix(name, e.ext), e.encoding) {
+				return
+			}
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	// if the file only exists in precompressed form, serve that
+	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+		accepted := r.Header.Get("Accept-Encoding")
+		for _, e := range encodings {
+			if !strings.Contains(accepted, e.encoding) {
+				continue
+			}
+			if fs.serveFile(w, r, fullPath+e.ext, name, e.encoding) {
+				return
+			}
+		}
+	}
+
+	if fs.serveFile(w, r, fullPath, name, "") {
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// serveFile serves the file at fullPath with the content type of
+// name. Returns false if the file could not be opened.
+func (fs *fileServer) serveFile(w http.ResponseWriter, r *http.Request, fullPath string, name string, encoding string) bool {
+	f, err := os.Open(fullPath)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	stats, err := f.Stat()
+	if err != nil || stats.IsDir() {
+		return false
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", contentType(name))
+	if encoding != "" {
+		h.Set("Content-Encoding", encoding)
+		h.Add("Vary", "Accept-Encoding")
+	}
+	http.ServeContent(w, r, "", stats.ModTime(), f)
+	return true
+}
+
+// guard only lets through requests addressed to host, so that pages
+// resolving their own domain to the loopback address (DNS rebinding)
+// can't read the game files.
+type guard struct {
+	host    string
+	handler http.Handler
+}
+
+func (g *guard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Host != g.host {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	g.handler.ServeHTTP(w, r)
+}
+
+type server struct {
+	listener net.Listener
+	srv      *http.Server
+	token    string
+}
+
+// startServer serves rootFolder on a random loopback port,
+// until stop is called. Files are only served under a random
+// per-launch path prefix, see URL.
+func startServer(consumer *state.Consumer, rootFolder string) (*server, error) {
+	tokenBytes := make([]byte, 16)
+	_, err := rand.Read(tokenBytes)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	s := &server{
+		listener: listener,
+		token:    token,
+		srv: &http.Server{
+			Handler: &guard{
+				host:    listener.Addr().String(),
+				handler: http.StripPrefix("/"+token, &fileServer{root: rootFolder}),
+			},
+		},
+	}
+
+	go func() {
+		err := s.srv.Serve(listener)
+		if err != nil && err != http.ErrServerClosed {
+			consumer.Warnf("HTML5 server stopped: %+v", err)
+		}
+	}()
+
+	return s, nil
+}
+
+// URL returns the address of a file relative to the served folder
+func (s *server) URL(relPath string) string {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   s.listener.Addr().String(),
+		Path:   "/" + s.token + "/" + filepath.ToSlash(relPath),
+	}
+	return u.String()
+}
+
+func (s *server) stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.srv.Shutdown(ctx)
+}
@@ -0,0 +1,117 @@
+package html
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/itchio/wharf/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FileServer(t *testing.T) {
+	root, err := ioutil.TempDir("", "html-server")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	write := func(name string, contents string) {
+		fullPath := filepath.Join(root, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
+		assert.NoError(t, ioutil.WriteFile(fullPath, []byte(contents), 0644))
+	}
+	write("index.html", "<html></html>")
+	write("Build/game.wasm.br", "brotli-wasm")
+	write("Build/game.data.gz", "gzip-data")
+	write("Build/loader.js", "loader")
+
+	ts := httptest.NewServer(&fileServer{root: root})
+	defer ts.Close()
+
+	get := func(path string, acceptEncoding string) (*http.Response, string) {
+		req, err := http.NewRequest("GET", ts.URL+path, nil)
+		assert.NoError(t, err)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		// don't let the transport decompress for us
+		res, err := (&http.Transport{DisableCompression: true}).RoundTrip(req)
+		assert.NoError(t, err)
+		defer res.Body.Close()
+		body, err := ioutil.ReadAll(res.Body)
+		assert.NoError(t, err)
+		return res, string(body)
+	}
+
+	res, body := get("/", "")
+	assert.EqualValues(t, 200, res.StatusCode)
+	assert.EqualValues(t, "<html></html>", body)
+	assert.EqualValues(t, "text/html; charset=utf-8", res.Header.Get("Content-Type"))
+	assert.EqualValues(t, "same-origin", res.Header.Get("Cross-Origin-Opener-Policy"))
+	assert.EqualValues(t, "require-corp", res.Header.Get("Cross-Origin-Embedder-Policy"))
+
+	res, body = get("/Build/game.wasm.br", "")
+	assert.EqualValues(t, 200, res.StatusCode)
+	assert.EqualValues(t, "brotli-wasm", body)
+	assert.EqualValues(t, "application/wasm", res.Header.Get("Content-Type"))
+	assert.EqualValues(t, "br", res.Header.Get("Content-Encoding"))
+
+	res, body = get("/Build/game.data", "gzip, deflate")
+	assert.EqualValues(t, 200, res.StatusCode)
+	assert.EqualValues(t, "gzip-data", body)
+	assert.EqualValues(t, "application/octet-stream", res.Header.Get("Content-Type"))
+	assert.EqualValues(t, "gzip", res.Header.Get("Content-Encoding"))
+
+	res, _ = get("/Build/game.data", "")
+	assert.EqualValues(t, 404, res.StatusCode)
+
+	res, body = get("/Build/loader.js", "br")
+	assert.EqualValues(t, 200, res.StatusCode)
+	assert.EqualValues(t, "loader", body)
+	assert.EqualValues(t, "application/javascript", res.Header.Get("Content-Type"))
+	assert.EqualValues(t, "", res.Header.Get("Content-Encoding"))
+
+	res, _ = get("/../../etc/passwd", "")
+	assert.EqualValues(t, 404, res.StatusCode)
+}
+
+func Test_Server(t *testing.T) {
+	root, err := ioutil.TempDir("", "html-server")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(root, "index.html"), []byte("<html></html>"), 0644))
+
+	s, err := startServer(&state.Consumer{}, root)
+	assert.NoError(t, err)
+	defer s.stop()
+
+	get := func(url string, host string) int {
+		req, err := http.NewRequest("GET", url, nil)
+		assert.NoError(t, err)
+		if host != "" {
+			req.Host = host
+		}
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	indexURL := s.URL("index.html")
+	assert.EqualValues(t, 200, get(indexURL, ""))
+
+	// files aren't served without the per-launch token
+	assert.EqualValues(t, 404, get(strings.Replace(indexURL, s.token+"/", "", 1), ""))
+
+	// DNS rebinding: right address, wrong host
+	addr := s.listener.Addr().String()
+	port := addr[strings.LastIndex(addr, ":"):]
+	assert.EqualValues(t, 403, get(indexURL, "evil.example"+port))
+	assert.EqualValues(t, 403, get(indexURL, "localhost"+port))
+
+	other, err := startServer(&state.Consumer{}, root)
+	assert.NoError(t, err)
+	defer other.stop()
+	assert.NotEqual(t, s.token, other.token)
+}
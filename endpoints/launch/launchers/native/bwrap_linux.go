@@ -0,0 +1,191 @@
+// +build linux
+
+package native
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/itchio/smaug/runner"
+	"github.com/pkg/errors"
+)
+
+type bwrapParams struct {
+	// Defaults to `bwrap` from $PATH
+	BinaryPath string
+
+	// If true, the game gets its own network namespace with
+	// only a loopback interface
+	NoNetwork bool
+
+	// The user's real home, hidden from the game. Defaults to $HOME
+	HomeDir string
+}
+
+// bwrapSaveDirs are the folders in the sandboxed home that are
+// persisted to the install folder, since that's where most games
+// store their saves and settings on Linux.
+var bwrapSaveDirs = []struct {
+	homePath string
+	name     string
+}{
+	{".config", "config"},
+	{filepath.Join(".local", "share"), "data"},
+}
+
+// bwrapRunner runs games through bubblewrap, with a profile similar
+// to the firejail one: the whole filesystem is read-only, the install
+// folder included, and the game gets a private home folder in which
+// only a few well-known save folders are writable.
+type bwrapRunner struct {
+	params *runner.RunnerParams
+	bwrap  bwrapParams
+}
+
+var _ runner.Runner = (*bwrapRunner)(nil)
+
+func newBwrapRunner(params *runner.RunnerParams, bp bwrapParams) (runner.Runner, error) {
+	if bp.BinaryPath == "" {
+		binaryPath, err := exec.LookPath(bwrapBinary)
+		if err != nil {
+			return nil, errors.Wrap(err, "bubblewrap sandbox requested, but bwrap is not installed")
+		}
+		bp.BinaryPath = binaryPath
+	}
+
+	if bp.HomeDir == "" {
+		bp.HomeDir = os.Getenv("HOME")
+	}
+
+	br := &bwrapRunner{
+		params: params,
+		bwrap:  bp,
+	}
+	return br, nil
+}
+
+// bwrapSaveDir returns where a sandboxed game's saves are persisted
+func bwrapSaveDir(installFolder string) string {
+	return filepath.Join(installFolder, ".itch", "sandbox")
+}
+
+func (br *bwrapRunner) Prepare() error {
+	for _, d := range bwrapSaveDirs {
+		err := os.MkdirAll(filepath.Join(bwrapSaveDir(br.params.InstallFolder), d.name), 0755)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func (br *bwrapRunner) Run() error {
+	params := br.params
+	consumer := params.Consumer
+
+	args := bwrapArgs(params, br.bwrap)
+	consumer.Opf("Running (%s) through bubblewrap", params.FullTargetPath)
+	consumer.Debugf("bwrap args: %s", strings.Join(args, " "))
+
+	cmd := exec.Command(br.bwrap.BinaryPath, args...)
+	cmd.Dir = params.Dir
+	cmd.Env = params.Env
+	cmd.Stdout = params.Stdout
+	cmd.Stderr = params.Stderr
+
+	pg, err := runner.NewProcessGroup(consumer, cmd, params.Ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = pg.AfterStart()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = pg.Wait()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// bwrapArgs returns the command-line arguments for bwrap. Order
+// matters: later mounts are stacked on top of earlier ones.
+func bwrapArgs(params *runner.RunnerParams, bp bwrapParams) []string {
+	args := []string{
+		"--ro-bind", "/", "/",
+		"--dev", "/dev",
+		"--proc", "/proc",
+		"--tmpfs", "/tmp",
+		"--unshare-pid",
+		"--unshare-ipc",
+		"--unshare-uts",
+		"--die-with-parent",
+		"--new-session",
+	}
+
+	if bp.NoNetwork {
+		args = append(args, "--unshare-net")
+	}
+
+	// X11 sockets live in /tmp, which we just hid
+	const x11Dir = "/tmp/.X11-unix"
+	if _, err := os.Stat(x11Dir); err == nil {
+		args = append(args, "--ro-bind", x11Dir, x11Dir)
+	}
+
+	if bp.HomeDir != "" {
+		args = append(args, "--tmpfs", bp.HomeDir)
+
+		// without it, games can't talk to the X server
+		xauthority := filepath.Join(bp.HomeDir, ".Xauthority")
+		if v, ok := lookupEnvBlock(params.Env, "XAUTHORITY"); ok {
+			xauthority = v
+		}
+		if _, err := os.Stat(xauthority); err == nil {
+			args = append(args, "--ro-bind", xauthority, xauthority)
+		}
+
+		saveDir := bwrapSaveDir(params.InstallFolder)
+		for _, d := range bwrapSaveDirs {
+			args = append(args, "--bind", filepath.Join(saveDir, d.name), filepath.Join(bp.HomeDir, d.homePath))
+		}
+	}
+
+	// the install folder is often in the user's home, so it
+	// has to be mounted again after the private home.
+	args = append(args, "--ro-bind", params.InstallFolder, params.InstallFolder)
+
+	// ...except for the game's temporary directory
+	tempDir := filepath.Join(params.InstallFolder, ".itch", "temp")
+	if _, err := os.Stat(tempDir); err == nil {
+		args = append(args, "--bind", tempDir, tempDir)
+	}
+
+	if params.Dir != "" {
+		args = append(args, "--chdir", params.Dir)
+	}
+
+	args = append(args, "--", params.FullTargetPath)
+	args = append(args, params.Args...)
+	return args
+}
+
+func lookupEnvBlock(env []string, key string) (string, bool) {
+	// later entries override earlier ones
+	for i := len(env) - 1; i >= 0; i-- {
+		if strings.HasPrefix(env[i], key+"=") {
+			return strings.TrimPrefix(env[i], key+"="), true
+		}
+	}
+	return "", false
+}
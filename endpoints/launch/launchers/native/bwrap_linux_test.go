@@ -0,0 +1,104 @@
+// +build linux
+
+package native
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/itchio/ox"
+	"github.com/itchio/smaug/runner"
+	"github.com/itchio/wharf/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BwrapArgs(t *testing.T) {
+	params := &runner.RunnerParams{
+		FullTargetPath: "/home/player/.config/itch/apps/garden/garden",
+		Dir:            "/home/player/.config/itch/apps/garden",
+		Args:           []string{"--windowed"},
+		InstallFolder:  "/home/player/.config/itch/apps/garden",
+	}
+
+	args := strings.Join(bwrapArgs(params, bwrapParams{HomeDir: "/home/player"}), " ")
+	assert.Contains(t, args, "--ro-bind / /")
+	assert.Contains(t, args, "--tmpfs /home/player")
+	assert.Contains(t, args, "--bind /home/player/.config/itch/apps/garden/.itch/sandbox/data /home/player/.local/share")
+	assert.NotContains(t, args, "--unshare-net")
+	assert.True(t, strings.HasSuffix(args, "--chdir /home/player/.config/itch/apps/garden -- /home/player/.config/itch/apps/garden/garden --windowed"))
+
+	// the install folder must be mounted read-only after the private home
+	assert.True(t, strings.Index(args, "--tmpfs /home/player") < strings.Index(args, "--ro-bind /home/player/.config/itch/apps/garden /home"))
+
+	args = strings.Join(bwrapArgs(params, bwrapParams{HomeDir: "/home/player", NoNetwork: true}), " ")
+	assert.Contains(t, args, "--unshare-net")
+}
+
+const bwrapTestProgram = `#!/bin/sh
+echo "home=$(ls -A "$HOME" | grep -v '^\.' | tr '\n' ' ')"
+if touch "$INSTALL_FOLDER/escape" 2>/dev/null; then echo "install=writable"; else echo "install=readonly"; fi
+if echo saved > "$HOME/.local/share/save.txt"; then echo "save=writable"; fi
+echo "interfaces=$(tail -n +3 /proc/net/dev | wc -l)"
+`
+
+func Test_BwrapSandbox(t *testing.T) {
+	if !bwrapWorks() {
+		t.Skip("bwrap not installed, or can't create namespaces here")
+	}
+
+	dir, err := ioutil.TempDir("", "bwrap")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	home := filepath.Join(dir, "home")
+	installFolder := filepath.Join(home, "games", "garden")
+	assert.NoError(t, os.MkdirAll(installFolder, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(home, "secret"), []byte("hunter2"), 0644))
+
+	target := filepath.Join(installFolder, "garden.sh")
+	assert.NoError(t, ioutil.WriteFile(target, []byte(bwrapTestProgram), 0755))
+
+	run := func(noNetwork bool) string {
+		var stdout bytes.Buffer
+		params := &runner.RunnerParams{
+			Consumer:       &state.Consumer{},
+			Ctx:            context.Background(),
+			Sandbox:        true,
+			FullTargetPath: target,
+			Dir:            installFolder,
+			Env: []string{
+				"HOME=" + home,
+				"PATH=" + os.Getenv("PATH"),
+				"INSTALL_FOLDER=" + installFolder,
+			},
+			Stdout:        &stdout,
+			Stderr:        os.Stderr,
+			InstallFolder: installFolder,
+			Runtime:       ox.CurrentRuntime(),
+		}
+
+		r, err := newBwrapRunner(params, bwrapParams{HomeDir: home, NoNetwork: noNetwork})
+		assert.NoError(t, err)
+		assert.NoError(t, r.Prepare())
+		assert.NoError(t, r.Run())
+		return stdout.String()
+	}
+
+	out := run(true)
+	assert.Contains(t, out, "install=readonly")
+	assert.Contains(t, out, "save=writable")
+	assert.NotContains(t, out, "secret")
+	assert.Contains(t, out, "interfaces=1")
+
+	saved, err := ioutil.ReadFile(filepath.Join(bwrapSaveDir(installFolder), "data", "save.txt"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "saved\n", string(saved))
+
+	_, err = os.Stat(filepath.Join(installFolder, "escape"))
+	assert.True(t, os.IsNotExist(err))
+}
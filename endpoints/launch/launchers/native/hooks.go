@@ -0,0 +1,94 @@
+package native
+
+import (
+	"context"
+	"time"
+
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/endpoints/launch"
+	"github.com/itchio/butler/endpoints/launch/manifest"
+	"github.com/itchio/butler/installer/loggerwriter"
+	"github.com/itchio/smaug/runner"
+	"github.com/pkg/errors"
+)
+
+const defaultHookTimeout = 60 * time.Second
+
+// runHooks runs all manifest hooks for the given event, one after
+// the other, in the install folder, with the same environment and
+// sandbox settings as the game itself. Each hook gets its own timeout,
+// derived from parentCtx.
+func (l *Launcher) runHooks(parentCtx context.Context, params launch.LauncherParams, event butlerd.HookEvent, envBlock []string) error {
+	consumer := params.RequestContext.Consumer
+
+	if params.AppManifest == nil {
+		return nil
+	}
+
+	hooks := manifest.ListHooks(params.AppManifest, params.Runtime, event)
+	for i, h := range hooks {
+		hookPath := manifest.ExpandHookPath(h, params.Vars)
+
+		timeout := defaultHookTimeout
+		if h.Timeout > 0 {
+			timeout = time.Duration(h.Timeout) * time.Second
+		}
+
+		consumer.Infof("→ Running %s hook %d/%d (%s), timeout %s", event, i+1, len(hooks), hookPath, timeout)
+
+		err := func() error {
+			ctx, cancel := context.WithTimeout(parentCtx, timeout)
+			defer cancel()
+
+			runParams := &runner.RunnerParams{
+				Consumer: consumer,
+				Ctx:      ctx,
+
+				Sandbox: params.Sandbox,
+
+				FullTargetPath: hookPath,
+
+				Name:   hookPath,
+				Dir:    params.InstallFolder,
+				Args:   manifest.ExpandHookArgs(h, params.Vars),
+				Env:    envBlock,
+				Stdout: loggerwriter.New(consumer, "out"),
+				Stderr: loggerwriter.New(consumer, "err"),
+
+				InstallFolder: params.InstallFolder,
+				Runtime:       params.Runtime,
+
+				FirejailParams: l.FirejailParams(params),
+				FujiParams:     l.FujiParams(params),
+			}
+
+			run, err := l.getRunner(params, runParams)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			err = run.Prepare()
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			exitCode, err := interpretRunError(run.Run())
+			if ctx.Err() == context.DeadlineExceeded {
+				return errors.Errorf("timed out after %s", timeout)
+			}
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			if exitCode != 0 {
+				return errors.Errorf("exit code %d", exitCode)
+			}
+			return nil
+		}()
+		if err != nil {
+			return errors.WithMessage(err, hookPath)
+		}
+	}
+
+	return nil
+}
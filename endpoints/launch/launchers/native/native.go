@@ -1,6 +1,7 @@
 package native
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -49,6 +50,11 @@ func (l *Launcher) Do(params launch.LauncherParams) error {
 		cwd = filepath.Dir(params.FullTargetPath)
 	}
 
+	if params.WorkingDir != "" {
+		consumer.Infof("Using working directory (%s) from manifest", params.WorkingDir)
+		cwd = params.WorkingDir
+	}
+
 	_, err = os.Stat(params.FullTargetPath)
 	if err != nil {
 		return errors.WithStack(err)
@@ -64,6 +70,10 @@ func (l *Launcher) Do(params launch.LauncherParams) error {
 		consumer.Warnf("Could not determine PE info: %s", err.Error())
 	}
 
+	if params.Sandbox {
+		params.SandboxBackend = resolveSandboxBackend(params)
+	}
+
 	err = handlePrereqs(params)
 	if err != nil {
 		if be, ok := butlerd.AsButlerdError(err); ok {
@@ -130,6 +140,12 @@ func (l *Launcher) Do(params launch.LauncherParams) error {
 		envBlock = append(envBlock, fmt.Sprintf("%s=%s", k, v))
 	}
 
+	err = l.runHooks(params.Ctx, params, butlerd.HookEventPreLaunch, envBlock)
+	if err != nil {
+		consumer.Errorf("Pre-launch hook failed: %+v", err)
+		return errors.WithMessage(butlerd.CodePreLaunchHookFailed, err.Error())
+	}
+
 	const maxLines = 40
 	stdout := newOutputCollector(maxLines)
 	stderr := newOutputCollector(maxLines)
@@ -145,6 +161,21 @@ func (l *Launcher) Do(params launch.LauncherParams) error {
 		fullTargetPath = "love"
 	}
 
+	if params.Compat != nil && isWindowsExecutable(params) {
+		var compatEnv map[string]string
+		fullTargetPath, args, compatEnv = params.Compat.Wrap(fullTargetPath, args)
+		name = fullTargetPath
+		for k, v := range compatEnv {
+			envBlock = append(envBlock, fmt.Sprintf("%s=%s", k, v))
+		}
+		consumer.Infof("Launching through compatibility runner (%s)", fullTargetPath)
+
+		err = os.MkdirAll(params.Compat.PrefixDir, 0755)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
 	runParams := &runner.RunnerParams{
 		Consumer: consumer,
 		Ctx:      params.Ctx,
@@ -168,7 +199,7 @@ func (l *Launcher) Do(params launch.LauncherParams) error {
 		FujiParams:     l.FujiParams(params),
 	}
 
-	run, err := runner.GetRunner(runParams)
+	run, err := l.getRunner(params, runParams)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -182,7 +213,8 @@ func (l *Launcher) Do(params launch.LauncherParams) error {
 		startTime := time.Now()
 
 		messages.LaunchRunning.Notify(params.RequestContext, butlerd.LaunchRunningNotification{})
-		exitCode, err := interpretRunError(run.Run())
+		runErr := run.Run()
+		exitCode, err := interpretRunError(runErr)
 		messages.LaunchExited.Notify(params.RequestContext, butlerd.LaunchExitedNotification{})
 		if err != nil {
 			return errors.WithStack(err)
@@ -194,17 +226,33 @@ func (l *Launcher) Do(params launch.LauncherParams) error {
 			return errors.WithStack(err)
 		}
 
-		if exitCode != 0 {
-			var signedExitCode = int64(exitCode)
-			if runtime.GOOS == "windows" {
-				// Windows uses 32-bit unsigned integers as exit codes, although the
-				// command interpreter treats them as signed. If a process fails
-				// initialization, a Windows system error code may be returned.
-				signedExitCode = int64(int32(signedExitCode))
-
-				// The line above turns `4294967295` into -1
-			}
+		var signedExitCode = int64(exitCode)
+		if runtime.GOOS == "windows" {
+			// Windows uses 32-bit unsigned integers as exit codes, although the
+			// command interpreter treats them as signed. If a process fails
+			// initialization, a Windows system error code may be returned.
+			signedExitCode = int64(int32(signedExitCode))
 
+			// The line above turns `4294967295` into -1
+		}
+
+		if params.RecordExitCode != nil {
+			params.RecordExitCode(signedExitCode)
+		}
+
+		// games we stopped ourselves didn't crash
+		if exitCode != 0 && params.Ctx.Err() == nil && params.RecordCrash != nil {
+			params.RecordCrash(&launch.CrashInfo{
+				ExitCode: signedExitCode,
+				Signal:   exitSignal(runErr),
+				Duration: runDuration,
+				Target:   params.FullTargetPath,
+				Stdout:   stdout.Lines(),
+				Stderr:   stderr.Lines(),
+			})
+		}
+
+		if exitCode != 0 {
 			exeName := filepath.Base(params.FullTargetPath)
 			msg := fmt.Sprintf("Exit code 0x%x (%d) for (%s)", uint32(exitCode), signedExitCode, exeName)
 			consumer.Warnf(msg)
@@ -219,6 +267,13 @@ func (l *Launcher) Do(params launch.LauncherParams) error {
 		return nil
 	}()
 
+	// the launch context is cancelled when the game is stopped,
+	// post-exit hooks still need to run then.
+	hookErr := l.runHooks(context.Background(), params, butlerd.HookEventPostExit, envBlock)
+	if hookErr != nil {
+		consumer.Warnf("Post-exit hook failed: %+v", hookErr)
+	}
+
 	if err != nil {
 		consumer.Errorf("Had error: %s", err.Error())
 		if len(stderr.Lines()) == 0 {
@@ -307,6 +362,13 @@ func (l *Launcher) AttachParams(params launch.LauncherParams) runner.AttachParam
 	}
 }
 
+func isWindowsExecutable(params launch.LauncherParams) bool {
+	if params.Candidate != nil {
+		return params.Candidate.Flavor == dash.FlavorNativeWindows
+	}
+	return strings.HasSuffix(strings.ToLower(params.FullTargetPath), ".exe")
+}
+
 func configureTargetIfNeeded(params launch.LauncherParams) error {
 	if params.Candidate != nil {
 		// already configured
@@ -372,6 +434,17 @@ func interpretRunError(err error) (int, error) {
 	return 0, nil
 }
 
+// exitSignal returns the name of the signal that killed
+// a process, or an empty string
+func exitSignal(err error) string {
+	if exitError, ok := AsExitError(err); ok {
+		if status, ok := exitError.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			return status.Signal().String()
+		}
+	}
+	return ""
+}
+
 type causer interface {
 	Cause() error
 }
@@ -0,0 +1,55 @@
+// +build linux
+
+package native
+
+import (
+	"os/exec"
+
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/endpoints/launch"
+	"github.com/itchio/smaug/runner"
+)
+
+const bwrapBinary = "bwrap"
+
+// resolveSandboxBackend returns the sandbox backend requested
+// in params, or bubblewrap if it works here, or firejail.
+func resolveSandboxBackend(params launch.LauncherParams) butlerd.SandboxBackend {
+	if params.SandboxBackend != "" {
+		return params.SandboxBackend
+	}
+
+	if bwrapWorks() {
+		return butlerd.SandboxBackendBubblewrap
+	}
+	return butlerd.SandboxBackendFirejail
+}
+
+// bwrapWorks returns true if bwrap is installed and can create
+// namespaces, which some kernels and containers don't allow.
+func bwrapWorks() bool {
+	bwrapPath, err := exec.LookPath(bwrapBinary)
+	if err != nil {
+		return false
+	}
+	return exec.Command(bwrapPath, "--ro-bind", "/", "/", "--unshare-net", "true").Run() == nil
+}
+
+func (l *Launcher) getRunner(params launch.LauncherParams, runParams *runner.RunnerParams) (runner.Runner, error) {
+	if !runParams.Sandbox {
+		return runner.GetRunner(runParams)
+	}
+
+	backend := resolveSandboxBackend(params)
+	runParams.Consumer.Infof("Using (%s) sandbox backend", backend)
+	if backend == butlerd.SandboxBackendBubblewrap {
+		return newBwrapRunner(runParams, bwrapParams{
+			NoNetwork: params.SandboxNoNetwork,
+		})
+	}
+
+	if params.SandboxNoNetwork {
+		runParams.Consumer.Warnf("The firejail sandbox backend does not support disabling network access")
+	}
+	return runner.GetRunner(runParams)
+}
@@ -0,0 +1,17 @@
+// +build !linux
+
+package native
+
+import (
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/endpoints/launch"
+	"github.com/itchio/smaug/runner"
+)
+
+func resolveSandboxBackend(params launch.LauncherParams) butlerd.SandboxBackend {
+	return params.SandboxBackend
+}
+
+func (l *Launcher) getRunner(params launch.LauncherParams, runParams *runner.RunnerParams) (runner.Runner, error) {
+	return runner.GetRunner(runParams)
+}
@@ -0,0 +1,104 @@
+package launch
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// A launchLock is written to the install folder while a game is
+// running, so that other butler processes (like the ones started by
+// desktop shortcuts) don't launch it a second time.
+//
+// Telling stale locks apart requires finding the game's processes,
+// so other processes' locks are only honored on Linux and macOS. On
+// Windows, the game's processes belong to a job object we can't query,
+// see canFindProcesses, and any lock left by another process is
+// treated as stale.
+type launchLock struct {
+	LaunchID  string    `json:"launchId"`
+	StartedAt time.Time `json:"startedAt"`
+
+	// The butler process that launched the game, for troubleshooting
+	PID int `json:"pid"`
+}
+
+func launchLockPath(installFolder string) string {
+	return filepath.Join(installFolder, ".itch", "launch.lock")
+}
+
+// acquireLaunchLock records that launchID is running. If the lock is
+// held by a launch that's still running, it is returned instead.
+func acquireLaunchLock(installFolder string, launchID string) (*launchLock, error) {
+	lockPath := launchLockPath(installFolder)
+	err := os.MkdirAll(filepath.Dir(lockPath), 0755)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	payload, err := json.Marshal(&launchLock{
+		LaunchID:  launchID,
+		StartedAt: time.Now().UTC(),
+		PID:       os.Getpid(),
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = f.Write(payload)
+			f.Close()
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			return nil, nil
+		}
+		if !os.IsExist(err) {
+			return nil, errors.WithStack(err)
+		}
+
+		held := readLaunchLock(installFolder)
+		if held != nil && len(findProcesses(held.LaunchID)) > 0 {
+			return held, nil
+		}
+
+		// left over by a butler process that didn't exit cleanly
+		err = os.Remove(lockPath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	return nil, errors.Errorf("could not acquire launch lock (%s)", lockPath)
+}
+
+// readLaunchLock returns the current lock of an install folder,
+// or nil if there's none, or it can't be read.
+func readLaunchLock(installFolder string) *launchLock {
+	payload, err := ioutil.ReadFile(launchLockPath(installFolder))
+	if err != nil {
+		return nil
+	}
+
+	var lock launchLock
+	err = json.Unmarshal(payload, &lock)
+	if err != nil {
+		return nil
+	}
+	return &lock
+}
+
+// releaseLaunchLock removes the lock, if it's still held by launchID
+func releaseLaunchLock(installFolder string, launchID string) {
+	held := readLaunchLock(installFolder)
+	if held == nil || held.LaunchID != launchID {
+		return
+	}
+	os.Remove(launchLockPath(installFolder))
+}
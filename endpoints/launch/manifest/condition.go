@@ -0,0 +1,340 @@
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/itchio/ox"
+	"github.com/pkg/errors"
+)
+
+// Host describes the system action conditions are evaluated against
+type Host struct {
+	Runtime *ox.Runtime
+
+	// Version of the operating system, like `10.0.17134` or `4.15.0`.
+	// If empty, version comparisons are considered satisfied.
+	OSVersion string
+
+	// Looks up an environment variable, defaults to os.LookupEnv
+	LookupEnv func(key string) (string, bool)
+}
+
+// CurrentHost returns a Host for the system we're running on
+func CurrentHost(runtime *ox.Runtime) *Host {
+	return &Host{
+		Runtime:   runtime,
+		OSVersion: OSVersion(),
+		LookupEnv: os.LookupEnv,
+	}
+}
+
+func (h *Host) lookupEnv(key string) (string, bool) {
+	if h.LookupEnv == nil {
+		return os.LookupEnv(key)
+	}
+	return h.LookupEnv(key)
+}
+
+// EvalCondition returns true if condition holds on host. An empty
+// condition always holds.
+//
+// A condition is a small boolean expression, for example:
+//
+//	osVersion >= 10.0 && !env.ITCH_DISABLE_VULKAN
+//	(platform == linux || platform == osx) && arch == amd64
+//
+// Supported terms are `platform`, `arch` and `osVersion` compared
+// with `==`, `!=`, `<`, `<=`, `>`, `>=` (ordering only makes sense for
+// `osVersion`), and `env.NAME`, which is true if the environment variable
+// is set, or can be compared to a value. Terms can be combined with `&&`,
+// `||`, `!` and parentheses.
+func EvalCondition(condition string, host *Host) (bool, error) {
+	if strings.TrimSpace(condition) == "" {
+		return true, nil
+	}
+
+	tokens, err := tokenizeCondition(condition)
+	if err != nil {
+		return false, errors.WithMessage(err, fmt.Sprintf("in condition (%s)", condition))
+	}
+
+	p := &conditionParser{tokens: tokens, host: host}
+	res, err := p.parseOr()
+	if err != nil {
+		return false, errors.WithMessage(err, fmt.Sprintf("in condition (%s)", condition))
+	}
+
+	if p.pos < len(p.tokens) {
+		return false, errors.Errorf("in condition (%s): unexpected (%s)", condition, p.tokens[p.pos])
+	}
+	return res, nil
+}
+
+// CheckCondition returns an error if condition can't be parsed
+func CheckCondition(condition string) error {
+	_, err := EvalCondition(condition, &Host{
+		Runtime:   ox.CurrentRuntime(),
+		LookupEnv: func(key string) (string, bool) { return "", false },
+	})
+	return err
+}
+
+var conditionOperators = []string{"&&", "||", "==", "!=", ">=", "<=", "!", "(", ")", ">", "<"}
+
+func tokenizeCondition(s string) ([]string, error) {
+	var tokens []string
+
+	for len(s) > 0 {
+		r := rune(s[0])
+		if unicode.IsSpace(r) {
+			s = s[1:]
+			continue
+		}
+
+		matched := false
+		for _, op := range conditionOperators {
+			if strings.HasPrefix(s, op) {
+				tokens = append(tokens, op)
+				s = s[len(op):]
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+
+		if r == '"' {
+			end := strings.IndexRune(s[1:], '"')
+			if end < 0 {
+				return nil, errors.New("unterminated string")
+			}
+			tokens = append(tokens, s[:end+2])
+			s = s[end+2:]
+			continue
+		}
+
+		end := strings.IndexFunc(s, func(r rune) bool {
+			return !(unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("._-", r))
+		})
+		if end == 0 {
+			return nil, errors.Errorf("unexpected character (%c)", r)
+		}
+		if end < 0 {
+			end = len(s)
+		}
+		tokens = append(tokens, s[:end])
+		s = s[end:]
+	}
+
+	return tokens, nil
+}
+
+type conditionParser struct {
+	tokens []string
+	pos    int
+	host   *Host
+}
+
+func (p *conditionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *conditionParser) next() (string, error) {
+	if p.pos >= len(p.tokens) {
+		return "", errors.New("unexpected end of condition")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+	return tok, nil
+}
+
+func (p *conditionParser) parseOr() (bool, error) {
+	res, err := p.parseAnd()
+	if err != nil {
+		return false, err
+	}
+
+	for p.peek() == "||" {
+		p.pos++
+		rhs, err := p.parseAnd()
+		if err != nil {
+			return false, err
+		}
+		res = res || rhs
+	}
+	return res, nil
+}
+
+func (p *conditionParser) parseAnd() (bool, error) {
+	res, err := p.parseUnary()
+	if err != nil {
+		return false, err
+	}
+
+	for p.peek() == "&&" {
+		p.pos++
+		rhs, err := p.parseUnary()
+		if err != nil {
+			return false, err
+		}
+		res = res && rhs
+	}
+	return res, nil
+}
+
+func (p *conditionParser) parseUnary() (bool, error) {
+	switch p.peek() {
+	case "!":
+		p.pos++
+		res, err := p.parseUnary()
+		return !res, err
+	case "(":
+		p.pos++
+		res, err := p.parseOr()
+		if err != nil {
+			return false, err
+		}
+		tok, err := p.next()
+		if err != nil {
+			return false, err
+		}
+		if tok != ")" {
+			return false, errors.Errorf("expected ), got (%s)", tok)
+		}
+		return res, nil
+	}
+	return p.parseTerm()
+}
+
+func (p *conditionParser) parseTerm() (bool, error) {
+	name, err := p.next()
+	if err != nil {
+		return false, err
+	}
+
+	var op, value string
+	switch p.peek() {
+	case "==", "!=", ">=", "<=", ">", "<":
+		op, _ = p.next()
+		value, err = p.next()
+		if err != nil {
+			return false, err
+		}
+		if isConditionOperator(value) {
+			return false, errors.Errorf("expected value after (%s), got (%s)", op, value)
+		}
+		value = strings.Trim(value, `"`)
+	}
+
+	switch {
+	case strings.HasPrefix(name, "env."):
+		key := strings.TrimPrefix(name, "env.")
+		actual, ok := p.host.lookupEnv(key)
+		if op == "" {
+			return ok, nil
+		}
+		return compareStrings(op, actual, value)
+	case name == "platform":
+		if op == "" {
+			return false, errors.New("platform must be compared to a value")
+		}
+		return compareStrings(op, string(p.host.Runtime.Platform), value)
+	case name == "arch":
+		if op == "" {
+			return false, errors.New("arch must be compared to a value")
+		}
+		return compareStrings(op, p.host.Runtime.Arch(), value)
+	case name == "osVersion":
+		if op == "" {
+			return false, errors.New("osVersion must be compared to a value")
+		}
+		if p.host.OSVersion == "" {
+			return true, nil
+		}
+		return compareInts(op, CompareVersions(p.host.OSVersion, value)), nil
+	}
+
+	return false, errors.Errorf("unknown term (%s)", name)
+}
+
+func isConditionOperator(tok string) bool {
+	for _, op := range conditionOperators {
+		if tok == op {
+			return true
+		}
+	}
+	return false
+}
+
+func compareStrings(op string, a string, b string) (bool, error) {
+	switch op {
+	case "==":
+		return a == b, nil
+	case "!=":
+		return a != b, nil
+	}
+	return false, errors.Errorf("operator (%s) can only be used with osVersion", op)
+}
+
+func compareInts(op string, cmp int) bool {
+	switch op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	}
+	return false
+}
+
+// CompareVersions compares dotted versions like `10.0.17134` numerically.
+// Returns -1 if a < b, 0 if they're equal, 1 if a > b. Missing components
+// count as zero, non-numeric suffixes (like `-generic`) are ignored.
+func CompareVersions(a string, b string) int {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var an, bn int64
+		if i < len(as) {
+			an = leadingInt(as[i])
+		}
+		if i < len(bs) {
+			bn = leadingInt(bs[i])
+		}
+
+		if an < bn {
+			return -1
+		}
+		if an > bn {
+			return 1
+		}
+	}
+	return 0
+}
+
+func leadingInt(s string) int64 {
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsDigit(r)
+	})
+	if end >= 0 {
+		s = s[:end]
+	}
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
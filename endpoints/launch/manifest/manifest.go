@@ -3,9 +3,10 @@ package manifest
 import (
 	"os"
 	"path/filepath"
-	"strings"
+	"regexp"
 
 	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/dash"
 	"github.com/itchio/ox"
 	"github.com/mitchellh/mapstructure"
 
@@ -16,23 +17,72 @@ import (
 
 // TODO: linter
 
-func ListActions(m *butlerd.Manifest, runtime *ox.Runtime) []*butlerd.Action {
+// ListActions returns the actions that apply to host: those that match its
+// platform and architecture, and whose condition holds. On 64-bit hosts,
+// 32-bit actions are only kept if there are no 64-bit ones.
+func ListActions(m *butlerd.Manifest, host *Host) []*butlerd.Action {
 	var result []*butlerd.Action
 
 	for _, a := range m.Actions {
-		if a.Platform == "" {
-			// universal
-			result = append(result, a)
-		} else if a.Platform == runtime.Platform {
-			// just the right platform for us!
-			result = append(result, a)
+		if !ActionApplies(a, host) {
+			continue
+		}
+		result = append(result, a)
+	}
+
+	if host.Runtime.Is64 {
+		hasNativeArch := false
+		for _, a := range result {
+			if a.Arch == string(dash.ArchAmd64) {
+				hasNativeArch = true
+				break
+			}
+		}
+
+		if hasNativeArch {
+			var nativeResult []*butlerd.Action
+			for _, a := range result {
+				if a.Arch != string(dash.Arch386) {
+					nativeResult = append(nativeResult, a)
+				}
+			}
+			result = nativeResult
 		}
-		// otherwise, skip it
 	}
 
 	return result
 }
 
+// ActionApplies returns true if an action can be launched on host.
+// Actions with invalid conditions never apply.
+func ActionApplies(a *butlerd.Action, host *Host) bool {
+	runtime := host.Runtime
+
+	if a.Platform != "" && a.Platform != runtime.Platform {
+		// not for this platform
+		return false
+	}
+
+	switch a.Arch {
+	case "":
+		// universal
+	case string(dash.ArchAmd64):
+		if !runtime.Is64 {
+			return false
+		}
+	case string(dash.Arch386):
+		// 32-bit actions can run on 64-bit hosts too
+	default:
+		return false
+	}
+
+	ok, err := EvalCondition(a.Condition, host)
+	if err != nil {
+		return false
+	}
+	return ok
+}
+
 func Path(folder string) string {
 	return filepath.Join(folder, ".itch.toml")
 }
@@ -80,22 +130,161 @@ func Read(folder string) (*butlerd.Manifest, error) {
 	return manifest, nil
 }
 
-func ExpandPath(a *butlerd.Action, runtime *ox.Runtime, baseFolder string) string {
-	if filepath.IsAbs(a.Path) {
-		return a.Path
-	}
+// Vars holds the values substituted for template variables
+// such as `{{installFolder}}` in manifest actions.
+type Vars struct {
+	Runtime       *ox.Runtime
+	InstallFolder string
+	Locale        string
 
-	path := a.Path
-	if strings.Contains(path, "{{EXT}}") {
-		var ext = ""
-		switch runtime.Platform {
+	// Only needed to expand save paths
+	UserDirs *UserDirs
+}
+
+var varPattern = regexp.MustCompile(`{{([A-Za-z]+)}}`)
+
+func (v *Vars) lookup(name string) (string, bool) {
+	switch name {
+	case "EXT":
+		switch v.Runtime.Platform {
 		case ox.PlatformWindows:
-			ext = ".exe"
+			return ".exe", true
 		case ox.PlatformOSX:
-			ext = ".app"
+			return ".app", true
+		}
+		return "", true
+	case "installFolder":
+		return v.InstallFolder, true
+	case "platform":
+		return string(v.Runtime.Platform), true
+	case "arch":
+		return v.Runtime.Arch(), true
+	case "locale":
+		return v.Locale, true
+	}
+
+	if v.UserDirs != nil {
+		switch name {
+		case "home":
+			return v.UserDirs.Home, true
+		case "userData":
+			return v.UserDirs.UserData, true
+		case "userConfig":
+			return v.UserDirs.UserConfig, true
+		case "documents":
+			return v.UserDirs.Documents, true
+		}
+	}
+	return "", false
+}
+
+// Expand substitutes all known template variables in s.
+// Unknown variables are left as-is.
+func Expand(s string, vars *Vars) string {
+	return varPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if value, ok := vars.lookup(varPattern.FindStringSubmatch(match)[1]); ok {
+			return value
+		}
+		return match
+	})
+}
+
+// UnknownVars returns the names of template variables used by
+// an action that we don't know how to expand.
+func UnknownVars(a *butlerd.Action) []string {
+	var inputs []string
+	inputs = append(inputs, a.Path, a.WorkingDir)
+	inputs = append(inputs, a.Args...)
+	for _, v := range a.Env {
+		inputs = append(inputs, v)
+	}
+
+	vars := &Vars{Runtime: ox.CurrentRuntime()}
+	var result []string
+	for _, input := range inputs {
+		for _, m := range varPattern.FindAllStringSubmatch(input, -1) {
+			if _, ok := vars.lookup(m[1]); !ok {
+				result = append(result, m[1])
+			}
 		}
-		path = strings.Replace(path, "{{EXT}}", ext, 1)
+	}
+	return result
+}
+
+func ExpandPath(a *butlerd.Action, vars *Vars) string {
+	return expandPath(a.Path, vars)
+}
+
+func expandPath(path string, vars *Vars) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	path = Expand(path, vars)
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(vars.InstallFolder, path)
+}
+
+func ExpandArgs(a *butlerd.Action, vars *Vars) []string {
+	var args []string
+	for _, arg := range a.Args {
+		args = append(args, Expand(arg, vars))
+	}
+	return args
+}
+
+func ExpandEnv(a *butlerd.Action, vars *Vars) map[string]string {
+	env := make(map[string]string)
+	for k, v := range a.Env {
+		env[k] = Expand(v, vars)
+	}
+	return env
+}
+
+// ListHooks returns the hooks for a given event that apply to runtime,
+// in manifest order.
+func ListHooks(m *butlerd.Manifest, runtime *ox.Runtime, event butlerd.HookEvent) []*butlerd.Hook {
+	var result []*butlerd.Hook
+
+	for _, h := range m.Hooks {
+		if h.Event != event {
+			continue
+		}
+		if h.Platform != "" && h.Platform != runtime.Platform {
+			continue
+		}
+		result = append(result, h)
+	}
+
+	return result
+}
+
+func ExpandHookPath(h *butlerd.Hook, vars *Vars) string {
+	return expandPath(h.Path, vars)
+}
+
+func ExpandHookArgs(h *butlerd.Hook, vars *Vars) []string {
+	var args []string
+	for _, arg := range h.Args {
+		args = append(args, Expand(arg, vars))
+	}
+	return args
+}
+
+// ExpandWorkingDir returns the absolute working directory requested
+// by an action, or an empty string if it didn't request one.
+func ExpandWorkingDir(a *butlerd.Action, vars *Vars) string {
+	if a.WorkingDir == "" {
+		return ""
+	}
+
+	dir := Expand(a.WorkingDir, vars)
+	if filepath.IsAbs(dir) {
+		return dir
 	}
 
-	return filepath.Join(baseFolder, path)
+	return filepath.Join(vars.InstallFolder, dir)
 }
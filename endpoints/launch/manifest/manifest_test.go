@@ -0,0 +1,188 @@
+package manifest_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/endpoints/launch/manifest"
+	"github.com/itchio/ox"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Expand(t *testing.T) {
+	installFolder := filepath.Join("games", "garden")
+
+	vars := &manifest.Vars{
+		Runtime: &ox.Runtime{
+			Platform: ox.PlatformWindows,
+			Is64:     true,
+		},
+		InstallFolder: installFolder,
+		Locale:        "fr-FR",
+	}
+
+	a := &butlerd.Action{
+		Name:       "play",
+		Path:       "bin-{{arch}}/garden{{EXT}}",
+		Args:       []string{"--lang={{locale}}", "--data={{installFolder}}/data"},
+		WorkingDir: "bin-{{arch}}",
+		Env: map[string]string{
+			"GARDEN_PLATFORM": "{{platform}}",
+			"GARDEN_UNKNOWN":  "{{unknown}}",
+		},
+	}
+
+	assert.EqualValues(t, filepath.Join(installFolder, "bin-amd64", "garden.exe"), manifest.ExpandPath(a, vars))
+	assert.EqualValues(t, []string{"--lang=fr-FR", "--data=" + installFolder + "/data"}, manifest.ExpandArgs(a, vars))
+	assert.EqualValues(t, filepath.Join(installFolder, "bin-amd64"), manifest.ExpandWorkingDir(a, vars))
+	assert.EqualValues(t, map[string]string{
+		"GARDEN_PLATFORM": "windows",
+		"GARDEN_UNKNOWN":  "{{unknown}}",
+	}, manifest.ExpandEnv(a, vars))
+	assert.EqualValues(t, []string{"unknown"}, manifest.UnknownVars(a))
+
+	vars.Runtime = &ox.Runtime{
+		Platform: ox.PlatformLinux,
+		Is64:     false,
+	}
+	assert.EqualValues(t, filepath.Join(installFolder, "bin-386", "garden"), manifest.ExpandPath(a, vars))
+	assert.EqualValues(t, "", manifest.ExpandWorkingDir(&butlerd.Action{}, vars))
+}
+
+func Test_ListActions(t *testing.T) {
+	m := &butlerd.Manifest{
+		Actions: []*butlerd.Action{
+			{Name: "play-32", Path: "game32.exe", Platform: ox.PlatformWindows, Arch: "386"},
+			{Name: "play-64", Path: "game64.exe", Platform: ox.PlatformWindows, Arch: "amd64"},
+			{Name: "play-linux", Path: "game.x86_64", Platform: ox.PlatformLinux},
+			{Name: "play-vulkan", Path: "game-vk.exe", Condition: "osVersion >= 10.0 && env.VULKAN_SDK"},
+			{Name: "broken", Path: "broken.exe", Condition: "osVersion >="},
+		},
+	}
+
+	names := func(actions []*butlerd.Action) []string {
+		var res []string
+		for _, a := range actions {
+			res = append(res, a.Name)
+		}
+		return res
+	}
+
+	env := map[string]string{}
+	host := &manifest.Host{
+		Runtime:   &ox.Runtime{Platform: ox.PlatformWindows, Is64: true},
+		OSVersion: "10.0.17134",
+		LookupEnv: func(key string) (string, bool) {
+			v, ok := env[key]
+			return v, ok
+		},
+	}
+
+	assert.EqualValues(t, []string{"play-64"}, names(manifest.ListActions(m, host)))
+
+	env["VULKAN_SDK"] = "C:\\VulkanSDK"
+	assert.EqualValues(t, []string{"play-64", "play-vulkan"}, names(manifest.ListActions(m, host)))
+
+	host.OSVersion = "6.1.7601"
+	assert.EqualValues(t, []string{"play-64"}, names(manifest.ListActions(m, host)))
+
+	host.Runtime = &ox.Runtime{Platform: ox.PlatformWindows, Is64: false}
+	assert.EqualValues(t, []string{"play-32"}, names(manifest.ListActions(m, host)))
+
+	host.Runtime = &ox.Runtime{Platform: ox.PlatformLinux, Is64: true}
+	assert.EqualValues(t, []string{"play-linux"}, names(manifest.ListActions(m, host)))
+}
+
+func Test_EvalCondition(t *testing.T) {
+	host := &manifest.Host{
+		Runtime:   &ox.Runtime{Platform: ox.PlatformLinux, Is64: true},
+		OSVersion: "4.15.0-29-generic",
+		LookupEnv: func(key string) (string, bool) {
+			if key == "DISPLAY" {
+				return ":0", true
+			}
+			return "", false
+		},
+	}
+
+	eval := func(condition string) bool {
+		res, err := manifest.EvalCondition(condition, host)
+		assert.NoError(t, err, condition)
+		return res
+	}
+
+	assert.True(t, eval(""))
+	assert.True(t, eval("platform == linux && arch == amd64"))
+	assert.False(t, eval("platform != linux"))
+	assert.True(t, eval("osVersion >= 4.4 && osVersion < 5"))
+	assert.True(t, eval(`env.DISPLAY == ":0"`))
+	assert.False(t, eval("env.WAYLAND_DISPLAY"))
+	assert.True(t, eval("!env.WAYLAND_DISPLAY && (platform == windows || env.DISPLAY)"))
+
+	for _, bad := range []string{"platform", "osVersion >=", "(arch == amd64", "arch > 386", "foo == bar", "env.A ~ b"} {
+		_, err := manifest.EvalCondition(bad, host)
+		assert.Error(t, err, bad)
+	}
+
+	assert.EqualValues(t, -1, manifest.CompareVersions("6.1.7601", "10.0"))
+	assert.EqualValues(t, 0, manifest.CompareVersions("10", "10.0.0"))
+	assert.EqualValues(t, 1, manifest.CompareVersions("10.13.6", "10.9"))
+}
+
+func Test_ListHooks(t *testing.T) {
+	m := &butlerd.Manifest{
+		Hooks: []*butlerd.Hook{
+			{Event: butlerd.HookEventPreLaunch, Path: "tools/migrate.exe", Platform: ox.PlatformWindows},
+			{Event: butlerd.HookEventPreLaunch, Path: "tools/migrate.sh", Platform: ox.PlatformLinux},
+			{Event: butlerd.HookEventPostExit, Path: "tools/upload-logs{{EXT}}", Args: []string{"{{installFolder}}/logs"}},
+		},
+	}
+
+	linux := &ox.Runtime{Platform: ox.PlatformLinux, Is64: true}
+	hooks := manifest.ListHooks(m, linux, butlerd.HookEventPreLaunch)
+	assert.Len(t, hooks, 1)
+	assert.EqualValues(t, "tools/migrate.sh", hooks[0].Path)
+
+	windows := &ox.Runtime{Platform: ox.PlatformWindows, Is64: true}
+	hooks = manifest.ListHooks(m, windows, butlerd.HookEventPostExit)
+	assert.Len(t, hooks, 1)
+
+	vars := &manifest.Vars{Runtime: windows, InstallFolder: "garden"}
+	assert.EqualValues(t, filepath.Join("garden", "tools", "upload-logs.exe"), manifest.ExpandHookPath(hooks[0], vars))
+	assert.EqualValues(t, []string{"garden/logs"}, manifest.ExpandHookArgs(hooks[0], vars))
+}
+
+func Test_ListSaves(t *testing.T) {
+	m := &butlerd.Manifest{
+		Saves: []*butlerd.SavePath{
+			{Path: "saves/**/*.sav"},
+			{Path: "{{userData}}/Garden/settings.ini", Platform: ox.PlatformLinux},
+			{Path: "{{documents}}/My Games/Garden", Platform: ox.PlatformWindows},
+		},
+	}
+
+	linux := &ox.Runtime{Platform: ox.PlatformLinux, Is64: true}
+	vars := &manifest.Vars{
+		Runtime:       linux,
+		InstallFolder: "/games/garden",
+		UserDirs:      &manifest.UserDirs{UserData: "/home/player/.local/share"},
+	}
+
+	roots := manifest.ListSaves(m, vars)
+	assert.Len(t, roots, 2)
+	assert.EqualValues(t, filepath.FromSlash("/games/garden/saves"), roots[0].Base)
+	assert.EqualValues(t, "**/*.sav", roots[0].Glob)
+	assert.EqualValues(t, filepath.FromSlash("/home/player/.local/share/Garden"), roots[1].Base)
+	assert.EqualValues(t, "settings.ini", roots[1].Glob)
+
+	assert.True(t, roots[0].Match("slot1.sav"))
+	assert.True(t, roots[0].Match("profiles/amos/slot1.sav"))
+	assert.False(t, roots[0].Match("profiles/amos/slot1.bak"))
+	assert.True(t, roots[1].Match("settings.ini"))
+	assert.False(t, roots[1].Match("cache/settings.ini"))
+
+	// everything inside a matched folder is a save
+	dirRoot := manifest.ExpandSavePath(&butlerd.SavePath{Path: "profiles/*"}, vars)
+	assert.True(t, dirRoot.Match("amos/slot1.sav"))
+}
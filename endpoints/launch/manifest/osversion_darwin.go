@@ -0,0 +1,17 @@
+// +build darwin
+
+package manifest
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// OSVersion returns the macOS product version, like `10.13.6`
+func OSVersion() string {
+	out, err := exec.Command("sw_vers", "-productVersion").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
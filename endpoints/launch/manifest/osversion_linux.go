@@ -0,0 +1,25 @@
+// +build linux
+
+package manifest
+
+import (
+	"syscall"
+)
+
+// OSVersion returns the kernel release, like `4.15.0-29-generic`
+func OSVersion() string {
+	var uts syscall.Utsname
+	err := syscall.Uname(&uts)
+	if err != nil {
+		return ""
+	}
+
+	var release []byte
+	for _, c := range uts.Release {
+		if c == 0 {
+			break
+		}
+		release = append(release, byte(c))
+	}
+	return string(release)
+}
@@ -0,0 +1,8 @@
+// +build !linux,!darwin,!windows
+
+package manifest
+
+// OSVersion is unknown on this platform
+func OSVersion() string {
+	return ""
+}
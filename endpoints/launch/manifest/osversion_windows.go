@@ -0,0 +1,43 @@
+// +build windows
+
+package manifest
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// GetVersion lies about versions after Windows 8 unless the executable
+// has a compatibility manifest, RtlGetVersion doesn't.
+var (
+	modntdll          = syscall.NewLazyDLL("ntdll.dll")
+	procRtlGetVersion = modntdll.NewProc("RtlGetVersion")
+)
+
+type osVersionInfo struct {
+	size         uint32
+	majorVersion uint32
+	minorVersion uint32
+	buildNumber  uint32
+	platformID   uint32
+	csdVersion   [128]uint16
+}
+
+// OSVersion returns the Windows version, like `10.0.17134`
+func OSVersion() string {
+	info := &osVersionInfo{}
+	info.size = uint32(unsafe.Sizeof(*info))
+
+	err := procRtlGetVersion.Find()
+	if err != nil {
+		return ""
+	}
+
+	status, _, _ := procRtlGetVersion.Call(uintptr(unsafe.Pointer(info)))
+	if status != 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%d.%d.%d", info.majorVersion, info.minorVersion, info.buildNumber)
+}
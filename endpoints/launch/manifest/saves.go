@@ -0,0 +1,169 @@
+package manifest
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/itchio/butler/butlerd"
+	"github.com/pkg/errors"
+)
+
+// A SaveRoot is a save path from the manifest, expanded for a host.
+type SaveRoot struct {
+	// The path as written in the manifest
+	Pattern string
+
+	// Absolute folder the glob is relative to
+	Base string
+
+	// Slash-separated glob, relative to Base. Supports `*`, `?`,
+	// character classes and `**` for any number of folders.
+	Glob string
+}
+
+// ListSaves returns the save paths of a manifest that apply to the
+// runtime of vars, in manifest order. vars should have UserDirs set.
+func ListSaves(m *butlerd.Manifest, vars *Vars) []*SaveRoot {
+	var result []*SaveRoot
+
+	for _, s := range m.Saves {
+		if s.Platform != "" && s.Platform != vars.Runtime.Platform {
+			continue
+		}
+		result = append(result, ExpandSavePath(s, vars))
+	}
+
+	return result
+}
+
+// ExpandSavePath substitutes template variables in a save path and
+// splits it into a folder and a glob pattern relative to that folder.
+func ExpandSavePath(s *butlerd.SavePath, vars *Vars) *SaveRoot {
+	expanded := strings.TrimSuffix(filepath.ToSlash(expandPath(s.Path, vars)), "/")
+
+	segments := strings.Split(expanded, "/")
+	numBase := len(segments) - 1
+	for i, segment := range segments {
+		if strings.ContainsAny(segment, "*?[") {
+			numBase = i
+			break
+		}
+	}
+
+	base := strings.Join(segments[:numBase], "/")
+	if base == "" {
+		base = "/"
+	}
+
+	return &SaveRoot{
+		Pattern: s.Path,
+		Base:    filepath.FromSlash(base),
+		Glob:    strings.Join(segments[numBase:], "/"),
+	}
+}
+
+// Match returns true if relPath (slash-separated, relative to Base)
+// is part of this save path. Everything inside a matched folder is
+// part of it too.
+func (sr *SaveRoot) Match(relPath string) bool {
+	pattern := strings.Split(sr.Glob, "/")
+	name := strings.Split(relPath, "/")
+
+	for i := 1; i <= len(name); i++ {
+		if matchSegments(pattern, name[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern []string, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(name) == 0 {
+		return false
+	}
+
+	ok, err := path.Match(pattern[0], name[0])
+	if err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], name[1:])
+}
+
+// canDescend returns true if files inside the folder name could
+// match pattern.
+func canDescend(pattern []string, name []string) bool {
+	if len(name) == 0 {
+		return true
+	}
+	if len(pattern) == 0 {
+		return false
+	}
+	if pattern[0] == "**" {
+		return true
+	}
+
+	ok, err := path.Match(pattern[0], name[0])
+	if err != nil || !ok {
+		return false
+	}
+	return canDescend(pattern[1:], name[1:])
+}
+
+// Files returns the slash-separated paths, relative to Base, of all
+// regular files that are part of this save path. A missing Base
+// simply means there are no saves yet.
+func (sr *SaveRoot) Files() ([]string, error) {
+	var result []string
+
+	err := filepath.Walk(sr.Base, func(fullPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		rel, err := filepath.Rel(sr.Base, fullPath)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if info.IsDir() {
+			// don't walk all of `{{home}}` for `{{home}}/*.sav`
+			if rel != "." && !sr.Match(rel) && !canDescend(strings.Split(sr.Glob, "/"), strings.Split(rel, "/")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		if sr.Match(rel) {
+			result = append(result, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return result, nil
+}
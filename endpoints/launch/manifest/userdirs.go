@@ -0,0 +1,56 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/itchio/ox"
+)
+
+// UserDirs are the per-user folders games commonly store their
+// saves and settings in. They're available to manifests as
+// `{{home}}`, `{{userData}}`, `{{userConfig}}` and `{{documents}}`.
+type UserDirs struct {
+	Home       string
+	UserData   string
+	UserConfig string
+	Documents  string
+}
+
+// CurrentUserDirs returns the user folders of the current user,
+// following the conventions of the given platform.
+func CurrentUserDirs(runtime *ox.Runtime) *UserDirs {
+	switch runtime.Platform {
+	case ox.PlatformWindows:
+		home := os.Getenv("USERPROFILE")
+		return &UserDirs{
+			Home:       home,
+			UserData:   envOr("APPDATA", filepath.Join(home, "AppData", "Roaming")),
+			UserConfig: envOr("LOCALAPPDATA", filepath.Join(home, "AppData", "Local")),
+			Documents:  filepath.Join(home, "Documents"),
+		}
+	case ox.PlatformOSX:
+		home := os.Getenv("HOME")
+		return &UserDirs{
+			Home:       home,
+			UserData:   filepath.Join(home, "Library", "Application Support"),
+			UserConfig: filepath.Join(home, "Library", "Preferences"),
+			Documents:  filepath.Join(home, "Documents"),
+		}
+	default:
+		home := os.Getenv("HOME")
+		return &UserDirs{
+			Home:       home,
+			UserData:   envOr("XDG_DATA_HOME", filepath.Join(home, ".local", "share")),
+			UserConfig: envOr("XDG_CONFIG_HOME", filepath.Join(home, ".config")),
+			Documents:  envOr("XDG_DOCUMENTS_DIR", filepath.Join(home, "Documents")),
+		}
+	}
+}
+
+func envOr(key string, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,60 @@
+// +build darwin
+
+package launch
+
+import (
+	"bufio"
+	"bytes"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const canFindProcesses = true
+
+// findProcesses returns the PIDs of all processes that have our
+// launch marker in their environment. ps only shows the environment
+// of processes that belong to the same user, which games do.
+func findProcesses(launchID string) []int64 {
+	marker := launchIDEnvVar + "=" + launchID
+
+	out, err := exec.Command("ps", "-E", "-ww", "-ax", "-o", "pid=,command=").Output()
+	if err != nil {
+		return nil
+	}
+
+	var pids []int64
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.Contains(line, marker) {
+			continue
+		}
+
+		fields := strings.SplitN(line, " ", 2)
+		pid, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		pids = append(pids, pid)
+	}
+	return pids
+}
+
+func terminateProcesses(pids []int64) {
+	for _, pid := range pids {
+		syscall.Kill(int(pid), syscall.SIGTERM)
+	}
+}
+
+func killProcesses(pids []int64) {
+	for _, pid := range pids {
+		syscall.Kill(int(pid), syscall.SIGKILL)
+	}
+}
+
+func focusWindows(pids []int64) bool {
+	return false
+}
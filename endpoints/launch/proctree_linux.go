@@ -0,0 +1,77 @@
+// +build linux
+
+package launch
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+const canFindProcesses = true
+
+// findProcesses returns the PIDs of all processes that have our
+// launch marker in their environment. Unlike process groups, this
+// also finds children that detached themselves.
+func findProcesses(launchID string) []int64 {
+	marker := []byte(launchIDEnvVar + "=" + launchID)
+
+	entries, err := ioutil.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+
+	var pids []int64
+	for _, e := range entries {
+		pid, err := strconv.ParseInt(e.Name(), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		environ, err := ioutil.ReadFile("/proc/" + e.Name() + "/environ")
+		if err != nil {
+			// gone, or not ours
+			continue
+		}
+
+		for _, entry := range bytes.Split(environ, []byte{0}) {
+			if bytes.Equal(entry, marker) {
+				pids = append(pids, pid)
+				break
+			}
+		}
+	}
+	return pids
+}
+
+func terminateProcesses(pids []int64) {
+	for _, pid := range pids {
+		syscall.Kill(int(pid), syscall.SIGTERM)
+	}
+}
+
+func killProcesses(pids []int64) {
+	for _, pid := range pids {
+		syscall.Kill(int(pid), syscall.SIGKILL)
+	}
+}
+
+// focusWindows brings the first visible window of any of pids to
+// the front. Returns false if xdotool isn't installed, or if none
+// of the processes have a window.
+func focusWindows(pids []int64) bool {
+	xdotool, err := exec.LookPath("xdotool")
+	if err != nil {
+		return false
+	}
+
+	for _, pid := range pids {
+		err := exec.Command(xdotool, "search", "--onlyvisible", "--pid", strconv.FormatInt(pid, 10), "windowactivate").Run()
+		if err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+// +build !linux,!darwin
+
+package launch
+
+// canFindProcesses is false here. On Windows, a game's processes are
+// only tracked by the runner's job object, which we can't query: games
+// are listed without PIDs, stopping them kills their job object right
+// away, and launch locks held by other butler processes can't be told
+// apart from stale ones.
+const canFindProcesses = false
+
+// findProcesses isn't implemented here, see canFindProcesses
+func findProcesses(launchID string) []int64 {
+	return nil
+}
+
+func terminateProcesses(pids []int64) {}
+
+func killProcesses(pids []int64) {}
+
+func focusWindows(pids []int64) bool {
+	return false
+}
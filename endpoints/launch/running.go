@@ -0,0 +1,182 @@
+package launch
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/itchio/butler/butlerd"
+	"github.com/pkg/errors"
+)
+
+// launchIDEnvVar is set for every launched game, so we can find all
+// of its processes later, even those that left its process group.
+const launchIDEnvVar = "ITCHIO_LAUNCH_ID"
+
+const defaultStopGracePeriod = 10 * time.Second
+
+// newRunningCave returns a cave that can be stopped, along with a func
+// to call once the runner has returned. That func waits for a Stop in
+// progress to be over, since runners return as soon as they've asked
+// the game to exit, not once it has.
+func newRunningCave(launchID string, caveID string, cancel context.CancelFunc) (*butlerd.RunningCave, func()) {
+	exited := make(chan struct{})
+
+	var lock sync.Mutex
+	var stops []chan struct{}
+
+	running := &butlerd.RunningCave{
+		LaunchID:  launchID,
+		CaveID:    caveID,
+		StartedAt: time.Now().UTC(),
+		Done:      make(chan struct{}),
+		Processes: func() []int64 {
+			return findProcesses(launchID)
+		},
+		Stop: func(gracePeriod time.Duration) (bool, error) {
+			stopped := make(chan struct{})
+			defer close(stopped)
+			lock.Lock()
+			stops = append(stops, stopped)
+			lock.Unlock()
+
+			// this makes the runner terminate the game's process group
+			// (or job object, on Windows)
+			cancel()
+			if !canFindProcesses {
+				// there's no asking the game to exit, the runner kills it
+				select {
+				case <-exited:
+					return true, nil
+				case <-time.After(gracePeriod):
+					return true, errors.Errorf("game did not exit after %s", gracePeriod)
+				}
+			}
+			terminateProcesses(findProcesses(launchID))
+
+			deadline := time.After(gracePeriod)
+			ticker := time.NewTicker(250 * time.Millisecond)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					select {
+					case <-exited:
+						if len(findProcesses(launchID)) == 0 {
+							return false, nil
+						}
+					default:
+					}
+				case <-deadline:
+					pids := findProcesses(launchID)
+					if len(pids) == 0 {
+						select {
+						case <-exited:
+							return false, nil
+						default:
+							return true, errors.Errorf("game did not exit after %s", gracePeriod)
+						}
+					}
+					killProcesses(pids)
+					return true, nil
+				}
+			}
+		},
+	}
+
+	finish := func() {
+		close(exited)
+
+		lock.Lock()
+		pending := stops
+		lock.Unlock()
+		for _, stopped := range pending {
+			<-stopped
+		}
+	}
+
+	return running, finish
+}
+
+func LaunchList(rc *butlerd.RequestContext, params butlerd.LaunchListParams) (*butlerd.LaunchListResult, error) {
+	res := &butlerd.LaunchListResult{
+		Games: []*butlerd.RunningGame{},
+	}
+
+	for _, running := range rc.RunningCaves.List() {
+		res.Games = append(res.Games, &butlerd.RunningGame{
+			CaveID:    running.CaveID,
+			LaunchID:  running.LaunchID,
+			StartedAt: running.StartedAt,
+			PIDs:      running.Processes(),
+		})
+	}
+	return res, nil
+}
+
+func LaunchStop(rc *butlerd.RequestContext, params butlerd.LaunchStopParams) (*butlerd.LaunchStopResult, error) {
+	consumer := rc.Consumer
+
+	gracePeriod := defaultStopGracePeriod
+	if params.GracePeriod > 0 {
+		gracePeriod = time.Duration(params.GracePeriod * float64(time.Second))
+	}
+
+	res := &butlerd.LaunchStopResult{}
+	for _, running := range rc.RunningCaves.ByCaveID(params.CaveID) {
+		consumer.Infof("Stopping cave (%s), launch (%s)...", running.CaveID, running.LaunchID)
+		res.DidStop = true
+
+		forced, err := running.Stop(gracePeriod)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if forced {
+			consumer.Warnf("Game did not exit within %s, had to kill it", gracePeriod)
+			res.Forced = true
+		}
+	}
+
+	return res, nil
+}
+
+// checkAlreadyRunning applies params.IfRunning if the cave is already
+// running, in this process or another. Returns a non-nil result if the
+// launch shouldn't go any further.
+func checkAlreadyRunning(rc *butlerd.RequestContext, params butlerd.LaunchParams, installFolder string) (*butlerd.LaunchResult, error) {
+	consumer := rc.Consumer
+
+	var launchIDs []string
+	for _, running := range rc.RunningCaves.ByCaveID(params.CaveID) {
+		launchIDs = append(launchIDs, running.LaunchID)
+	}
+	if held := readLaunchLock(installFolder); held != nil {
+		if !canFindProcesses {
+			consumer.Warnf("Found launch lock for (%s), but can't tell whether it's still running on this platform, ignoring it", held.LaunchID)
+		} else if len(findProcesses(held.LaunchID)) > 0 {
+			launchIDs = append(launchIDs, held.LaunchID)
+		}
+	}
+	if len(launchIDs) == 0 {
+		return nil, nil
+	}
+
+	switch params.IfRunning {
+	case butlerd.IfRunningPolicyLaunch:
+		consumer.Warnf("Cave is already running, launching another instance")
+		return nil, nil
+	case butlerd.IfRunningPolicyFocus:
+		var pids []int64
+		for _, launchID := range launchIDs {
+			pids = append(pids, findProcesses(launchID)...)
+		}
+		if focusWindows(pids) {
+			consumer.Infof("Cave is already running, brought it to the front")
+			return &butlerd.LaunchResult{Focused: true}, nil
+		}
+		consumer.Warnf("Cave is already running, and its window could not be focused")
+	}
+
+	return nil, errors.WithStack(butlerd.CodeAlreadyRunning)
+}
@@ -0,0 +1,34 @@
+package launch
+
+import (
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/database/models"
+)
+
+const sandboxBackendSetting = "sandbox_backend"
+
+func LaunchSetSandboxBackend(rc *butlerd.RequestContext, params butlerd.LaunchSetSandboxBackendParams) (*butlerd.LaunchSetSandboxBackendResult, error) {
+	rc.WithConn(func(conn *sqlite.Conn) {
+		models.SetSetting(conn, sandboxBackendSetting, params.Backend)
+	})
+
+	res := &butlerd.LaunchSetSandboxBackendResult{}
+	return res, nil
+}
+
+func LaunchGetSandboxBackend(rc *butlerd.RequestContext, params butlerd.LaunchGetSandboxBackendParams) (*butlerd.LaunchGetSandboxBackendResult, error) {
+	res := &butlerd.LaunchGetSandboxBackendResult{}
+	rc.WithConn(func(conn *sqlite.Conn) {
+		res.Backend = getSandboxBackend(conn)
+	})
+	return res, nil
+}
+
+// getSandboxBackend returns the backend set with Launch.SetSandboxBackend,
+// or an empty string to pick one at launch time.
+func getSandboxBackend(conn *sqlite.Conn) butlerd.SandboxBackend {
+	var backend butlerd.SandboxBackend
+	models.GetSetting(conn, sandboxBackendSetting, &backend)
+	return backend
+}
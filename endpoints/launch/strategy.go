@@ -45,9 +45,11 @@ func (sr *StrategyResult) String() string {
 	return strings.Join(lines, "\n")
 }
 
-func DetermineStrategy(consumer *state.Consumer, runtime *ox.Runtime, installFolder string, manifestAction *butlerd.Action) (*StrategyResult, error) {
+func DetermineStrategy(consumer *state.Consumer, vars *manifest.Vars, manifestAction *butlerd.Action) (*StrategyResult, error) {
+	runtime := vars.Runtime
+
 	// is it a path?
-	fullPath := manifest.ExpandPath(manifestAction, runtime, installFolder)
+	fullPath := manifest.ExpandPath(manifestAction, vars)
 	stats, err := os.Stat(fullPath)
 	if err != nil {
 		// is it an URL?
@@ -8,6 +8,7 @@ import (
 
 	"github.com/itchio/butler/butlerd"
 	"github.com/itchio/butler/cmd/operate"
+	"github.com/itchio/butler/endpoints/launch/manifest"
 	"github.com/itchio/butler/filtering"
 	"github.com/itchio/ox"
 	"github.com/itchio/pelican"
@@ -49,15 +50,34 @@ type LauncherParams struct {
 	// May be nil
 	Action *butlerd.Action
 
+	// Values for template variables in the manifest
+	Vars *manifest.Vars
+
 	// If true, enable sandbox
 	Sandbox bool
 
+	// Empty means auto-detect, only relevant on Linux
+	SandboxBackend butlerd.SandboxBackend
+
+	// If true, sandboxed games have no network access
+	SandboxNoNetwork bool
+
 	// Additional command-line arguments
 	Args []string
 
 	// Additional environment variables
 	Env map[string]string
 
+	// If non-empty, overrides the working directory
+	WorkingDir string
+
+	// If true, HTML5 games are served by butlerd
+	ServeHTML bool
+
+	// If non-nil, Windows executables are launched through
+	// a compatibility runner
+	Compat *CompatParams
+
 	PrereqsDir    string
 	ForcePrereqs  bool
 	Access        *operate.GameAccess
@@ -65,6 +85,12 @@ type LauncherParams struct {
 	Runtime       *ox.Runtime
 
 	RecordPlayTime RecordPlayTimeFunc
+
+	// May be nil. Called by launchers that wait for a process to exit
+	RecordExitCode RecordExitCodeFunc
+
+	// May be nil. Called when a process exits abnormally
+	RecordCrash RecordCrashFunc
 }
 
 // cf. https://github.com/itchio/itch/issues/1751
@@ -120,6 +146,23 @@ func (lp *LauncherParams) SniffFile(fileEntry *tlc.File) (*dash.Candidate, error
 
 type RecordPlayTimeFunc func(playTime time.Duration) error
 
+type RecordExitCodeFunc func(exitCode int64)
+
+// CrashInfo describes an abnormal exit, as observed by a launcher
+type CrashInfo struct {
+	ExitCode int64
+	// Name of the signal that killed the process, if any
+	Signal   string
+	Duration time.Duration
+	Target   string
+
+	// Last lines of output
+	Stdout []string
+	Stderr []string
+}
+
+type RecordCrashFunc func(crash *CrashInfo)
+
 type Launcher interface {
 	Do(params LauncherParams) error
 }
@@ -0,0 +1,60 @@
+package launch
+
+import (
+	"time"
+
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/butlerd/messages"
+)
+
+const resourceUsageInterval = 2 * time.Second
+
+type processUsage struct {
+	// User and system time, in seconds
+	CPUSeconds float64
+	// Resident set size, in bytes
+	RSS int64
+	// Number of processes that were sampled
+	Processes int64
+}
+
+// monitorResourceUsage sends LaunchResourceUsage notifications
+// for a launch until done is closed.
+func monitorResourceUsage(rc *butlerd.RequestContext, launchID string, done chan struct{}) {
+	ticker := time.NewTicker(resourceUsageInterval)
+	defer ticker.Stop()
+
+	var last *processUsage
+	var lastSampledAt time.Time
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		usage := sampleUsage(findProcesses(launchID))
+		if usage == nil {
+			continue
+		}
+		sampledAt := time.Now()
+
+		notif := butlerd.LaunchResourceUsageNotification{
+			LaunchID:   launchID,
+			CPUSeconds: usage.CPUSeconds,
+			RSS:        usage.RSS,
+			Children:   usage.Processes - 1,
+		}
+		if last != nil {
+			// CPU time goes down when child processes exit
+			if delta := usage.CPUSeconds - last.CPUSeconds; delta > 0 {
+				notif.CPUPercent = delta / sampledAt.Sub(lastSampledAt).Seconds() * 100
+			}
+		}
+		last = usage
+		lastSampledAt = sampledAt
+
+		messages.LaunchResourceUsage.Notify(rc, notif)
+	}
+}
@@ -0,0 +1,72 @@
+// +build linux
+
+package launch
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// clockTicks is USER_HZ, the unit of CPU times in /proc. It's 100 on
+// every architecture Linux supports, and we'd need cgo to ask.
+const clockTicks = 100
+
+// sampleUsage adds up the resource usage of pids. Returns nil if
+// none of them could be read, for example because they all exited.
+func sampleUsage(pids []int64) *processUsage {
+	var usage *processUsage
+	pageSize := int64(os.Getpagesize())
+
+	for _, pid := range pids {
+		stat, err := ioutil.ReadFile("/proc/" + strconv.FormatInt(pid, 10) + "/stat")
+		if err != nil {
+			continue
+		}
+
+		cpuTicks, rssPages, err := parseProcStat(stat)
+		if err != nil {
+			continue
+		}
+
+		if usage == nil {
+			usage = &processUsage{}
+		}
+		usage.CPUSeconds += float64(cpuTicks) / clockTicks
+		usage.RSS += rssPages * pageSize
+		usage.Processes++
+	}
+	return usage
+}
+
+// parseProcStat returns the user+system CPU time, in clock ticks,
+// and the resident set size, in pages, from `/proc/<pid>/stat`
+func parseProcStat(stat []byte) (int64, int64, error) {
+	// the command name is in parentheses and may contain spaces
+	// (or parentheses), so only look at what's after it
+	end := bytes.LastIndexByte(stat, ')')
+	if end < 0 {
+		return 0, 0, errors.New("invalid stat format")
+	}
+
+	// fields[0] is field 3 in proc(5), `state`
+	fields := bytes.Fields(stat[end+1:])
+	const utime, stime, rss = 14 - 3, 15 - 3, 24 - 3
+	if len(fields) <= rss {
+		return 0, 0, errors.New("invalid stat format")
+	}
+
+	var values [3]int64
+	for i, index := range []int{utime, stime, rss} {
+		v, err := strconv.ParseInt(string(fields[index]), 10, 64)
+		if err != nil {
+			return 0, 0, errors.WithStack(err)
+		}
+		values[i] = v
+	}
+
+	return values[0] + values[1], values[2], nil
+}
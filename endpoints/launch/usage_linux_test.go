@@ -0,0 +1,107 @@
+// +build linux
+
+package launch
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseProcStat(t *testing.T) {
+	stat := []byte("4242 (My Game (x64)) S 1 4242 4242 0 -1 4194560 1200 0 3 0 250 50 0 0 20 0 7 0 1234 987654321 2048 18446744073709551615 1 1 0 0 0 0 0 0 0 0 0 0 17 3 0 0 0 0 0\n")
+	cpuTicks, rssPages, err := parseProcStat(stat)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 300, cpuTicks)
+	assert.EqualValues(t, 2048, rssPages)
+
+	_, _, err = parseProcStat([]byte("4242 (truncated"))
+	assert.Error(t, err)
+
+	usage := sampleUsage([]int64{int64(os.Getpid())})
+	assert.NotNil(t, usage)
+	assert.EqualValues(t, 1, usage.Processes)
+	assert.True(t, usage.RSS > 0)
+
+	assert.Nil(t, sampleUsage(nil))
+}
+
+func Test_LaunchLock(t *testing.T) {
+	installFolder, err := ioutil.TempDir("", "launch-lock")
+	assert.NoError(t, err)
+	defer os.RemoveAll(installFolder)
+
+	held, err := acquireLaunchLock(installFolder, "first")
+	assert.NoError(t, err)
+	assert.Nil(t, held)
+
+	// nothing runs with the "first" marker, so the lock is stale
+	held, err = acquireLaunchLock(installFolder, "second")
+	assert.NoError(t, err)
+	assert.Nil(t, held)
+
+	game := exec.Command("sleep", "30")
+	game.Env = append(os.Environ(), launchIDEnvVar+"=second")
+	assert.NoError(t, game.Start())
+
+	held, err = acquireLaunchLock(installFolder, "third")
+	assert.NoError(t, err)
+	if assert.NotNil(t, held) {
+		assert.EqualValues(t, "second", held.LaunchID)
+		assert.EqualValues(t, os.Getpid(), held.PID)
+	}
+
+	// only the holder can release the lock
+	releaseLaunchLock(installFolder, "third")
+	assert.NotNil(t, readLaunchLock(installFolder))
+
+	game.Process.Kill()
+	game.Wait()
+
+	releaseLaunchLock(installFolder, "second")
+	assert.Nil(t, readLaunchLock(installFolder))
+}
+
+func Test_StopRunningCave(t *testing.T) {
+	// ignores SIGTERM, like a game that hangs while quitting
+	game := exec.Command("sh", "-c", `trap "" TERM; sleep 30`)
+	game.Env = append(os.Environ(), launchIDEnvVar+"=stubborn")
+	assert.NoError(t, game.Start())
+	go game.Wait()
+
+	// wait for the trap to be set up and sleep to start
+	for i := 0; i < 100 && len(findProcesses("stubborn")) < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	running, finish := newRunningCave("stubborn", "cave", cancel)
+
+	// runners return as soon as they've signalled the game
+	go func() {
+		<-ctx.Done()
+		finish()
+		close(running.Done)
+	}()
+
+	forced, err := running.Stop(500 * time.Millisecond)
+	assert.NoError(t, err)
+	assert.True(t, forced)
+
+	// SIGKILL isn't instant either
+	for i := 0; i < 100 && len(findProcesses("stubborn")) > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Empty(t, findProcesses("stubborn"))
+
+	select {
+	case <-running.Done:
+	case <-time.After(time.Second):
+		t.Error("Done wasn't closed after Stop returned")
+	}
+}
@@ -0,0 +1,8 @@
+// +build !linux
+
+package launch
+
+// sampleUsage is only implemented on Linux
+func sampleUsage(pids []int64) *processUsage {
+	return nil
+}
@@ -0,0 +1,108 @@
+package saves
+
+import (
+	"fmt"
+
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/butlerd/messages"
+	"github.com/itchio/butler/cmd/operate"
+	"github.com/itchio/butler/endpoints/saves/snapshots"
+	"github.com/pkg/errors"
+)
+
+func Register(router *butlerd.Router) {
+	messages.SavesSnapshot.Register(router, Snapshot)
+	messages.SavesList.Register(router, List)
+	messages.SavesRestore.Register(router, Restore)
+}
+
+type caveFolders struct {
+	installFolder string
+	backupFolder  string
+}
+
+func getCaveFolders(rc *butlerd.RequestContext, caveID string) *caveFolders {
+	cave := operate.ValidateCave(rc, caveID)
+
+	var cf caveFolders
+	rc.WithConn(func(conn *sqlite.Conn) {
+		cf.installFolder = cave.GetInstallFolder(conn)
+		cf.backupFolder = cave.GetSaveBackupFolder(conn)
+	})
+	return &cf
+}
+
+func Snapshot(rc *butlerd.RequestContext, params butlerd.SavesSnapshotParams) (*butlerd.SavesSnapshotResult, error) {
+	cf := getCaveFolders(rc, params.CaveID)
+
+	roots, err := snapshots.Roots(cf.installFolder)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("The manifest of cave (%s) lists no save paths", params.CaveID)
+	}
+
+	snapshot, err := snapshots.Take(cf.backupFolder, roots, butlerd.SaveSnapshotReasonManual)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if snapshot == nil {
+		return nil, fmt.Errorf("No saves found for cave (%s)", params.CaveID)
+	}
+
+	rc.Consumer.Statf("Backed up %d save files to (%s)", snapshot.Files, snapshot.Path)
+	res := &butlerd.SavesSnapshotResult{
+		Snapshot: snapshot,
+	}
+	return res, nil
+}
+
+func List(rc *butlerd.RequestContext, params butlerd.SavesListParams) (*butlerd.SavesListResult, error) {
+	cf := getCaveFolders(rc, params.CaveID)
+
+	list, err := snapshots.List(cf.backupFolder)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	res := &butlerd.SavesListResult{
+		Snapshots: list,
+	}
+	return res, nil
+}
+
+func Restore(rc *butlerd.RequestContext, params butlerd.SavesRestoreParams) (*butlerd.SavesRestoreResult, error) {
+	consumer := rc.Consumer
+	cf := getCaveFolders(rc, params.CaveID)
+
+	if len(rc.RunningCaves.ByCaveID(params.CaveID)) > 0 {
+		return nil, fmt.Errorf("Can't restore saves of cave (%s) while it's running", params.CaveID)
+	}
+
+	roots, err := snapshots.Roots(cf.installFolder)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	backup, err := snapshots.Take(cf.backupFolder, roots, butlerd.SaveSnapshotReasonRestore)
+	if err != nil {
+		return nil, errors.Wrap(err, "backing up current saves")
+	}
+	if backup != nil {
+		consumer.Infof("Backed up current saves as version %d", backup.Version)
+	}
+
+	files, err := snapshots.Restore(cf.backupFolder, params.Version, roots)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	consumer.Statf("Restored %d save files from version %d", files, params.Version)
+
+	res := &butlerd.SavesRestoreResult{
+		Files:  files,
+		Backup: backup,
+	}
+	return res, nil
+}
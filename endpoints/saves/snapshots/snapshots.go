@@ -0,0 +1,522 @@
+// Package snapshots backs up and restores the save files of a game,
+// as listed in the `saves` section of its manifest.
+//
+// Each snapshot is a zip named `saves-v<version>.zip` in a per-cave
+// backup folder, containing a `snapshot.json` metadata file and the
+// files of each save path under `files/<index>/`.
+package snapshots
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/endpoints/launch/manifest"
+	"github.com/itchio/ox"
+	"github.com/itchio/wharf/state"
+	"github.com/pkg/errors"
+)
+
+// MaxAutomatic is how many automatic snapshots are kept per cave.
+// Manual snapshots are never pruned.
+const MaxAutomatic = 20
+
+const metadataName = "snapshot.json"
+
+var namePattern = regexp.MustCompile(`^saves-v([0-9]+)\.zip$`)
+
+// matches snapshots and snapshots still being written
+var reservedPattern = regexp.MustCompile(`^saves-v([0-9]+)\.zip(\.tmp)?$`)
+
+var reserveLock sync.Mutex
+
+type metadata struct {
+	butlerd.SaveSnapshot
+
+	// Where each save path was at snapshot time
+	Roots []*rootInfo `json:"roots"`
+
+	// Changes whenever a file is added, removed or modified
+	Fingerprint string `json:"fingerprint"`
+}
+
+type rootInfo struct {
+	Pattern string `json:"pattern"`
+	Base    string `json:"base"`
+}
+
+type entry struct {
+	root     int
+	rel      string
+	fullPath string
+	info     os.FileInfo
+}
+
+// Roots reads the manifest in installFolder and returns its save paths
+// for the current host. Returns nil if there's no manifest, or if it
+// doesn't list any save paths.
+func Roots(installFolder string) ([]*manifest.SaveRoot, error) {
+	m, err := manifest.Read(installFolder)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if m == nil {
+		return nil, nil
+	}
+
+	runtime := ox.CurrentRuntime()
+	vars := &manifest.Vars{
+		Runtime:       runtime,
+		InstallFolder: installFolder,
+		UserDirs:      manifest.CurrentUserDirs(runtime),
+	}
+	return manifest.ListSaves(m, vars), nil
+}
+
+// Take zips all the files of roots into a new snapshot in folder.
+// Returns nil if there are no files to back up.
+func Take(folder string, roots []*manifest.SaveRoot, reason butlerd.SaveSnapshotReason) (*butlerd.SaveSnapshot, error) {
+	meta, entries, err := collect(roots)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	version, err := reserveVersion(folder)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	meta.Version = version
+	meta.CreatedAt = time.Now().UTC()
+	meta.Reason = reason
+
+	snapshotPath := filepath.Join(folder, fmt.Sprintf("saves-v%d.zip", version))
+	tmpPath := snapshotPath + ".tmp"
+	err = writeZip(tmpPath, meta, entries)
+	if err != nil {
+		os.Remove(tmpPath)
+		return nil, errors.WithStack(err)
+	}
+
+	err = os.Rename(tmpPath, snapshotPath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	meta.Path = snapshotPath
+	return &meta.SaveSnapshot, nil
+}
+
+// Auto takes a snapshot of the saves of the game in installFolder,
+// unless they haven't changed since the last snapshot, then prunes
+// old automatic snapshots. Errors are logged: automatic snapshots
+// must never get in the way of whatever triggered them.
+func Auto(consumer *state.Consumer, installFolder string, folder string, reason butlerd.SaveSnapshotReason) {
+	roots, err := Roots(installFolder)
+	if err != nil {
+		consumer.Warnf("Could not list save paths: %+v", err)
+		return
+	}
+	if len(roots) == 0 {
+		return
+	}
+
+	unchanged, err := unchangedSinceLast(folder, roots)
+	if err != nil {
+		consumer.Warnf("Could not compare saves with last snapshot: %+v", err)
+	}
+	if unchanged {
+		consumer.Infof("Saves haven't changed since last snapshot")
+		return
+	}
+
+	snapshot, err := Take(folder, roots, reason)
+	if err != nil {
+		consumer.Warnf("Could not snapshot saves: %+v", err)
+		return
+	}
+	if snapshot == nil {
+		consumer.Infof("No saves to back up")
+		return
+	}
+	consumer.Infof("Backed up %d save files to (%s)", snapshot.Files, snapshot.Path)
+
+	err = Prune(folder, MaxAutomatic)
+	if err != nil {
+		consumer.Warnf("Could not prune save snapshots: %+v", err)
+	}
+}
+
+// List returns all snapshots in folder, most recent first.
+// Unreadable snapshots are skipped.
+func List(folder string) ([]*butlerd.SaveSnapshot, error) {
+	infos, err := ioutil.ReadDir(folder)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	var result []*butlerd.SaveSnapshot
+	for _, info := range infos {
+		if !namePattern.MatchString(info.Name()) {
+			continue
+		}
+
+		meta, err := readMetadata(filepath.Join(folder, info.Name()))
+		if err != nil {
+			continue
+		}
+		result = append(result, &meta.SaveSnapshot)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version > result[j].Version
+	})
+	return result, nil
+}
+
+// Prune removes the oldest automatic snapshots in folder so that at
+// most keep of them remain.
+func Prune(folder string, keep int) error {
+	snapshots, err := List(folder)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	kept := 0
+	for _, s := range snapshots {
+		if s.Reason == butlerd.SaveSnapshotReasonManual {
+			continue
+		}
+		kept++
+		if kept <= keep {
+			continue
+		}
+
+		err = os.Remove(s.Path)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// Restore extracts a snapshot over the current saves, and returns
+// the number of files written. Save paths are matched by pattern
+// with roots, so saves follow the game if it was reinstalled
+// elsewhere. Save paths no longer in the manifest are restored
+// where they were at snapshot time.
+func Restore(folder string, version int64, roots []*manifest.SaveRoot) (int64, error) {
+	snapshotPath := filepath.Join(folder, fmt.Sprintf("saves-v%d.zip", version))
+	zr, err := zip.OpenReader(snapshotPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, fmt.Errorf("No save snapshot with version %d", version)
+		}
+		return 0, errors.WithStack(err)
+	}
+	defer zr.Close()
+
+	meta, err := readMetadataFrom(&zr.Reader)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	bases := make(map[int]string)
+	for i, ri := range meta.Roots {
+		bases[i] = ri.Base
+		for _, root := range roots {
+			if root.Pattern == ri.Pattern {
+				bases[i] = root.Base
+				break
+			}
+		}
+	}
+
+	var files int64
+	for _, f := range zr.File {
+		if !strings.HasPrefix(f.Name, "files/") {
+			continue
+		}
+
+		tokens := strings.SplitN(strings.TrimPrefix(f.Name, "files/"), "/", 2)
+		if len(tokens) != 2 {
+			continue
+		}
+		index, err := strconv.Atoi(tokens[0])
+		if err != nil {
+			continue
+		}
+		base, ok := bases[index]
+		if !ok {
+			continue
+		}
+
+		rel := path.Clean(tokens[1])
+		if path.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, "../") {
+			return files, fmt.Errorf("Invalid path in save snapshot: %s", f.Name)
+		}
+
+		err = extractFile(f, filepath.Join(base, filepath.FromSlash(rel)))
+		if err != nil {
+			return files, errors.WithStack(err)
+		}
+		files++
+	}
+
+	return files, nil
+}
+
+// reserveVersion picks the version of the next snapshot in folder and
+// creates its temporary file, so that snapshots taken concurrently
+// never get the same version.
+func reserveVersion(folder string) (int64, error) {
+	reserveLock.Lock()
+	defer reserveLock.Unlock()
+
+	err := os.MkdirAll(folder, 0755)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	infos, err := ioutil.ReadDir(folder)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	var latest int64
+	for _, info := range infos {
+		matches := reservedPattern.FindStringSubmatch(info.Name())
+		if matches == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		if version > latest {
+			latest = version
+		}
+	}
+
+	version := latest + 1
+	tmpPath := filepath.Join(folder, fmt.Sprintf("saves-v%d.zip.tmp", version))
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	err = f.Close()
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return version, nil
+}
+
+func collect(roots []*manifest.SaveRoot) (*metadata, []*entry, error) {
+	meta := &metadata{}
+	var entries []*entry
+
+	h := sha256.New()
+	for i, root := range roots {
+		meta.Roots = append(meta.Roots, &rootInfo{
+			Pattern: root.Pattern,
+			Base:    root.Base,
+		})
+
+		rels, err := root.Files()
+		if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+
+		for _, rel := range rels {
+			fullPath := filepath.Join(root.Base, filepath.FromSlash(rel))
+			info, err := os.Stat(fullPath)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return nil, nil, errors.WithStack(err)
+			}
+
+			entries = append(entries, &entry{
+				root:     i,
+				rel:      rel,
+				fullPath: fullPath,
+				info:     info,
+			})
+			meta.Files++
+			meta.Size += info.Size()
+			fmt.Fprintf(h, "%d/%s:%d:%d\n", i, rel, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	meta.Fingerprint = fmt.Sprintf("%x", h.Sum(nil))
+
+	return meta, entries, nil
+}
+
+func unchangedSinceLast(folder string, roots []*manifest.SaveRoot) (bool, error) {
+	existing, err := List(folder)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	if len(existing) == 0 {
+		return false, nil
+	}
+
+	last, err := readMetadata(existing[0].Path)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	current, _, err := collect(roots)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return current.Fingerprint == last.Fingerprint, nil
+}
+
+func writeZip(zipPath string, meta *metadata, entries []*entry) error {
+	f, err := os.Create(zipPath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+
+	metaWriter, err := zw.Create(metadataName)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = json.NewEncoder(metaWriter).Encode(meta)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, e := range entries {
+		err = addFile(zw, e)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	err = zw.Close()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return f.Close()
+}
+
+func addFile(zw *zip.Writer, e *entry) error {
+	hdr, err := zip.FileInfoHeader(e.info)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	hdr.Name = fmt.Sprintf("files/%d/%s", e.root, e.rel)
+	hdr.Method = zip.Deflate
+
+	w, err := zw.CreateHeader(hdr)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	src, err := os.Open(e.fullPath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer src.Close()
+
+	_, err = io.Copy(w, src)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func extractFile(f *zip.File, dest string) error {
+	err := os.MkdirAll(filepath.Dir(dest), 0755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	src, err := f.Open()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(dest, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = io.Copy(dst, src)
+	if err != nil {
+		dst.Close()
+		return errors.WithStack(err)
+	}
+
+	err = dst.Close()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	modTime := f.ModTime()
+	return os.Chtimes(dest, modTime, modTime)
+}
+
+func readMetadata(snapshotPath string) (*metadata, error) {
+	zr, err := zip.OpenReader(snapshotPath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer zr.Close()
+
+	meta, err := readMetadataFrom(&zr.Reader)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	meta.Path = snapshotPath
+	return meta, nil
+}
+
+func readMetadataFrom(zr *zip.Reader) (*metadata, error) {
+	for _, f := range zr.File {
+		if f.Name != metadataName {
+			continue
+		}
+
+		r, err := f.Open()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		defer r.Close()
+
+		meta := &metadata{}
+		err = json.NewDecoder(r).Decode(meta)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return meta, nil
+	}
+
+	return nil, errors.New("save snapshot has no metadata")
+}
@@ -0,0 +1,146 @@
+package snapshots_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/endpoints/launch/manifest"
+	"github.com/itchio/butler/endpoints/saves/snapshots"
+	"github.com/itchio/ox"
+	"github.com/itchio/wharf/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Snapshots(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshots")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	installFolder := filepath.Join(dir, "garden")
+	backupFolder := filepath.Join(dir, "save-backups", "cave")
+
+	write := func(rel string, contents string) {
+		p := filepath.Join(installFolder, filepath.FromSlash(rel))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		assert.NoError(t, ioutil.WriteFile(p, []byte(contents), 0644))
+	}
+	read := func(rel string) string {
+		bs, err := ioutil.ReadFile(filepath.Join(installFolder, filepath.FromSlash(rel)))
+		assert.NoError(t, err)
+		return string(bs)
+	}
+
+	write(".itch.toml", "[[saves]]\npath = \"saves/**/*.sav\"\n")
+	write("garden.sh", "#!/bin/sh")
+	write("saves/slot1.sav", "level 1")
+	write("saves/amos/slot2.sav", "level 7")
+	write("saves/amos/notes.txt", "not a save")
+
+	roots, err := snapshots.Roots(installFolder)
+	assert.NoError(t, err)
+	assert.Len(t, roots, 1)
+
+	s1, err := snapshots.Take(backupFolder, roots, butlerd.SaveSnapshotReasonManual)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, s1.Version)
+	assert.EqualValues(t, 2, s1.Files)
+	assert.EqualValues(t, len("level 1")+len("level 7"), s1.Size)
+
+	// nothing changed, automatic snapshots are skipped
+	consumer := &state.Consumer{}
+	snapshots.Auto(consumer, installFolder, backupFolder, butlerd.SaveSnapshotReasonPlaySession)
+	list, err := snapshots.List(backupFolder)
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	write("saves/slot1.sav", "level 2")
+	snapshots.Auto(consumer, installFolder, backupFolder, butlerd.SaveSnapshotReasonPlaySession)
+	list, err = snapshots.List(backupFolder)
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.EqualValues(t, 2, list[0].Version)
+	assert.EqualValues(t, butlerd.SaveSnapshotReasonPlaySession, list[0].Reason)
+
+	// saves survive the game being reinstalled elsewhere
+	assert.NoError(t, os.RemoveAll(filepath.Join(installFolder, "saves")))
+	newInstallFolder := filepath.Join(dir, "garden-2")
+	assert.NoError(t, os.Rename(installFolder, newInstallFolder))
+	installFolder = newInstallFolder
+
+	roots, err = snapshots.Roots(installFolder)
+	assert.NoError(t, err)
+	files, err := snapshots.Restore(backupFolder, 1, roots)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, files)
+	assert.EqualValues(t, "level 1", read("saves/slot1.sav"))
+	assert.EqualValues(t, "level 7", read("saves/amos/slot2.sav"))
+
+	_, err = snapshots.Restore(backupFolder, 42, roots)
+	assert.Error(t, err)
+}
+
+func Test_Prune(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshots")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	savePath := filepath.Join(dir, "save.dat")
+	roots := []*manifest.SaveRoot{
+		manifest.ExpandSavePath(&butlerd.SavePath{Path: savePath}, &manifest.Vars{Runtime: ox.CurrentRuntime()}),
+	}
+	backupFolder := filepath.Join(dir, "backups")
+
+	assert.NoError(t, ioutil.WriteFile(savePath, []byte("manual"), 0644))
+	_, err = snapshots.Take(backupFolder, roots, butlerd.SaveSnapshotReasonManual)
+	assert.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		_, err = snapshots.Take(backupFolder, roots, butlerd.SaveSnapshotReasonPlaySession)
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, snapshots.Prune(backupFolder, 2))
+	list, err := snapshots.List(backupFolder)
+	assert.NoError(t, err)
+
+	var versions []int64
+	for _, s := range list {
+		versions = append(versions, s.Version)
+	}
+	assert.EqualValues(t, []int64{5, 4, 1}, versions)
+}
+
+func Test_TakeConcurrently(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshots")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	savePath := filepath.Join(dir, "save.dat")
+	roots := []*manifest.SaveRoot{
+		manifest.ExpandSavePath(&butlerd.SavePath{Path: savePath}, &manifest.Vars{Runtime: ox.CurrentRuntime()}),
+	}
+	backupFolder := filepath.Join(dir, "backups")
+	assert.NoError(t, ioutil.WriteFile(savePath, []byte("level 3"), 0644))
+
+	const count = 8
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := snapshots.Take(backupFolder, roots, butlerd.SaveSnapshotReasonManual)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	list, err := snapshots.List(backupFolder)
+	assert.NoError(t, err)
+	assert.Len(t, list, count)
+	for i, s := range list {
+		assert.EqualValues(t, count-i, s.Version)
+	}
+}
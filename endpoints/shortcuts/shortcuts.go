@@ -0,0 +1,163 @@
+package shortcuts
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/butlerd/messages"
+	"github.com/itchio/butler/cmd/operate"
+	"github.com/itchio/butler/database"
+	"github.com/itchio/butler/database/models"
+	"github.com/itchio/butler/endpoints/launch/manifest"
+	"github.com/itchio/butler/endpoints/shortcuts/xdg"
+	"github.com/itchio/ox"
+	"github.com/pkg/errors"
+)
+
+// iconExts are the image formats desktop environments reliably support
+var iconExts = map[string]bool{
+	".png":  true,
+	".svg":  true,
+	".xpm":  true,
+	".jpg":  true,
+	".jpeg": true,
+}
+
+func Register(router *butlerd.Router) {
+	messages.CavesCreateShortcut.Register(router, CreateShortcut)
+	messages.CavesRemoveShortcut.Register(router, RemoveShortcut)
+}
+
+func CreateShortcut(rc *butlerd.RequestContext, params butlerd.CavesCreateShortcutParams) (*butlerd.CavesCreateShortcutResult, error) {
+	consumer := rc.Consumer
+
+	if ox.CurrentRuntime().Platform != ox.PlatformLinux {
+		return nil, errors.New("Shortcuts are only supported on Linux")
+	}
+
+	cave := operate.ValidateCave(rc, params.CaveID)
+
+	butlerPath, err := os.Executable()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var installFolder, dbPath string
+	rc.WithConn(func(conn *sqlite.Conn) {
+		installFolder = cave.GetInstallFolder(conn)
+		dbPath, err = database.Path(conn)
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if dbPath == "" {
+		return nil, errors.New("Shortcuts can't be created for in-memory databases")
+	}
+
+	exec := []string{butlerPath, "--dbpath", dbPath, "shortcut", "launch", cave.ID}
+	if params.PrereqsDir != "" {
+		exec = append(exec, "--prereqs-dir", params.PrereqsDir)
+	}
+
+	iconPath, err := writeIcon(rc, cave, installFolder)
+	if err != nil {
+		consumer.Warnf("Could not set up shortcut icon, using a generic one: %+v", err)
+	}
+
+	entry := &xdg.Entry{
+		CaveID:   cave.ID,
+		Name:     cave.Game.Title,
+		Comment:  cave.Game.ShortText,
+		Exec:     exec,
+		IconPath: iconPath,
+	}
+	entryPath, err := entry.Write()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	consumer.Infof("Wrote shortcut for %s to (%s)", operate.GameToString(cave.Game), entryPath)
+
+	res := &butlerd.CavesCreateShortcutResult{
+		Path:     entryPath,
+		IconPath: iconPath,
+	}
+	return res, nil
+}
+
+func RemoveShortcut(rc *butlerd.RequestContext, params butlerd.CavesRemoveShortcutParams) (*butlerd.CavesRemoveShortcutResult, error) {
+	removed, err := xdg.Remove(params.CaveID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	res := &butlerd.CavesRemoveShortcutResult{
+		Removed: removed,
+	}
+	return res, nil
+}
+
+// writeIcon copies the icon listed in the manifest, or downloads the
+// game's cover, and returns its path. Returns an empty path if
+// neither is available.
+func writeIcon(rc *butlerd.RequestContext, cave *models.Cave, installFolder string) (string, error) {
+	appManifest, err := manifest.Read(installFolder)
+	if err != nil {
+		rc.Consumer.Warnf("Could not read manifest: %+v", err)
+	}
+
+	if appManifest != nil && appManifest.Icon != "" {
+		iconPath := appManifest.Icon
+		if !filepath.IsAbs(iconPath) {
+			iconPath = filepath.Join(installFolder, iconPath)
+		}
+
+		ext := strings.ToLower(filepath.Ext(iconPath))
+		if !iconExts[ext] {
+			return "", fmt.Errorf("Unsupported icon format (%s)", appManifest.Icon)
+		}
+
+		f, err := os.Open(iconPath)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		defer f.Close()
+
+		return xdg.WriteIcon(cave.ID, ext, f)
+	}
+
+	coverURL := cave.Game.StillCoverURL
+	if coverURL == "" {
+		coverURL = cave.Game.CoverURL
+	}
+	if coverURL == "" {
+		return "", nil
+	}
+
+	u, err := url.Parse(coverURL)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	ext := strings.ToLower(path.Ext(u.Path))
+	if !iconExts[ext] {
+		return "", fmt.Errorf("Unsupported cover format (%s)", coverURL)
+	}
+
+	res, err := rc.HTTPClient.Get(coverURL)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d while downloading cover (%s)", res.StatusCode, coverURL)
+	}
+
+	return xdg.WriteIcon(cave.ID, ext, res.Body)
+}
@@ -0,0 +1,201 @@
+// Package xdg writes desktop entries, as specified by freedesktop.org,
+// so that installed games show up in the application menu of Linux
+// desktop environments.
+//
+// See https://specifications.freedesktop.org/desktop-entry-spec/latest/
+package xdg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// An Entry is an application menu item that launches a cave
+type Entry struct {
+	CaveID  string
+	Name    string
+	Comment string
+
+	// Command line, the first element being the absolute path of butler
+	Exec []string
+
+	// Absolute path to an image. If empty, a generic icon is used
+	IconPath string
+}
+
+const fallbackIcon = "applications-games"
+
+// DataHome returns `$XDG_DATA_HOME`, which defaults to `~/.local/share`
+func DataHome() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.Getenv("HOME"), ".local", "share")
+}
+
+func baseName(caveID string) string {
+	return "itch-cave-" + caveID
+}
+
+// EntryPath returns where the desktop entry for a cave is written
+func EntryPath(caveID string) string {
+	return filepath.Join(DataHome(), "applications", baseName(caveID)+".desktop")
+}
+
+// IconPath returns where the icon of a cave is written, given the
+// extension of the image, like `.png`
+func IconPath(caveID string, ext string) string {
+	return filepath.Join(DataHome(), "icons", baseName(caveID)+ext)
+}
+
+// String returns the contents of the `.desktop` file
+func (e *Entry) String() string {
+	var execArgs []string
+	for _, arg := range e.Exec {
+		execArgs = append(execArgs, quoteExecArg(arg))
+	}
+
+	icon := e.IconPath
+	if icon == "" {
+		icon = fallbackIcon
+	}
+
+	var b bytes.Buffer
+	line := func(key string, value string) {
+		fmt.Fprintf(&b, "%s=%s\n", key, escapeValue(value))
+	}
+
+	b.WriteString("[Desktop Entry]\n")
+	line("Type", "Application")
+	line("Version", "1.0")
+	line("Name", e.Name)
+	if e.Comment != "" {
+		line("Comment", e.Comment)
+	}
+	line("Exec", strings.Join(execArgs, " "))
+	line("Icon", icon)
+	line("Terminal", "false")
+	line("Categories", "Game;")
+	line("X-Itch-Cave-Id", e.CaveID)
+	return b.String()
+}
+
+// Write creates or replaces the desktop entry for a cave, and
+// returns its path.
+func (e *Entry) Write() (string, error) {
+	entryPath := EntryPath(e.CaveID)
+	err := os.MkdirAll(filepath.Dir(entryPath), 0755)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	// desktop environments watch that folder, don't let them
+	// see a half-written file
+	tmpPath := entryPath + ".tmp"
+	err = ioutil.WriteFile(tmpPath, []byte(e.String()), 0644)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	err = os.Rename(tmpPath, entryPath)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return entryPath, nil
+}
+
+// WriteIcon copies an image to the icon path of a cave, replacing
+// any previous icon, and returns the path it was written to.
+func WriteIcon(caveID string, ext string, r io.Reader) (string, error) {
+	err := removeIcons(caveID)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	iconPath := IconPath(caveID, strings.ToLower(ext))
+	err = os.MkdirAll(filepath.Dir(iconPath), 0755)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	f, err := os.Create(iconPath)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, r)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return iconPath, f.Close()
+}
+
+// Remove deletes the desktop entry and icon of a cave, if any.
+// Returns true if there was a desktop entry.
+func Remove(caveID string) (bool, error) {
+	err := removeIcons(caveID)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	err = os.Remove(EntryPath(caveID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+func removeIcons(caveID string) error {
+	matches, err := filepath.Glob(IconPath(caveID, ".*"))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, match := range matches {
+		err = os.Remove(match)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// escapeValue escapes a value of type string, as defined by the spec
+func escapeValue(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		"\n", `\n`,
+		"\t", `\t`,
+		"\r", `\r`,
+	).Replace(s)
+}
+
+const execReservedChars = " \t\n\"'\\><~|&;$*?#()`"
+
+// quoteExecArg quotes an argument of the Exec key. Field codes
+// like `%f` are not supported, so percent signs are escaped too.
+func quoteExecArg(arg string) string {
+	arg = strings.Replace(arg, "%", "%%", -1)
+	if arg != "" && !strings.ContainsAny(arg, execReservedChars) {
+		return arg
+	}
+
+	quoted := strings.NewReplacer(
+		`"`, `\"`,
+		"`", "\\`",
+		`$`, `\$`,
+		`\`, `\\`,
+	).Replace(arg)
+	return `"` + quoted + `"`
+}
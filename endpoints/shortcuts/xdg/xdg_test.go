@@ -0,0 +1,64 @@
+package xdg_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/itchio/butler/endpoints/shortcuts/xdg"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Entry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xdg")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	oldDataHome := os.Getenv("XDG_DATA_HOME")
+	os.Setenv("XDG_DATA_HOME", dir)
+	defer os.Setenv("XDG_DATA_HOME", oldDataHome)
+
+	iconPath, err := xdg.WriteIcon("1234", ".PNG", strings.NewReader("not really a png"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, filepath.Join(dir, "icons", "itch-cave-1234.png"), iconPath)
+
+	e := &xdg.Entry{
+		CaveID:   "1234",
+		Name:     "Garden\nof Eden",
+		Exec:     []string{"/opt/butler/butler", "--dbpath", "/home/amos/My Games/butler.db", "shortcut", "launch", "1234", `100% "$free"`},
+		IconPath: iconPath,
+	}
+	contents := e.String()
+	assert.Contains(t, contents, `Name=Garden\nof Eden`)
+	assert.Contains(t, contents, `Exec=/opt/butler/butler --dbpath "/home/amos/My Games/butler.db" shortcut launch 1234 "100%% \\"\\$free\\""`)
+	assert.Contains(t, contents, "Icon="+iconPath)
+	assert.NotContains(t, contents, "Comment=")
+
+	entryPath, err := e.Write()
+	assert.NoError(t, err)
+	assert.EqualValues(t, xdg.EntryPath("1234"), entryPath)
+
+	written, err := ioutil.ReadFile(entryPath)
+	assert.NoError(t, err)
+	assert.EqualValues(t, contents, string(written))
+
+	// desktop entries aren't programs
+	stats, err := os.Stat(entryPath)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, stats.Mode().Perm()&0111)
+
+	removed, err := xdg.Remove("1234")
+	assert.NoError(t, err)
+	assert.True(t, removed)
+	_, err = os.Stat(iconPath)
+	assert.True(t, os.IsNotExist(err))
+
+	removed, err = xdg.Remove("1234")
+	assert.NoError(t, err)
+	assert.False(t, removed)
+
+	e.IconPath = ""
+	assert.Contains(t, e.String(), "Icon=applications-games")
+}
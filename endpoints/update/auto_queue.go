@@ -0,0 +1,85 @@
+package update
+
+import (
+	"crawshaw.io/sqlite"
+	"github.com/go-xorm/builder"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/cmd/operate"
+	"github.com/itchio/butler/database/models"
+	"github.com/itchio/butler/endpoints/install"
+	"github.com/itchio/wharf/state"
+	"github.com/pkg/errors"
+)
+
+// swapped out in tests
+var installQueue = install.InstallQueue
+
+// maybeQueueUpdate queues a direct update as a download if the
+// cave's update policy allows it, and marks it as queued.
+func maybeQueueUpdate(params checkUpdateCaveParams, consumer *state.Consumer, cave *models.Cave, update *butlerd.GameUpdate) error {
+	rc := params.rc
+
+	policy := operate.CaveUpdatePolicy(cave)
+	switch policy {
+	case butlerd.UpdatePolicyAutoWhenIdle, butlerd.UpdatePolicyAutoImmediate:
+		// good
+	default:
+		return nil
+	}
+
+	if !update.Direct || len(update.Choices) == 0 {
+		consumer.Infof("Not queuing update (not a direct update)")
+		return nil
+	}
+	choice := update.Choices[0]
+
+	if cave.Pinned {
+		consumer.Infof("Not queuing update (cave is pinned)")
+		return nil
+	}
+
+	// direct updates don't look at the snooze date, but
+	// queuing one by ourselves would undo the snooze.
+	if cave.SnoozedAt != nil && (choice.Build == nil || !moreRecentThan(choice.Build.CreatedAt, cave.SnoozedAt)) {
+		consumer.Infof("Not queuing update (snoozed at %s)", cave.SnoozedAt)
+		return nil
+	}
+
+	if len(rc.RunningCaves.ByCaveID(cave.ID)) > 0 {
+		consumer.Infof("Not queuing update (cave is running)")
+		return nil
+	}
+	if policy == butlerd.UpdatePolicyAutoWhenIdle && len(rc.RunningCaves.List()) > 0 {
+		consumer.Infof("Not queuing update (a game is running)")
+		return nil
+	}
+
+	var pending bool
+	rc.WithConn(func(conn *sqlite.Conn) {
+		pending = models.MustSelectOne(conn, &models.Download{}, builder.And(
+			builder.Eq{"cave_id": cave.ID},
+			builder.IsNull{"finished_at"},
+			builder.Not{builder.Expr("discarded")},
+		))
+	})
+	if pending {
+		consumer.Infof("Not queuing update (cave already has a pending download)")
+		return nil
+	}
+
+	consumer.Statf("Queuing update, as per the cave's (%s) policy", policy)
+	_, err := installQueue(rc, butlerd.InstallQueueParams{
+		CaveID:        cave.ID,
+		Reason:        butlerd.DownloadReasonUpdate,
+		Game:          update.Game,
+		Upload:        choice.Upload,
+		Build:         choice.Build,
+		QueueDownload: true,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	update.Queued = true
+	return nil
+}
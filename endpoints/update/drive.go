@@ -0,0 +1,122 @@
+package update
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"crawshaw.io/sqlite"
+	"github.com/go-xorm/builder"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/cmd/operate"
+	"github.com/itchio/butler/database/models"
+	"github.com/itchio/hades"
+	"github.com/itchio/ox"
+)
+
+var updatesDriveCancelID = "Updates.Drive"
+
+// how often the schedule is re-read while driving
+var driveTick = 1 * time.Minute
+
+func UpdatesDrive(rc *butlerd.RequestContext, params butlerd.UpdatesDriveParams) (*butlerd.UpdatesDriveResult, error) {
+	consumer := rc.Consumer
+	consumer.Infof("Now driving update checks...")
+
+	ctx, cancelFunc := context.WithCancel(rc.Ctx)
+
+	rc.CancelFuncs.Add(updatesDriveCancelID, cancelFunc)
+	defer rc.CancelFuncs.Remove(updatesDriveCancelID)
+
+	var lastRound time.Time
+	var jitter time.Duration
+	wasQuiet := false
+
+	for {
+		schedule := loadSchedule(rc)
+		interval := time.Duration(schedule.IntervalSeconds) * time.Second
+
+		now := time.Now()
+		quiet := inQuietHours(schedule.QuietHours, now)
+		if quiet != wasQuiet {
+			if quiet {
+				consumer.Infof("Entering quiet hours, pausing update checks")
+			} else {
+				consumer.Infof("Leaving quiet hours, resuming update checks")
+			}
+			wasQuiet = quiet
+		}
+
+		if interval > 0 && !quiet && now.Sub(lastRound) >= interval+jitter {
+			performRound(ctx, rc, schedule)
+			lastRound = time.Now()
+			jitter = 0
+			if schedule.JitterSeconds > 0 {
+				jitter = time.Duration(rand.Int63n(schedule.JitterSeconds)) * time.Second
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			consumer.Infof("Update checks cancelled, bye!")
+			res := &butlerd.UpdatesDriveResult{}
+			return res, nil
+		case <-time.After(driveTick):
+			// let's keep going
+		}
+	}
+}
+
+func UpdatesDriveCancel(rc *butlerd.RequestContext, params butlerd.UpdatesDriveCancelParams) (*butlerd.UpdatesDriveCancelResult, error) {
+	didCancel := rc.CancelFuncs.Call(updatesDriveCancelID)
+	return &butlerd.UpdatesDriveCancelResult{
+		DidCancel: didCancel,
+	}, nil
+}
+
+// performRound checks all caves that haven't been checked
+// during the last interval.
+func performRound(ctx context.Context, rc *butlerd.RequestContext, schedule *butlerd.UpdateSchedule) {
+	consumer := rc.Consumer
+	startTime := time.Now()
+
+	cutoff := time.Now().UTC().Add(-time.Duration(schedule.IntervalSeconds) * time.Second)
+
+	var caves []*models.Cave
+	rc.WithConn(func(conn *sqlite.Conn) {
+		var allCaves []*models.Cave
+		models.MustSelect(conn, &allCaves, builder.Not{builder.Expr("pinned")}, hades.Search{}.OrderBy("last_touched_at DESC"))
+
+		var checks []*models.UpdateCheck
+		models.MustSelect(conn, &checks, builder.NewCond(), hades.Search{})
+		checkedAt := make(map[string]time.Time)
+		for _, uc := range checks {
+			if uc.CheckedAt != nil {
+				checkedAt[uc.CaveID] = *uc.CheckedAt
+			}
+		}
+
+		for _, cave := range allCaves {
+			if operate.CaveUpdatePolicy(cave) == butlerd.UpdatePolicyManual {
+				continue
+			}
+			if t, ok := checkedAt[cave.ID]; ok && t.After(cutoff) {
+				continue
+			}
+			caves = append(caves, cave)
+		}
+		models.PreloadCaves(conn, caves)
+	})
+
+	if len(caves) == 0 {
+		return
+	}
+
+	consumer.Infof("Automatically looking for updates to %d items...", len(caves))
+	updateParams := checkUpdateCaveParams{
+		rc:      rc,
+		runtime: ox.CurrentRuntime(),
+	}
+	res := checkCaves(ctx, updateParams, caves, numWorkers(schedule), false)
+	consumer.Statf("Found %d updates (%d warnings) in %s", len(res.Updates), len(res.Warnings), time.Since(startTime))
+}
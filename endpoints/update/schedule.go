@@ -0,0 +1,63 @@
+package update
+
+import (
+	"time"
+
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/database/models"
+)
+
+const scheduleSetting = "update_schedule"
+
+// used until a client sets a schedule
+var defaultSchedule = butlerd.UpdateSchedule{
+	IntervalSeconds: 60 * 60,
+	JitterSeconds:   10 * 60,
+	Concurrency:     4,
+}
+
+func UpdatesSetSchedule(rc *butlerd.RequestContext, params butlerd.UpdatesSetScheduleParams) (*butlerd.UpdatesSetScheduleResult, error) {
+	rc.WithConn(func(conn *sqlite.Conn) {
+		models.SetSetting(conn, scheduleSetting, params.Schedule)
+	})
+
+	res := &butlerd.UpdatesSetScheduleResult{}
+	return res, nil
+}
+
+func UpdatesGetSchedule(rc *butlerd.RequestContext, params butlerd.UpdatesGetScheduleParams) (*butlerd.UpdatesGetScheduleResult, error) {
+	res := &butlerd.UpdatesGetScheduleResult{
+		Schedule: loadSchedule(rc),
+	}
+	return res, nil
+}
+
+func loadSchedule(rc *butlerd.RequestContext) *butlerd.UpdateSchedule {
+	schedule := defaultSchedule
+	rc.WithConn(func(conn *sqlite.Conn) {
+		models.GetSetting(conn, scheduleSetting, &schedule)
+	})
+	return &schedule
+}
+
+func numWorkers(schedule *butlerd.UpdateSchedule) int {
+	if schedule.Concurrency <= 0 {
+		return int(defaultSchedule.Concurrency)
+	}
+	return int(schedule.Concurrency)
+}
+
+// inQuietHours returns true if t falls within qh, which may be nil
+func inQuietHours(qh *butlerd.QuietHours, t time.Time) bool {
+	if qh == nil || qh.Start == qh.End {
+		return false
+	}
+
+	minutes := int64(t.Hour()*60 + t.Minute())
+	if qh.Start < qh.End {
+		return minutes >= qh.Start && minutes < qh.End
+	}
+	// spans midnight
+	return minutes >= qh.Start || minutes < qh.End
+}
@@ -0,0 +1,77 @@
+package update
+
+import (
+	"sort"
+
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/cmd/operate"
+	"github.com/itchio/butler/database/models"
+	itchio "github.com/itchio/go-itchio"
+	"github.com/itchio/httpkit/progress"
+	"github.com/itchio/wharf/state"
+)
+
+type upgradePathFunc func(currentBuildID int64, targetBuildID int64) (*itchio.UpgradePath, error)
+
+// estimateChoice sets how much a choice would take to download,
+// and how much it would change disk usage.
+func estimateChoice(consumer *state.Consumer, cave *models.Cave, choice *butlerd.GameUpdateChoice, getUpgradePath upgradePathFunc) {
+	archiveSize := choice.Upload.Size
+	installSize := int64(-1)
+	if choice.Build != nil {
+		if f := operate.FindBuildFile(choice.Build.Files, itchio.BuildFileTypeArchive, itchio.BuildFileSubTypeDefault); f != nil {
+			archiveSize = f.Size
+		}
+		if f := operate.FindBuildFile(choice.Build.Files, itchio.BuildFileTypeUnpacked, itchio.BuildFileSubTypeDefault); f != nil {
+			// single-file builds are installed as-is
+			installSize = f.Size
+		}
+	}
+	if installSize < 0 {
+		installSize = operate.GuessInstallSize(archiveSize)
+	}
+
+	choice.TransferSize = archiveSize
+	choice.DiskDelta = installSize - cave.InstalledSize
+
+	sameChannel := choice.Upload.ID == cave.UploadID && cave.BuildID > 0
+	if !sameChannel || choice.Build == nil || choice.Build.ID <= cave.BuildID {
+		return
+	}
+
+	upgradePath, err := getUpgradePath(cave.BuildID, choice.Build.ID)
+	if err != nil {
+		consumer.Warnf("Could not find upgrade path, assuming full download: %v", err)
+		return
+	}
+
+	var patchesSize int64
+	// the first build is the one we have installed
+	for _, b := range upgradePath.Builds[1:] {
+		f := operate.FindPatchFile(b)
+		if f == nil {
+			consumer.Infof("Build %d is missing a patch, assuming full download", b.ID)
+			return
+		}
+		patchesSize += f.Size
+	}
+
+	consumer.Infof("→ Patches total %s, archive is %s", progress.FormatBytes(patchesSize), progress.FormatBytes(archiveSize))
+	if patchesSize < archiveSize {
+		choice.TransferSize = patchesSize
+		choice.Patched = true
+	}
+}
+
+// sortChoices orders choices from most confidence to least
+// confidence, then from cheapest to most expensive transfer.
+func sortChoices(choices []*butlerd.GameUpdateChoice) {
+	sort.SliceStable(choices, func(i, j int) bool {
+		ii := choices[i]
+		jj := choices[j]
+		if ii.Confidence != jj.Confidence {
+			return ii.Confidence > jj.Confidence
+		}
+		return ii.TransferSize < jj.TransferSize
+	})
+}
@@ -1,8 +1,8 @@
 package update
 
 import (
+	"context"
 	"fmt"
-	"sort"
 	"sync"
 	"time"
 
@@ -29,13 +29,16 @@ import (
 func Register(router *butlerd.Router) {
 	messages.CheckUpdate.Register(router, CheckUpdate)
 	messages.SnoozeCave.Register(router, SnoozeCave)
+	messages.UpdatesSetSchedule.Register(router, UpdatesSetSchedule)
+	messages.UpdatesGetSchedule.Register(router, UpdatesGetSchedule)
+	messages.UpdatesDrive.Register(router, UpdatesDrive)
+	messages.UpdatesDriveCancel.Register(router, UpdatesDriveCancel)
 }
 
 func CheckUpdate(rc *butlerd.RequestContext, params butlerd.CheckUpdateParams) (*butlerd.CheckUpdateResult, error) {
 	startTime := time.Now()
 
 	consumer := rc.Consumer
-	res := &butlerd.CheckUpdateResult{}
 
 	updateParams := checkUpdateCaveParams{
 		rc:      rc,
@@ -63,6 +66,22 @@ func CheckUpdate(rc *butlerd.RequestContext, params butlerd.CheckUpdateParams) (
 	consumer.Infof("Looking for updates to %d items...", len(caves))
 	consumer.Infof("...for runtime %s", updateParams.runtime)
 
+	rc.StartProgress()
+	res := checkCaves(rc.Ctx, updateParams, caves, numWorkers(loadSchedule(rc)), params.Verbose)
+	rc.EndProgress()
+
+	consumer.Statf("Checked %d entries in %s", len(caves), time.Since(startTime))
+
+	return res, nil
+}
+
+// checkCaves looks for updates to caves, using numWorkers workers, and
+// sends a notification for every update found.
+func checkCaves(ctx context.Context, updateParams checkUpdateCaveParams, caves []*models.Cave, numWorkers int, verbose bool) *butlerd.CheckUpdateResult {
+	rc := updateParams.rc
+	consumer := rc.Consumer
+	res := &butlerd.CheckUpdateResult{}
+
 	var doneCaves int
 
 	// protects 'res' and 'doneCaves'
@@ -73,7 +92,6 @@ func CheckUpdate(rc *butlerd.RequestContext, params butlerd.CheckUpdateParams) (
 
 	taskSpecs := make(chan taskSpec)
 	workerDone := make(chan struct{})
-	numWorkers := 4
 
 	processOne := func(spec taskSpec) {
 		defer func() {
@@ -93,6 +111,13 @@ func CheckUpdate(rc *butlerd.RequestContext, params butlerd.CheckUpdateParams) (
 
 		ml := memorylogger.New()
 		update, err := checkUpdateCave(updateParams, ml.Consumer(), spec.cave)
+		if err == nil && update != nil {
+			qErr := maybeQueueUpdate(updateParams, ml.Consumer(), spec.cave, update)
+			if qErr != nil {
+				// the update was still found, so let the client know about it
+				consumer.Warnf("Could not queue update for cave (%s): %+v", spec.cave.ID, qErr)
+			}
+		}
 		resultMutex.Lock()
 		defer resultMutex.Unlock()
 
@@ -106,7 +131,7 @@ func CheckUpdate(rc *butlerd.RequestContext, params butlerd.CheckUpdateParams) (
 			ml.Copy(consumer)
 			consumer.Warnf("Log ends here ==================")
 		} else {
-			if params.Verbose {
+			if verbose {
 				ml.Copy(consumer)
 			}
 			if update != nil {
@@ -140,7 +165,7 @@ func CheckUpdate(rc *butlerd.RequestContext, params butlerd.CheckUpdateParams) (
 			select {
 			case taskSpecs <- spec:
 				// good!
-			case <-rc.Ctx.Done():
+			case <-ctx.Done():
 				close(taskSpecs)
 				return
 			}
@@ -149,7 +174,6 @@ func CheckUpdate(rc *butlerd.RequestContext, params butlerd.CheckUpdateParams) (
 		close(taskSpecs)
 	}()
 
-	rc.StartProgress()
 	for i := 0; i < numWorkers; i++ {
 		go work()
 	}
@@ -157,11 +181,8 @@ func CheckUpdate(rc *butlerd.RequestContext, params butlerd.CheckUpdateParams) (
 	for i := 0; i < numWorkers; i++ {
 		<-workerDone
 	}
-	rc.EndProgress()
-
-	consumer.Statf("Checked %d entries in %s", len(caves), time.Since(startTime))
 
-	return res, nil
+	return res
 }
 
 type checkUpdateCaveParams struct {
@@ -179,6 +200,11 @@ func checkUpdateCave(params checkUpdateCaveParams, consumer *state.Consumer, cav
 		return nil, nil
 	}
 
+	if operate.IsLocalGame(cave.GameID) {
+		consumer.Statf("Cave was installed from a local file, skipping")
+		return nil, nil
+	}
+
 	var access *operate.GameAccess
 	rc.WithConn(func(conn *sqlite.Conn) {
 		access = operate.AccessForGameID(conn, cave.GameID)
@@ -288,13 +314,33 @@ func checkUpdateCave(params checkUpdateCaveParams, consumer *state.Consumer, cav
 	consumer.Infof("→ Cached upload:")
 	operate.LogUpload(consumer, cave.Upload, cave.Build)
 
-	listUploadsRes, err := client.ListGameUploads(itchio.ListGameUploadsParams{
-		GameID: cave.Game.ID,
+	var previousCheck *models.UpdateCheck
+	rc.WithConn(func(conn *sqlite.Conn) {
+		previousCheck = models.UpdateCheckByCaveID(conn, cave.ID)
 	})
+
+	listUploadsRes, check, err := listGameUploads(consumer, client, itchio.ListGameUploadsParams{
+		GameID: cave.Game.ID,
+	}, previousCheck)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
+	check.CaveID = cave.ID
+	rc.WithConn(check.Save)
+
+	getUpgradePath := func(currentBuildID int64, targetBuildID int64) (*itchio.UpgradePath, error) {
+		res, err := client.GetBuildUpgradePath(itchio.GetBuildUpgradePathParams{
+			CurrentBuildID: currentBuildID,
+			TargetBuildID:  targetBuildID,
+			Credentials:    access.Credentials,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return res.UpgradePath, nil
+	}
+
 	var currentUpload = cave.Upload
 	var freshUpload *itchio.Upload
 	var newerUploads []*itchio.Upload
@@ -355,11 +401,13 @@ func checkUpdateCave(params checkUpdateCaveParams, consumer *state.Consumer, cav
 					Direct: true,
 				}
 
-				res.Choices = append(res.Choices, &butlerd.GameUpdateChoice{
+				choice := &butlerd.GameUpdateChoice{
 					Upload:     freshUpload,
 					Build:      freshUpload.Build,
 					Confidence: 1,
-				})
+				}
+				estimateChoice(consumer, cave, choice, getUpgradePath)
+				res.Choices = append(res.Choices, choice)
 				return res, nil
 			}
 			consumer.Infof("No direct update found, let's get fuzzy")
@@ -373,7 +421,10 @@ func checkUpdateCave(params checkUpdateCaveParams, consumer *state.Consumer, cav
 	}
 
 	countBeforeNarrow := len(newerUploads)
-	narrowDownResult := manager.NarrowDownUploads(consumer, cave.Game, newerUploads, runtime)
+	narrowDownResult := manager.NarrowDownUploadsWithOptions(consumer, cave.Game, newerUploads, runtime, manager.NarrowDownUploadsOptions{
+		// games played through a compat runner should keep updating
+		AllowCompatRunners: cave.CompatRunnerID != "",
+	})
 	newerUploads = narrowDownResult.Uploads
 	consumer.Infof("→ %d uploads to consider (%d eliminated by narrow-down)", len(newerUploads), len(newerUploads)-countBeforeNarrow)
 
@@ -409,16 +460,12 @@ func checkUpdateCave(params checkUpdateCaveParams, consumer *state.Consumer, cav
 			Build:      u.Build,
 			Confidence: confidence,
 		}
+		estimateChoice(consumer, cave, choice, getUpgradePath)
 		res.Choices = append(res.Choices, choice)
 	}
 
-	consumer.Infof("Sorting by confidence...")
-	sort.Slice(res.Choices, func(i, j int) bool {
-		ii := res.Choices[i]
-		jj := res.Choices[j]
-		// sort from most confidence to least confidence
-		return ii.Confidence > jj.Confidence
-	})
+	consumer.Infof("Sorting by confidence, then transfer size...")
+	sortChoices(res.Choices)
 
 	consumer.Infof("→ Final draft:")
 	for _, c := range res.Choices {
@@ -0,0 +1,236 @@
+package update
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"crawshaw.io/sqlite"
+	"github.com/itchio/butler/butlerd"
+	"github.com/itchio/butler/butlerd/butlerdtest"
+	"github.com/itchio/butler/cmd/operate/memorylogger"
+	"github.com/itchio/butler/database/models"
+	itchio "github.com/itchio/go-itchio"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ListGameUploadsConditional(t *testing.T) {
+	var requests int
+	var uploadID = 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		etag := fmt.Sprintf(`"uploads-%d"`, uploadID)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		fmt.Fprintf(w, `{"uploads":[{"id":%d,"filename":"game.zip"}]}`, uploadID)
+	}))
+	defer server.Close()
+
+	client := itchio.ClientWithKey("key")
+	client.SetServer(server.URL)
+	consumer := memorylogger.New().Consumer()
+	params := itchio.ListGameUploadsParams{GameID: 12}
+
+	res, check, err := listGameUploads(consumer, client, params, nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, res.Uploads[0].ID)
+	assert.EqualValues(t, `"uploads-1"`, check.ETag)
+	assert.EqualValues(t, 12, check.GameID)
+
+	res, check, err = listGameUploads(consumer, client, params, check)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, res.Uploads[0].ID)
+	assert.EqualValues(t, `"uploads-1"`, check.ETag)
+
+	uploadID = 2
+	res, check, err = listGameUploads(consumer, client, params, check)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, res.Uploads[0].ID)
+	assert.EqualValues(t, `"uploads-2"`, check.ETag)
+	assert.EqualValues(t, 3, requests)
+
+	// entity tags are only valid for the game they were listed for
+	_, other, err := listGameUploads(consumer, client, itchio.ListGameUploadsParams{GameID: 13}, check)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 13, other.GameID)
+}
+
+func Test_InQuietHours(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2018, 6, 1, hour, minute, 0, 0, time.Local)
+	}
+
+	assert.False(t, inQuietHours(nil, at(3, 0)))
+
+	day := &butlerd.QuietHours{Start: 9 * 60, End: 17 * 60}
+	assert.True(t, inQuietHours(day, at(9, 0)))
+	assert.True(t, inQuietHours(day, at(16, 59)))
+	assert.False(t, inQuietHours(day, at(17, 0)))
+	assert.False(t, inQuietHours(day, at(8, 59)))
+
+	night := &butlerd.QuietHours{Start: 23 * 60, End: 7 * 60}
+	assert.True(t, inQuietHours(night, at(23, 30)))
+	assert.True(t, inQuietHours(night, at(2, 0)))
+	assert.False(t, inQuietHours(night, at(7, 0)))
+	assert.False(t, inQuietHours(night, at(12, 0)))
+}
+
+func Test_MaybeQueueUpdate(t *testing.T) {
+	rc := &butlerd.RequestContext{
+		RunningCaves: butlerd.NewRunningCaves(),
+	}
+	params := checkUpdateCaveParams{rc: rc}
+	consumer := memorylogger.New().Consumer()
+
+	snoozedAt := time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)
+	builtAt := snoozedAt.Add(-time.Hour)
+	newUpdate := func(direct bool) *butlerd.GameUpdate {
+		return &butlerd.GameUpdate{
+			CaveID: "cave",
+			Direct: direct,
+			Choices: []*butlerd.GameUpdateChoice{
+				{Build: &itchio.Build{ID: 2, CreatedAt: &builtAt}},
+			},
+		}
+	}
+
+	// none of these get as far as queuing
+	for _, cave := range []*models.Cave{
+		{ID: "cave"},
+		{ID: "cave", UpdatePolicy: string(butlerd.UpdatePolicyManual)},
+		{ID: "cave", UpdatePolicy: string(butlerd.UpdatePolicyAutoImmediate), Pinned: true},
+		{ID: "cave", UpdatePolicy: string(butlerd.UpdatePolicyAutoImmediate), SnoozedAt: &snoozedAt},
+	} {
+		update := newUpdate(true)
+		assert.NoError(t, maybeQueueUpdate(params, consumer, cave, update))
+		assert.False(t, update.Queued)
+	}
+
+	cave := &models.Cave{ID: "cave", UpdatePolicy: string(butlerd.UpdatePolicyAutoImmediate)}
+	update := newUpdate(false)
+	assert.NoError(t, maybeQueueUpdate(params, consumer, cave, update))
+	assert.False(t, update.Queued)
+
+	rc.RunningCaves.Add(&butlerd.RunningCave{LaunchID: "a", CaveID: "cave"})
+	update = newUpdate(true)
+	assert.NoError(t, maybeQueueUpdate(params, consumer, cave, update))
+	assert.False(t, update.Queued)
+	rc.RunningCaves.Remove("a")
+
+	rc.RunningCaves.Add(&butlerd.RunningCave{LaunchID: "b", CaveID: "other"})
+	cave.UpdatePolicy = string(butlerd.UpdatePolicyAutoWhenIdle)
+	update = newUpdate(true)
+	assert.NoError(t, maybeQueueUpdate(params, consumer, cave, update))
+	assert.False(t, update.Queued)
+}
+
+func Test_MaybeQueueUpdateQueues(t *testing.T) {
+	h := butlerdtest.New(t)
+	defer h.Close()
+	params := checkUpdateCaveParams{rc: h.RC}
+	consumer := memorylogger.New().Consumer()
+
+	var queued []butlerd.InstallQueueParams
+	defer func(orig func(*butlerd.RequestContext, butlerd.InstallQueueParams) (*butlerd.InstallQueueResult, error)) {
+		installQueue = orig
+	}(installQueue)
+	installQueue = func(rc *butlerd.RequestContext, p butlerd.InstallQueueParams) (*butlerd.InstallQueueResult, error) {
+		queued = append(queued, p)
+		return &butlerd.InstallQueueResult{}, nil
+	}
+
+	game := &itchio.Game{ID: 1}
+	choice := &butlerd.GameUpdateChoice{
+		Upload: &itchio.Upload{ID: 2},
+		Build:  &itchio.Build{ID: 3},
+	}
+	update := &butlerd.GameUpdate{
+		CaveID:  "cave",
+		Game:    game,
+		Direct:  true,
+		Choices: []*butlerd.GameUpdateChoice{choice},
+	}
+
+	cave := &models.Cave{ID: "cave", UpdatePolicy: string(butlerd.UpdatePolicyAutoWhenIdle)}
+	assert.NoError(t, maybeQueueUpdate(params, consumer, cave, update))
+	assert.True(t, update.Queued)
+	if assert.Len(t, queued, 1) {
+		q := queued[0]
+		assert.EqualValues(t, "cave", q.CaveID)
+		assert.EqualValues(t, butlerd.DownloadReasonUpdate, q.Reason)
+		assert.EqualValues(t, game, q.Game)
+		assert.EqualValues(t, choice.Upload, q.Upload)
+		assert.EqualValues(t, choice.Build, q.Build)
+		assert.True(t, q.QueueDownload)
+	}
+
+	// only one pending download per cave
+	h.RC.WithConn(func(conn *sqlite.Conn) {
+		models.MustSave(conn, &models.Download{ID: "pending", CaveID: "cave"})
+	})
+	update.Queued = false
+	assert.NoError(t, maybeQueueUpdate(params, consumer, cave, update))
+	assert.False(t, update.Queued)
+	assert.Len(t, queued, 1)
+}
+
+func Test_EstimateChoice(t *testing.T) {
+	consumer := memorylogger.New().Consumer()
+	cave := &models.Cave{UploadID: 1, BuildID: 10, InstalledSize: 1000}
+
+	patch := func(id int64, size int64) *itchio.Build {
+		return &itchio.Build{
+			ID: id,
+			Files: []*itchio.BuildFile{
+				{Type: itchio.BuildFileTypePatch, SubType: itchio.BuildFileSubTypeDefault, Size: size},
+			},
+		}
+	}
+	getUpgradePath := func(currentBuildID int64, targetBuildID int64) (*itchio.UpgradePath, error) {
+		return &itchio.UpgradePath{
+			Builds: []*itchio.Build{{ID: 10}, patch(11, 50), patch(12, 70)},
+		}, nil
+	}
+	newBuild := func(id int64) *itchio.Build {
+		return &itchio.Build{
+			ID: id,
+			Files: []*itchio.BuildFile{
+				{Type: itchio.BuildFileTypeArchive, SubType: itchio.BuildFileSubTypeDefault, Size: 800},
+				{Type: itchio.BuildFileTypeUnpacked, SubType: itchio.BuildFileSubTypeDefault, Size: 1200},
+			},
+		}
+	}
+
+	patched := &butlerd.GameUpdateChoice{
+		Upload:     &itchio.Upload{ID: 1, Size: 800},
+		Build:      newBuild(12),
+		Confidence: 0.5,
+	}
+	estimateChoice(consumer, cave, patched, getUpgradePath)
+	assert.True(t, patched.Patched)
+	assert.EqualValues(t, 120, patched.TransferSize)
+	assert.EqualValues(t, 200, patched.DiskDelta)
+
+	other := &butlerd.GameUpdateChoice{
+		Upload:     &itchio.Upload{ID: 2, Size: 500},
+		Confidence: 0.5,
+	}
+	estimateChoice(consumer, cave, other, getUpgradePath)
+	assert.False(t, other.Patched)
+	assert.EqualValues(t, 500, other.TransferSize)
+	assert.EqualValues(t, 650-1000, other.DiskDelta)
+
+	best := &butlerd.GameUpdateChoice{
+		Upload:       &itchio.Upload{ID: 3},
+		Confidence:   0.9,
+		TransferSize: 10000,
+	}
+	choices := []*butlerd.GameUpdateChoice{other, best, patched}
+	sortChoices(choices)
+	assert.EqualValues(t, []*butlerd.GameUpdateChoice{best, patched, other}, choices)
+}
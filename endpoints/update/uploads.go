@@ -0,0 +1,69 @@
+package update
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/itchio/butler/database/models"
+	itchio "github.com/itchio/go-itchio"
+	"github.com/itchio/wharf/state"
+	"github.com/pkg/errors"
+)
+
+// listGameUploads is like client.ListGameUploads, except it makes a
+// conditional request if the previous check left an entity tag.
+// It returns the check to record, with CaveID left blank.
+func listGameUploads(consumer *state.Consumer, client *itchio.Client, params itchio.ListGameUploadsParams, previous *models.UpdateCheck) (*itchio.ListGameUploadsResponse, *models.UpdateCheck, error) {
+	q := itchio.NewQuery(client, "/games/%d/uploads", params.GameID)
+	q.AddGameCredentials(params.Credentials)
+
+	req, err := http.NewRequest("GET", q.URL(), nil)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	conditional := previous != nil && previous.GameID == params.GameID && previous.ETag != "" && previous.Body != ""
+	if conditional {
+		req.Header.Set("If-None-Match", previous.ETag)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	defer res.Body.Close()
+
+	now := time.Now().UTC()
+	check := &models.UpdateCheck{
+		CheckedAt: &now,
+		GameID:    params.GameID,
+	}
+
+	var body []byte
+	if conditional && res.StatusCode == http.StatusNotModified {
+		consumer.Infof("→ Uploads not modified since last check (%s)", previous.CheckedAt)
+		body = []byte(previous.Body)
+		check.ETag = previous.ETag
+		check.Body = previous.Body
+		res.StatusCode = http.StatusOK
+	} else {
+		body, err = ioutil.ReadAll(res.Body)
+		if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+		if res.StatusCode == http.StatusOK {
+			check.ETag = res.Header.Get("ETag")
+			check.Body = string(body)
+		}
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	r := &itchio.ListGameUploadsResponse{}
+	err = itchio.ParseAPIResponse(r, res)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	return r, check, nil
+}
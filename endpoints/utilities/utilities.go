@@ -1,10 +1,14 @@
 package utilities
 
 import (
-	"github.com/efarrer/iothrottler"
+	"crawshaw.io/sqlite"
 	"github.com/itchio/butler/butlerd"
 	"github.com/itchio/butler/butlerd/messages"
+	"github.com/itchio/butler/cmd/operate"
+	"github.com/itchio/butler/cmd/operate/peercache"
+	"github.com/itchio/butler/cmd/operate/throttle"
 	"github.com/itchio/httpkit/timeout"
+	"github.com/pkg/errors"
 )
 
 func Register(router *butlerd.Router) {
@@ -31,11 +35,75 @@ func Register(router *butlerd.Router) {
 
 	messages.NetworkSetBandwidthThrottle.Register(router, func(rc *butlerd.RequestContext, params butlerd.NetworkSetBandwidthThrottleParams) (*butlerd.NetworkSetBandwidthThrottleResult, error) {
 		if params.Enabled {
-			timeout.ThrottlerPool.SetBandwidth(iothrottler.Bandwidth(params.Rate) * iothrottler.Kbps)
+			throttle.Global.SetManual(params.Rate)
 		} else {
-			timeout.ThrottlerPool.SetBandwidth(iothrottler.Unlimited)
+			throttle.Global.SetManual(0)
 		}
 		res := &butlerd.NetworkSetBandwidthThrottleResult{}
 		return res, nil
 	})
+
+	messages.NetworkSetBandwidthPolicy.Register(router, func(rc *butlerd.RequestContext, params butlerd.NetworkSetBandwidthPolicyParams) (*butlerd.NetworkSetBandwidthPolicyResult, error) {
+		rc.WithConn(func(conn *sqlite.Conn) {
+			operate.SetBandwidthPolicy(conn, params.Policy)
+		})
+		throttle.Global.Apply(params.Policy)
+
+		res := &butlerd.NetworkSetBandwidthPolicyResult{}
+		return res, nil
+	})
+
+	messages.NetworkSetConnectivityProbe.Register(router, func(rc *butlerd.RequestContext, params butlerd.NetworkSetConnectivityProbeParams) (*butlerd.NetworkSetConnectivityProbeResult, error) {
+		rc.WithConn(func(conn *sqlite.Conn) {
+			operate.SetConnectivityProbe(conn, params.Probe)
+		})
+
+		res := &butlerd.NetworkSetConnectivityProbeResult{}
+		return res, nil
+	})
+
+	messages.NetworkSetPeerCache.Register(router, func(rc *butlerd.RequestContext, params butlerd.NetworkSetPeerCacheParams) (*butlerd.NetworkSetPeerCacheResult, error) {
+		err := operate.ConfigurePeerCache(params.Settings)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		rc.WithConn(func(conn *sqlite.Conn) {
+			operate.SetPeerCacheSettings(conn, params.Settings)
+		})
+
+		res := &butlerd.NetworkSetPeerCacheResult{}
+		return res, nil
+	})
+
+	messages.NetworkGetPeerCache.Register(router, func(rc *butlerd.RequestContext, params butlerd.NetworkGetPeerCacheParams) (*butlerd.NetworkGetPeerCacheResult, error) {
+		res := &butlerd.NetworkGetPeerCacheResult{
+			Settings: &butlerd.PeerCacheSettings{},
+			Peers:    []string{},
+		}
+		rc.WithConn(func(conn *sqlite.Conn) {
+			if settings := operate.PeerCacheSettings(conn); settings != nil {
+				res.Settings = settings
+			}
+		})
+		if node := peercache.Current(); node != nil {
+			res.Peers = node.Peers()
+		}
+		return res, nil
+	})
+
+	messages.NetworkGetConnectivityProbe.Register(router, func(rc *butlerd.RequestContext, params butlerd.NetworkGetConnectivityProbeParams) (*butlerd.NetworkGetConnectivityProbeResult, error) {
+		res := &butlerd.NetworkGetConnectivityProbeResult{}
+		rc.WithConn(func(conn *sqlite.Conn) {
+			res.Probe = operate.ConnectivityProbe(conn)
+		})
+		return res, nil
+	})
+
+	messages.NetworkGetBandwidthPolicy.Register(router, func(rc *butlerd.RequestContext, params butlerd.NetworkGetBandwidthPolicyParams) (*butlerd.NetworkGetBandwidthPolicyResult, error) {
+		res := &butlerd.NetworkGetBandwidthPolicyResult{}
+		rc.WithConn(func(conn *sqlite.Conn) {
+			res.Policy = operate.BandwidthPolicy(conn)
+		})
+		return res, nil
+	})
 }
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/itchio/boar"
+	"github.com/itchio/butler/cmd/operate/peercache"
 	"github.com/itchio/butler/installer/bfs"
 	"github.com/itchio/savior"
 	"github.com/itchio/wharf/eos"
@@ -38,6 +39,9 @@ type InstallParams struct {
 
 	InstallerInfo *InstallerInfo
 
+	// What File is shared as with LAN peers, if anything
+	PeerKey *peercache.Key
+
 	// For cancellation
 	Context context.Context
 }
@@ -18,7 +18,7 @@ func (m *Manager) Install(params *installer.InstallParams) (*installer.InstallRe
 	destName := filepath.Base(stats.Name())
 	destAbsolutePath := filepath.Join(params.InstallFolderPath, destName)
 
-	err = operate.DownloadInstallSource(params.Consumer, params.StageFolderPath, params.Context, params.File, destAbsolutePath)
+	err = operate.DownloadInstallSource(params.Consumer, params.StageFolderPath, params.Context, params.File, destAbsolutePath, params.PeerKey)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
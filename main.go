@@ -13,8 +13,8 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/efarrer/iothrottler"
 	"github.com/itchio/butler/cmd/elevate"
+	"github.com/itchio/butler/cmd/operate/throttle"
 	"github.com/itchio/butler/comm"
 	"github.com/itchio/butler/filtering"
 	"github.com/itchio/butler/mansion"
@@ -214,10 +214,10 @@ func doMain(args []string) {
 	}
 
 	if *appArgs.throttle > 0 {
-		throttle := *appArgs.throttle
-		bwKiloBytes := throttle / 8 * 1024
+		rate := *appArgs.throttle
+		bwKiloBytes := rate / 8 * 1024
 		comm.Logf("Throttling to %s/s bandwidth", progress.FormatBytes(bwKiloBytes))
-		timeout.ThrottlerPool.SetBandwidth(iothrottler.Bandwidth(throttle) * iothrottler.Kbps)
+		throttle.Global.SetManual(rate)
 	}
 
 	if *appArgs.simulateOffline {
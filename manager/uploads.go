@@ -14,6 +14,14 @@ type uploadFilter struct {
 	consumer *state.Consumer
 	runtime  *ox.Runtime
 	game     *itchio.Game
+	options  NarrowDownUploadsOptions
+}
+
+type NarrowDownUploadsOptions struct {
+	// If true, Windows uploads are kept on Linux, so they can be
+	// launched through a compatibility runner like Wine or Proton.
+	// They're sorted after native uploads.
+	AllowCompatRunners bool
 }
 
 type NarrowDownUploadsResult struct {
@@ -24,10 +32,15 @@ type NarrowDownUploadsResult struct {
 }
 
 func NarrowDownUploads(consumer *state.Consumer, game *itchio.Game, uploads []*itchio.Upload, runtime *ox.Runtime) *NarrowDownUploadsResult {
+	return NarrowDownUploadsWithOptions(consumer, game, uploads, runtime, NarrowDownUploadsOptions{})
+}
+
+func NarrowDownUploadsWithOptions(consumer *state.Consumer, game *itchio.Game, uploads []*itchio.Upload, runtime *ox.Runtime, options NarrowDownUploadsOptions) *NarrowDownUploadsResult {
 	uf := &uploadFilter{
 		consumer: consumer,
 		runtime:  runtime,
 		game:     game,
+		options:  options,
 	}
 
 	return uf.narrowDownUploads(uploads)
@@ -65,7 +78,7 @@ func (uf *uploadFilter) excludeWrongPlatform(uploads []*itchio.Upload) []*itchio
 	for _, u := range uploads {
 		switch u.Type {
 		case "default":
-			if !IsCompatible(u.Platforms, uf.runtime) {
+			if !IsCompatible(u.Platforms, uf.runtime) && !uf.isCompatRunnable(u) {
 				// executable and not compatible with us? that's a skip
 				continue
 			}
@@ -79,6 +92,14 @@ func (uf *uploadFilter) excludeWrongPlatform(uploads []*itchio.Upload) []*itchio
 	return res
 }
 
+// isCompatRunnable returns true if upload could be launched
+// through a compatibility runner, when allowed.
+func (uf *uploadFilter) isCompatRunnable(upload *itchio.Upload) bool {
+	return uf.options.AllowCompatRunners &&
+		uf.runtime.Platform == ox.PlatformLinux &&
+		upload.Platforms.Windows != ""
+}
+
 var knownBadFormatRegexp = regexp.MustCompile(`(?i)\.(rpm|deb|pkg)$`)
 
 func (uf *uploadFilter) excludeWrongFormat(uploads []*itchio.Upload) []*itchio.Upload {
@@ -130,6 +151,11 @@ func (uf *uploadFilter) scoreUpload(upload *itchio.Upload, index int) *scoredUpl
 
 	score += ExclusivityScore(upload.Platforms, uf.runtime)
 
+	// Native uploads always beat ones that need a compatibility runner
+	if upload.Type == "default" && !IsCompatible(upload.Platforms, uf.runtime) {
+		score -= 1000
+	}
+
 	return &scoredUpload{
 		score:  score,
 		upload: upload,
@@ -348,3 +348,40 @@ func Test_NarrowDownUploads(t *testing.T) {
 		}, ndu(bothWindowsUploads, windows32), "do exclude 64-bit on 32-bit windows, if we have both")
 	}
 }
+
+func Test_NarrowDownUploads_CompatRunners(t *testing.T) {
+	consumer := makeTestConsumer(t)
+
+	game := &itchio.Game{
+		Classification: itchio.GameClassificationGame,
+	}
+
+	linux64 := &ox.Runtime{
+		Platform: ox.PlatformLinux,
+		Is64:     true,
+	}
+
+	windowsUpload := &itchio.Upload{
+		Platforms: itchio.Platforms{Windows: "all"},
+		Filename:  "game-windows.zip",
+		Type:      "default",
+	}
+	linuxUpload := &itchio.Upload{
+		Platforms: itchio.Platforms{Linux: "all"},
+		Filename:  "game-linux.tar.gz",
+		Type:      "default",
+	}
+
+	onlyWindows := []*itchio.Upload{windowsUpload}
+	assert.Empty(t, manager.NarrowDownUploads(consumer, game, onlyWindows, linux64).Uploads, "exclude windows uploads by default")
+
+	options := manager.NarrowDownUploadsOptions{AllowCompatRunners: true}
+	assert.EqualValues(t, []*itchio.Upload{windowsUpload},
+		manager.NarrowDownUploadsWithOptions(consumer, game, onlyWindows, linux64, options).Uploads,
+		"keep windows uploads when compat runners are allowed")
+
+	both := []*itchio.Upload{windowsUpload, linuxUpload}
+	assert.EqualValues(t, []*itchio.Upload{linuxUpload, windowsUpload},
+		manager.NarrowDownUploadsWithOptions(consumer, game, both, linux64, options).Uploads,
+		"prefer native uploads, even in less preferred formats")
+}